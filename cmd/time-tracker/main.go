@@ -38,18 +38,31 @@ func main() {
 			app.Version, app.BuildDate, app.GitCommit)
 	}
 
-	fmt.Printf("Трекер времени v%s (сборка: %s, коммит: %s)\n",
-		app.Version, app.BuildDate, app.GitCommit)
-	fmt.Printf("Логи сохраняются в: %s\n", logFile)
-	fmt.Printf("Файл данных: %s\n", cfg.DataFile)
-
 	// Создаем и инициализируем приложение
-	application := app.NewApp(cfg, fileLogger)
+	application, err := app.NewApp(cfg, fileLogger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка инициализации: %v\n", err)
+		os.Exit(1)
+	}
 
 	if err := application.Initialize(); err != nil {
-		fmt.Printf("Ошибка инициализации: %v\n", err)
+		application.Close()
+		fmt.Fprintf(os.Stderr, "Ошибка инициализации: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Если указана команда, выполняем её без интерактивного меню
+	if len(cfg.Args) > 0 {
+		code := application.RunCommand(cfg.Args)
+		application.Close()
+		os.Exit(code)
+	}
+
+	fmt.Printf("Трекер времени v%s (сборка: %s, коммит: %s)\n",
+		app.Version, app.BuildDate, app.GitCommit)
+	fmt.Printf("Логи сохраняются в: %s\n", logFile)
+	fmt.Printf("Файл данных: %s\n", cfg.DataFile)
+
 	application.Run()
+	application.Close()
 }
@@ -0,0 +1,296 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/service"
+)
+
+// handleProjects - GET /api/projects[?all=true]: список проектов,
+// POST /api/projects: создание проекта
+func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		s.createProject(w, r)
+		return
+	}
+
+	all := r.URL.Query().Get("all") == "true"
+
+	var projects []Project
+	err := s.Data.WithProjects(func(data map[string]*domain.Project) error {
+		summary := s.Tracking.Summary(data)
+
+		projects = make([]Project, 0, len(data))
+		for _, name := range s.Projects.GetProjectNames(data, all) {
+			projects = append(projects, newProject(name, data[name], summary[name]))
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, projects)
+}
+
+// createProject - создание проекта
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
+	var req CreateProjectRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var project Project
+	err := s.Data.WithProjects(func(data map[string]*domain.Project) error {
+		if _, exists := data[req.Name]; exists {
+			return withStatus(http.StatusConflict, "проект с именем '%s' уже существует", req.Name)
+		}
+
+		if err := s.Projects.CreateProject(data, req.Name); err != nil {
+			return err
+		}
+
+		project = newProject(req.Name, data[req.Name], service.ProjectSummary{})
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, project)
+}
+
+// handleProject - адреса проекта:
+//
+//	GET    /api/projects/{проект}
+//	GET    /api/projects/{проект}/sprints
+//	GET    /api/projects/{проект}/entries
+//	POST   /api/projects/{проект}/entries
+//	PATCH  /api/projects/{проект}/entries/{ID}
+//	DELETE /api/projects/{проект}/entries/{ID}
+func (s *Server) handleProject(w http.ResponseWriter, r *http.Request) {
+	// Имя проекта может содержать «/», поэтому части пути разбираются до декодирования
+	var parts []string
+	for _, part := range strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/api/projects/"), "/") {
+		decoded, err := url.PathUnescape(part)
+		if err != nil {
+			writeError(w, withStatus(http.StatusBadRequest, "неверный адрес: %v", err))
+			return
+		}
+		parts = append(parts, decoded)
+	}
+
+	projectName := parts[0]
+	switch {
+	case len(parts) == 1:
+		if allowMethods(w, r, http.MethodGet) {
+			s.getProject(w, projectName)
+		}
+	case len(parts) == 2 && parts[1] == "sprints":
+		if allowMethods(w, r, http.MethodGet) {
+			s.getSprints(w, projectName)
+		}
+	case len(parts) == 2 && parts[1] == "entries":
+		if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+			return
+		}
+		if r.Method == http.MethodPost {
+			s.addEntry(w, r, projectName)
+		} else {
+			s.getEntries(w, projectName)
+		}
+	case len(parts) == 3 && parts[1] == "entries":
+		if !allowMethods(w, r, http.MethodPatch, http.MethodDelete) {
+			return
+		}
+		if r.Method == http.MethodPatch {
+			s.editEntry(w, r, projectName, parts[2])
+		} else {
+			s.deleteEntry(w, projectName, parts[2])
+		}
+	default:
+		writeError(w, withStatus(http.StatusNotFound, "адрес %s не найден", r.URL.Path))
+	}
+}
+
+// getProject - проект по имени
+func (s *Server) getProject(w http.ResponseWriter, projectName string) {
+	var project Project
+	err := s.withProject(projectName, func(data map[string]*domain.Project) error {
+		project = newProject(projectName, data[projectName], s.Tracking.Summary(data)[projectName])
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, project)
+}
+
+// getSprints - спринты проекта, сначала активный
+func (s *Server) getSprints(w http.ResponseWriter, projectName string) {
+	var sprints []Sprint
+	err := s.withProject(projectName, func(data map[string]*domain.Project) error {
+		list, err := s.Projects.GetProjectSprints(data, projectName)
+		if err != nil {
+			return err
+		}
+
+		totals := s.Tracking.Summary(data)[projectName].Sprints
+		sprints = make([]Sprint, 0, len(list))
+		for _, sprint := range list {
+			sprints = append(sprints, Sprint{
+				ID:           sprint.ID,
+				Name:         sprint.Name,
+				Description:  sprint.Description,
+				StartDate:    sprint.StartDate,
+				EndDate:      sprint.EndDate,
+				Active:       sprint.IsActive,
+				TotalSeconds: totals[sprint.ID],
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sprints)
+}
+
+// getEntries - записи проекта по времени начала
+func (s *Server) getEntries(w http.ResponseWriter, projectName string) {
+	var entries []Entry
+	err := s.withProject(projectName, func(data map[string]*domain.Project) error {
+		list, err := s.Entries.GetEntries(data, projectName)
+		if err != nil {
+			return err
+		}
+
+		entries = make([]Entry, 0, len(list))
+		for _, entry := range list {
+			entries = append(entries, newEntry(entry))
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// addEntry - ручное добавление записи
+func (s *Server) addEntry(w http.ResponseWriter, r *http.Request, projectName string) {
+	var req AddEntryRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var entry domain.TimeEntry
+	err := s.withProject(projectName, func(data map[string]*domain.Project) error {
+		var err error
+		entry, err = s.Entries.AddEntry(data, projectName, req.Start, req.End, req.Description, req.SprintID, req.NoSprint)
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newEntry(entry))
+}
+
+// editEntry - изменение записи по ID или его уникальному началу
+func (s *Server) editEntry(w http.ResponseWriter, r *http.Request, projectName, id string) {
+	var req EditEntryRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	edit := service.EntryEdit{
+		Description: req.Description,
+		Start:       req.Start,
+		SprintID:    req.SprintID,
+	}
+	if req.DurationSeconds != nil {
+		duration := time.Duration(*req.DurationSeconds) * time.Second
+		edit.Duration = &duration
+	}
+
+	var entry domain.TimeEntry
+	err := s.withEntry(projectName, id, func(data map[string]*domain.Project) error {
+		var err error
+		entry, err = s.Entries.EditEntry(data, projectName, id, edit)
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newEntry(entry))
+}
+
+// deleteEntry - удаление записи
+func (s *Server) deleteEntry(w http.ResponseWriter, projectName, id string) {
+	err := s.withEntry(projectName, id, func(data map[string]*domain.Project) error {
+		return s.Entries.DeleteEntry(data, projectName, id)
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// withProject - выполнение fn под блокировкой данных, если проект существует
+func (s *Server) withProject(projectName string, fn func(data map[string]*domain.Project) error) error {
+	return s.Data.WithProjects(func(data map[string]*domain.Project) error {
+		if _, exists := data[projectName]; !exists {
+			return withStatus(http.StatusNotFound, "проект '%s' не существует", projectName)
+		}
+
+		return fn(data)
+	})
+}
+
+// withEntry - выполнение fn под блокировкой данных, если запись проекта найдена
+func (s *Server) withEntry(projectName, id string, fn func(data map[string]*domain.Project) error) error {
+	return s.withProject(projectName, func(data map[string]*domain.Project) error {
+		if _, err := s.Entries.FindEntry(data, projectName, id); err != nil {
+			return &statusError{status: http.StatusNotFound, err: err}
+		}
+
+		return fn(data)
+	})
+}
+
+// newProject - проект в ответе API
+func newProject(name string, project *domain.Project, summary service.ProjectSummary) Project {
+	return Project{
+		Name:         name,
+		Archived:     project.Archived,
+		Running:      project.StartTime != nil,
+		Paused:       project.PausedAt != nil,
+		ActiveSprint: project.ActiveSprint,
+		TotalSeconds: summary.Total,
+	}
+}
@@ -0,0 +1,233 @@
+// Package api - локальный HTTP API трекера в формате JSON
+// для расширений браузера и панелей мониторинга
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/service"
+	"github.com/MWT-proger/time-tracking/internal/storage"
+	"github.com/MWT-proger/time-tracking/pkg/logger"
+)
+
+// maxBodySize - предельный размер тела запроса
+const maxBodySize = 1 << 20
+
+// shutdownTimeout - время на завершение текущих запросов при остановке сервера
+const shutdownTimeout = 5 * time.Second
+
+// Data - доступ к проектам приложения под его блокировкой
+type Data interface {
+	WithProjects(fn func(projects map[string]*domain.Project) error) error
+}
+
+// Server - HTTP API: отслеживание и отчеты выполняет Backend,
+// проекты и записи читаются и изменяются сервисами под блокировкой Data
+type Server struct {
+	Backend  daemon.Backend
+	Data     Data
+	Projects *service.ProjectService
+	Tracking *service.TrackingService
+	Entries  *service.EntryService
+	Logger   logger.Logger
+
+	// Токен, который клиенты передают в заголовке Authorization: Bearer
+	Token string
+
+	// Обработчик остальных адресов без проверки токена, например
+	// файлы веб-интерфейса; nil - только API
+	Static http.Handler
+
+	http *http.Server
+}
+
+// NewServer - создание HTTP API
+func NewServer(
+	backend daemon.Backend,
+	data Data,
+	projectService *service.ProjectService,
+	trackingService *service.TrackingService,
+	entryService *service.EntryService,
+	token string,
+	log logger.Logger,
+) *Server {
+	return &Server{
+		Backend:  backend,
+		Data:     data,
+		Projects: projectService,
+		Tracking: trackingService,
+		Entries:  entryService,
+		Token:    token,
+		Logger:   log,
+	}
+}
+
+// Handler - обработчик всех адресов API с проверкой токена и файлов Static
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/status", s.handleStatus)
+	mux.HandleFunc("/api/start", s.handleStart)
+	mux.HandleFunc("/api/stop", s.handleStop)
+	mux.HandleFunc("/api/pause", s.handlePause)
+	mux.HandleFunc("/api/resume", s.handleResume)
+	mux.HandleFunc("/api/report", s.handleReport)
+	mux.HandleFunc("/api/projects", s.handleProjects)
+	mux.HandleFunc("/api/projects/", s.handleProject)
+
+	handler := s.cors(s.authorize(mux))
+	if s.Static == nil {
+		return handler
+	}
+
+	root := http.NewServeMux()
+	root.Handle("/api/", handler)
+	root.Handle("/", s.Static)
+	return root
+}
+
+// Start - открытие адреса addr и обработка запросов в фоне
+func (s *Server) Start(addr string) error {
+	if s.Token == "" {
+		return fmt.Errorf("не задан токен HTTP API")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия адреса HTTP API %s: %w", addr, err)
+	}
+
+	s.http = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := s.http.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.Logger.Errorf("Ошибка работы HTTP API: %v", err)
+		}
+	}()
+
+	s.Logger.Infof("HTTP API: http://%s/api/", listener.Addr())
+	return nil
+}
+
+// Close - остановка сервера после завершения текущих запросов
+func (s *Server) Close() error {
+	if s.http == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return s.http.Shutdown(ctx)
+}
+
+// authorize - проверка токена в заголовке Authorization
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+			s.Logger.Warnf("Запрос к HTTP API без верного токена: %s %s", r.Method, r.URL.Path)
+			writeError(w, withStatus(http.StatusUnauthorized, "неверный токен"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// cors - разрешение запросов со страниц других адресов: доступ
+// защищен токеном, а не cookie, поэтому источник запроса не важен
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusError - ошибка с кодом ответа
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+func (e *statusError) Unwrap() error { return e.err }
+
+// withStatus - ошибка с кодом ответа и сообщением
+func withStatus(status int, format string, args ...any) error {
+	return &statusError{status: status, err: fmt.Errorf(format, args...)}
+}
+
+// errorStatus - код ответа для ошибки: конфликты с данными - 409, остальное - 400
+func errorStatus(err error) int {
+	var se *statusError
+	switch {
+	case errors.As(err, &se):
+		return se.status
+	case errors.Is(err, service.ErrEntryOverlap),
+		errors.Is(err, service.ErrTimerRunning),
+		errors.Is(err, storage.ErrReadOnly),
+		errors.Is(err, storage.ErrChangedOnDisk):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// writeJSON - ответ в формате JSON
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError - ответ {"error": "..."} с кодом по ошибке
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, errorStatus(err), map[string]string{"error": err.Error()})
+}
+
+// readJSON - разбор тела запроса, неизвестные поля считаются ошибкой
+func readJSON(r *http.Request, v any) error {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return withStatus(http.StatusBadRequest, "неверное тело запроса: %v", err)
+	}
+
+	return nil
+}
+
+// allowMethods - проверка метода запроса, иначе ответ 405
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, withStatus(http.StatusMethodNotAllowed, "метод %s не поддерживается", r.Method))
+	return false
+}
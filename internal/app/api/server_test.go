@@ -0,0 +1,324 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/app/api"
+	"github.com/MWT-proger/time-tracking/internal/app/handlers"
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/service"
+	"github.com/MWT-proger/time-tracking/internal/storage"
+	"github.com/MWT-proger/time-tracking/pkg/config"
+	"github.com/MWT-proger/time-tracking/pkg/logger"
+	"github.com/MWT-proger/time-tracking/pkg/pomodoro"
+)
+
+const testToken = "secret"
+
+// trayStub - трей, который ничего не показывает
+type trayStub struct{}
+
+func (trayStub) SetTracking(string, *time.Time)                 {}
+func (trayStub) SetPaused(string, *time.Time, time.Duration)    {}
+func (trayStub) SetPomodoro(string, pomodoro.Phase, *time.Time) {}
+func (trayStub) SetProjects([]string)                           {}
+func (trayStub) StopTrayTicker()                                {}
+
+// testAPI - HTTP API поверх обработчиков с данными в памяти
+type testAPI struct {
+	t        *testing.T
+	server   *httptest.Server
+	handlers *handlers.Handlers
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+
+	log := logger.NewLogger("error", io.Discard)
+	cfg := config.DefaultConfig()
+
+	projectService := service.NewProjectService(log, storage.NewMemoryStorage(service.CurrentSchemaVersion))
+	trackingService := service.NewTrackingService(projectService, log, cfg)
+	entryService := service.NewEntryService(projectService, log)
+	reportService := service.NewReportService(projectService, log)
+
+	h := handlers.NewHandlers(projectService, trackingService, entryService, reportService, trayStub{}, log, cfg)
+	h.SetProjects(map[string]*domain.Project{})
+
+	server := httptest.NewServer(api.NewServer(h, h, projectService, trackingService, entryService, testToken, log).Handler())
+	t.Cleanup(server.Close)
+
+	return &testAPI{t: t, server: server, handlers: h}
+}
+
+// do - запрос с токеном, ответ разбирается в out, если он не nil
+func (a *testAPI) do(method, path string, body, out any) int {
+	a.t.Helper()
+	return a.doWithToken(testToken, method, path, body, out)
+}
+
+func (a *testAPI) doWithToken(token, method, path string, body, out any) int {
+	a.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			a.t.Fatal(err)
+		}
+		reader = bytes.NewReader(content)
+	}
+
+	req, err := http.NewRequest(method, a.server.URL+path, reader)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := a.server.Client().Do(req)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			a.t.Fatalf("%s %s: ошибка разбора ответа: %v", method, path, err)
+		}
+	}
+
+	return resp.StatusCode
+}
+
+// createProject - создание проекта с проверкой ответа
+func (a *testAPI) createProject(name string) {
+	a.t.Helper()
+
+	if status := a.do(http.MethodPost, "/api/projects", api.CreateProjectRequest{Name: name}, nil); status != http.StatusCreated {
+		a.t.Fatalf("создание проекта %s: код %d", name, status)
+	}
+}
+
+// shiftStart - перенос начала сессии в прошлое, чтобы у нее была длительность
+func (a *testAPI) shiftStart(projectName string, d time.Duration) {
+	a.t.Helper()
+
+	err := a.handlers.WithProjects(func(projects map[string]*domain.Project) error {
+		start := projects[projectName].StartTime.Add(-d)
+		projects[projectName].StartTime = &start
+		return nil
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	a := newTestAPI(t)
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		want   int
+	}{
+		{"без токена", http.MethodGet, "", http.StatusUnauthorized},
+		{"неверный токен", http.MethodGet, "wrong", http.StatusUnauthorized},
+		{"верный токен", http.MethodGet, testToken, http.StatusOK},
+		{"предварительный запрос CORS", http.MethodOptions, "", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.doWithToken(tt.token, tt.method, "/api/status", nil, nil); got != tt.want {
+				t.Errorf("код %d, ожидался %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	a := newTestAPI(t)
+	a.createProject("alpha")
+
+	if status := a.do(http.MethodPost, "/api/projects", api.CreateProjectRequest{Name: "alpha"}, nil); status != http.StatusConflict {
+		t.Errorf("повторное создание проекта: код %d, ожидался 409", status)
+	}
+
+	var started api.Result
+	if status := a.do(http.MethodPost, "/api/start", api.StartRequest{Project: "alpha"}, &started); status != http.StatusOK {
+		t.Fatalf("старт: код %d", status)
+	}
+
+	var timers []api.Timer
+	a.do(http.MethodGet, "/api/status", nil, &timers)
+	if len(timers) != 1 || timers[0].Project != "alpha" {
+		t.Fatalf("запущенные отслеживания: %+v", timers)
+	}
+
+	if status := a.do(http.MethodPost, "/api/start", api.StartRequest{Project: "alpha"}, nil); status != http.StatusBadRequest {
+		t.Errorf("повторный старт: код %d, ожидался 400", status)
+	}
+
+	a.shiftStart("alpha", time.Hour)
+
+	var stopped api.Result
+	if status := a.do(http.MethodPost, "/api/stop", api.StopRequest{Description: "готово"}, &stopped); status != http.StatusOK {
+		t.Fatalf("остановка: код %d", status)
+	}
+	if stopped.Project != "alpha" || stopped.DurationSeconds == nil || *stopped.DurationSeconds != 3600 {
+		t.Errorf("результат остановки: %+v", stopped)
+	}
+
+	var entries []api.Entry
+	a.do(http.MethodGet, "/api/projects/alpha/entries", nil, &entries)
+	if len(entries) != 1 || entries[0].Description != "готово" || entries[0].DurationSeconds != 3600 {
+		t.Errorf("записи после остановки: %+v", entries)
+	}
+
+	if status := a.do(http.MethodPost, "/api/stop", api.StopRequest{}, nil); status != http.StatusConflict {
+		t.Errorf("остановка без отслеживаний: код %d, ожидался 409", status)
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	a := newTestAPI(t)
+	a.createProject("alpha")
+	a.do(http.MethodPost, "/api/start", api.StartRequest{Project: "alpha"}, nil)
+
+	if status := a.do(http.MethodPost, "/api/pause", api.ProjectRequest{}, nil); status != http.StatusOK {
+		t.Fatalf("пауза: код %d", status)
+	}
+
+	var timers []api.Timer
+	a.do(http.MethodGet, "/api/status", nil, &timers)
+	if len(timers) != 1 || timers[0].PausedAt == nil {
+		t.Fatalf("отслеживание не приостановлено: %+v", timers)
+	}
+
+	if status := a.do(http.MethodPost, "/api/pause", api.ProjectRequest{}, nil); status != http.StatusConflict {
+		t.Errorf("пауза без запущенных отслеживаний: код %d, ожидался 409", status)
+	}
+
+	var resumed api.Result
+	if status := a.do(http.MethodPost, "/api/resume", api.ProjectRequest{Project: "alpha"}, &resumed); status != http.StatusOK {
+		t.Fatalf("продолжение: код %d", status)
+	}
+	if resumed.PauseSeconds == nil {
+		t.Errorf("нет длительности перерыва: %+v", resumed)
+	}
+}
+
+func TestEntries(t *testing.T) {
+	a := newTestAPI(t)
+	a.createProject("alpha")
+
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+
+	var entry api.Entry
+	status := a.do(http.MethodPost, "/api/projects/alpha/entries",
+		api.AddEntryRequest{Start: start, End: start.Add(time.Hour), Description: "анализ"}, &entry)
+	if status != http.StatusCreated {
+		t.Fatalf("добавление записи: код %d", status)
+	}
+
+	status = a.do(http.MethodPost, "/api/projects/alpha/entries",
+		api.AddEntryRequest{Start: start.Add(30 * time.Minute), End: start.Add(2 * time.Hour)}, nil)
+	if status != http.StatusConflict {
+		t.Errorf("пересекающаяся запись: код %d, ожидался 409", status)
+	}
+
+	if status := a.do(http.MethodGet, "/api/projects/beta/entries", nil, nil); status != http.StatusNotFound {
+		t.Errorf("записи несуществующего проекта: код %d, ожидался 404", status)
+	}
+
+	description := "разработка"
+	duration := int64(90 * 60)
+
+	var edited api.Entry
+	status = a.do(http.MethodPatch, "/api/projects/alpha/entries/"+entry.ID[:8],
+		api.EditEntryRequest{Description: &description, DurationSeconds: &duration}, &edited)
+	if status != http.StatusOK {
+		t.Fatalf("изменение записи: код %d", status)
+	}
+	if edited.ID != entry.ID || edited.Description != description || edited.DurationSeconds != duration {
+		t.Errorf("измененная запись: %+v", edited)
+	}
+
+	if status := a.do(http.MethodDelete, "/api/projects/alpha/entries/"+entry.ID, nil, nil); status != http.StatusNoContent {
+		t.Errorf("удаление записи: код %d", status)
+	}
+	if status := a.do(http.MethodDelete, "/api/projects/alpha/entries/"+entry.ID, nil, nil); status != http.StatusNotFound {
+		t.Errorf("удаление удаленной записи: код %d, ожидался 404", status)
+	}
+
+	var entries []api.Entry
+	a.do(http.MethodGet, "/api/projects/alpha/entries", nil, &entries)
+	if len(entries) != 0 {
+		t.Errorf("записи после удаления: %+v", entries)
+	}
+}
+
+func TestReport(t *testing.T) {
+	a := newTestAPI(t)
+	a.createProject("alpha")
+	a.createProject("beta")
+
+	day := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	add := func(project string, start time.Time, d time.Duration) {
+		status := a.do(http.MethodPost, "/api/projects/"+project+"/entries",
+			api.AddEntryRequest{Start: start, End: start.Add(d)}, nil)
+		if status != http.StatusCreated {
+			t.Fatalf("добавление записи в %s: код %d", project, status)
+		}
+	}
+	add("alpha", day, time.Hour)
+	add("alpha", day.AddDate(0, 0, 1), 30*time.Minute)
+	add("beta", day, 2*time.Hour)
+
+	// Запись вне периода отчета
+	add("beta", day.AddDate(0, 0, 10), time.Hour)
+
+	query := url.Values{"from": {"2024-03-04"}, "to": {"2024-03-05"}, "group_by": {"project"}}
+
+	var report api.Report
+	if status := a.do(http.MethodGet, "/api/report?"+query.Encode(), nil, &report); status != http.StatusOK {
+		t.Fatalf("отчет: код %d", status)
+	}
+	if report.TotalSeconds != int64((3*time.Hour + 30*time.Minute).Seconds()) {
+		t.Errorf("итог отчета: %d", report.TotalSeconds)
+	}
+
+	totals := make(map[string]int64)
+	for _, row := range report.Rows {
+		totals[row.Project] += row.Seconds
+	}
+	if totals["alpha"] != 5400 || totals["beta"] != 7200 {
+		t.Errorf("строки отчета: %+v", report.Rows)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"неверная группировка", "group_by=year"},
+		{"неверная дата", "from=04.03.2024"},
+		{"неизвестный проект", "project=gamma"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := a.do(http.MethodGet, "/api/report?"+tt.query, nil, nil); status != http.StatusBadRequest {
+				t.Errorf("код %d, ожидался 400", status)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadToken - токен API из файла path. Если файла нет, создается
+// случайный токен, доступный для чтения только владельцу.
+func LoadToken(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err == nil {
+		token := strings.TrimSpace(string(content))
+		if token == "" {
+			return "", fmt.Errorf("файл токена %s пуст", path)
+		}
+		return token, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("ошибка чтения токена: %w", err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("ошибка создания токена: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("ошибка создания директории токена: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("ошибка сохранения токена: %w", err)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
+	"github.com/MWT-proger/time-tracking/internal/service"
+)
+
+// handleStatus - GET /api/status: запущенные отслеживания
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	sessions, err := s.Backend.Status()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	timers := make([]Timer, 0, len(sessions))
+	for _, session := range sessions {
+		timers = append(timers, newTimer(session))
+	}
+
+	writeJSON(w, http.StatusOK, timers)
+}
+
+// handleStart - POST /api/start: начало отслеживания
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	var req StartRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	stopped, err := s.Backend.Start(req.Project, req.Sprint, req.Description)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Result{Project: req.Project, Stopped: stopped})
+}
+
+// handleStop - POST /api/stop: остановка отслеживания
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	var req StopRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	projectName, err := s.resolveTimer(req.Project, func(daemon.Session) bool { return true })
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	elapsed, err := s.Backend.Stop(projectName, req.Description)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Result{Project: projectName, DurationSeconds: seconds(elapsed)})
+}
+
+// handlePause - POST /api/pause: приостановка отслеживания
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	var req ProjectRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	projectName, err := s.resolveTimer(req.Project, func(session daemon.Session) bool { return session.PausedAt == nil })
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.Backend.Pause(projectName); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Result{Project: projectName})
+}
+
+// handleResume - POST /api/resume: продолжение отслеживания
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	var req ProjectRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	projectName, err := s.resolveTimer(req.Project, func(session daemon.Session) bool { return session.PausedAt != nil })
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	pause, err := s.Backend.Resume(projectName)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Result{Project: projectName, PauseSeconds: seconds(pause)})
+}
+
+// resolveTimer - проект действия: указанный или единственный подходящий из запущенных
+func (s *Server) resolveTimer(projectName string, keep func(daemon.Session) bool) (string, error) {
+	if projectName != "" {
+		return projectName, nil
+	}
+
+	sessions, err := s.Backend.Status()
+	if err != nil {
+		return "", err
+	}
+
+	var names []string
+	for _, session := range sessions {
+		if keep(session) {
+			names = append(names, session.Project)
+		}
+	}
+
+	switch len(names) {
+	case 0:
+		return "", withStatus(http.StatusConflict, "нет подходящих отслеживаний")
+	case 1:
+		return names[0], nil
+	default:
+		return "", withStatus(http.StatusBadRequest, "запущено несколько отслеживаний, укажите проект: %v", names)
+	}
+}
+
+// handleReport - GET /api/report?from=&to=&group_by=&project=: отчет за период,
+// по умолчанию текущая неделя по дням
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	query := r.URL.Query()
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	filter := service.ReportFilter{
+		From:    today.AddDate(0, 0, -(int(today.Weekday())+6)%7),
+		To:      today,
+		Project: query.Get("project"),
+		GroupBy: service.GroupByDay,
+	}
+
+	var err error
+	if value := query.Get("from"); value != "" {
+		if filter.From, err = time.ParseInLocation("2006-01-02", value, time.Local); err != nil {
+			writeError(w, withStatus(http.StatusBadRequest, "неверная дата начала '%s'", value))
+			return
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if filter.To, err = time.ParseInLocation("2006-01-02", value, time.Local); err != nil {
+			writeError(w, withStatus(http.StatusBadRequest, "неверная дата окончания '%s'", value))
+			return
+		}
+	}
+	if value := query.Get("group_by"); value != "" {
+		if filter.GroupBy, err = service.ParseGroupBy(value); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	report, err := s.Backend.Report(filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newReport(report))
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/service"
+)
+
+// Длительности в ответах API передаются в секундах
+
+// Project - проект
+type Project struct {
+	Name         string `json:"name"`
+	Archived     bool   `json:"archived"`
+	Running      bool   `json:"running"`
+	Paused       bool   `json:"paused"`
+	ActiveSprint string `json:"active_sprint,omitempty"`
+	TotalSeconds int    `json:"total_seconds"`
+}
+
+// Sprint - спринт проекта
+type Sprint struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	StartDate    string `json:"start_date,omitempty"`
+	EndDate      string `json:"end_date,omitempty"`
+	Active       bool   `json:"active"`
+	TotalSeconds int    `json:"total_seconds"`
+}
+
+// Entry - запись времени
+type Entry struct {
+	ID              string    `json:"id"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	Description     string    `json:"description"`
+	SprintID        string    `json:"sprint_id,omitempty"`
+	DurationSeconds int64     `json:"duration_seconds"`
+}
+
+// Timer - запущенное отслеживание
+type Timer struct {
+	Project        string     `json:"project"`
+	Sprint         string     `json:"sprint,omitempty"`
+	Start          time.Time  `json:"start"`
+	PausedAt       *time.Time `json:"paused_at,omitempty"`
+	ElapsedSeconds int64      `json:"elapsed_seconds"`
+	Long           bool       `json:"long,omitempty"`
+}
+
+// ReportRow - строка отчета
+type ReportRow struct {
+	Period  string `json:"period,omitempty"`
+	Project string `json:"project"`
+	Sprint  string `json:"sprint,omitempty"`
+	Seconds int64  `json:"seconds"`
+}
+
+// Report - отчет за период
+type Report struct {
+	From         string      `json:"from"`
+	To           string      `json:"to"`
+	Project      string      `json:"project,omitempty"`
+	GroupBy      string      `json:"group_by"`
+	Rows         []ReportRow `json:"rows"`
+	TotalSeconds int64       `json:"total_seconds"`
+}
+
+// Result - результат действия с отслеживанием
+type Result struct {
+	Project         string   `json:"project"`
+	Stopped         []string `json:"stopped,omitempty"`
+	DurationSeconds *int64   `json:"duration_seconds,omitempty"`
+	PauseSeconds    *int64   `json:"pause_seconds,omitempty"`
+}
+
+// CreateProjectRequest - тело POST /api/projects
+type CreateProjectRequest struct {
+	Name string `json:"name"`
+}
+
+// StartRequest - тело POST /api/start
+type StartRequest struct {
+	Project     string `json:"project"`
+	Sprint      string `json:"sprint,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// StopRequest - тело POST /api/stop, пустой проект - единственное запущенное отслеживание
+type StopRequest struct {
+	Project     string `json:"project"`
+	Description string `json:"description,omitempty"`
+}
+
+// ProjectRequest - тело POST /api/pause и /api/resume
+type ProjectRequest struct {
+	Project string `json:"project"`
+}
+
+// AddEntryRequest - тело POST /api/projects/{проект}/entries.
+// Пустой sprint_id - активный спринт проекта.
+type AddEntryRequest struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Description string    `json:"description,omitempty"`
+	SprintID    string    `json:"sprint_id,omitempty"`
+	NoSprint    bool      `json:"no_sprint,omitempty"`
+}
+
+// EditEntryRequest - тело PATCH /api/projects/{проект}/entries/{ID},
+// отсутствующие поля не изменяются, пустой sprint_id убирает спринт
+type EditEntryRequest struct {
+	Description     *string    `json:"description"`
+	Start           *time.Time `json:"start"`
+	DurationSeconds *int64     `json:"duration_seconds"`
+	SprintID        *string    `json:"sprint_id"`
+}
+
+// seconds - длительность в секундах для необязательного поля ответа
+func seconds(d time.Duration) *int64 {
+	value := int64(d.Seconds())
+	return &value
+}
+
+// newEntry - запись в ответе API
+func newEntry(entry domain.TimeEntry) Entry {
+	return Entry{
+		ID:              entry.ID,
+		Start:           entry.Start,
+		End:             entry.End,
+		Description:     entry.Description,
+		SprintID:        entry.SprintID,
+		DurationSeconds: int64(entry.Duration().Seconds()),
+	}
+}
+
+// newTimer - запущенное отслеживание в ответе API
+func newTimer(session daemon.Session) Timer {
+	return Timer{
+		Project:        session.Project,
+		Sprint:         session.Sprint,
+		Start:          session.Start,
+		PausedAt:       session.PausedAt,
+		ElapsedSeconds: int64(session.Elapsed.Seconds()),
+		Long:           session.Long,
+	}
+}
+
+// newReport - отчет в ответе API
+func newReport(report service.Report) Report {
+	result := Report{
+		From:         report.Filter.From.Format("2006-01-02"),
+		To:           report.Filter.To.Format("2006-01-02"),
+		Project:      report.Filter.Project,
+		GroupBy:      string(report.Filter.GroupBy),
+		Rows:         make([]ReportRow, 0, len(report.Rows)),
+		TotalSeconds: int64(report.Total.Seconds()),
+	}
+
+	for _, row := range report.Rows {
+		result.Rows = append(result.Rows, ReportRow{
+			Period:  row.Period,
+			Project: row.Project,
+			Sprint:  row.Sprint,
+			Seconds: int64(row.Duration.Seconds()),
+		})
+	}
+
+	return result
+}
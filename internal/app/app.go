@@ -1,16 +1,36 @@
 package app
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
 
+	"github.com/MWT-proger/time-tracking/internal/app/api"
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
 	"github.com/MWT-proger/time-tracking/internal/app/handlers"
 	"github.com/MWT-proger/time-tracking/internal/app/systray"
+	"github.com/MWT-proger/time-tracking/internal/app/tui"
+	"github.com/MWT-proger/time-tracking/internal/app/web"
 	"github.com/MWT-proger/time-tracking/internal/domain"
 	"github.com/MWT-proger/time-tracking/internal/service"
+	"github.com/MWT-proger/time-tracking/internal/storage"
+	"github.com/MWT-proger/time-tracking/pkg/clock"
 	"github.com/MWT-proger/time-tracking/pkg/config"
+	"github.com/MWT-proger/time-tracking/pkg/dialog"
+	"github.com/MWT-proger/time-tracking/pkg/idle"
 	"github.com/MWT-proger/time-tracking/pkg/logger"
+	"github.com/MWT-proger/time-tracking/pkg/notify"
+	"github.com/MWT-proger/time-tracking/pkg/pomodoro"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/chzyer/readline"
 )
 
+// trayPollInterval - интервал обновления трея по состоянию демона
+const trayPollInterval = 2 * time.Second
+
 type SystrayHandler interface {
 	Run()
 	Quit()
@@ -20,31 +40,125 @@ type SystrayHandler interface {
 type App struct {
 	ProjectService  *service.ProjectService
 	TrackingService *service.TrackingService
+	EntryService    *service.EntryService
+	ReportService   *service.ReportService
 	SystrayHandler  SystrayHandler
 	Projects        map[string]*domain.Project
 	Logger          logger.Logger
 	Config          *config.Config
 	Handlers        *handlers.Handlers
+	Lock            *storage.FileLock
+
+	// Клиент запущенного демона, nil - приложение само владеет данными
+	Remote *daemon.Client
+
+	// Закрывается при выходе из главного меню или из трея
+	quit     chan struct{}
+	quitOnce sync.Once
 }
 
 // NewApp - создание нового экземпляра приложения
-func NewApp(cfg *config.Config, log logger.Logger) *App {
-	projectService := service.NewProjectService(log, cfg.DataFile)
+func NewApp(cfg *config.Config, log logger.Logger) (*App, error) {
+	if cfg.TimerMode != config.TimerModeSingle && cfg.TimerMode != config.TimerModeMulti {
+		return nil, fmt.Errorf("неизвестный режим таймеров: %s (допустимо: single, multi)", cfg.TimerMode)
+	}
+
+	if cfg.UI != config.UIMenu && cfg.UI != config.UITUI {
+		return nil, fmt.Errorf("неизвестный интерфейс: %s (допустимо: menu, tui)", cfg.UI)
+	}
+
+	if _, _, err := service.ParseWorkHours(cfg.WorkHours); err != nil {
+		return nil, err
+	}
+
+	store, err := storage.New(cfg.Storage, storage.Options{
+		Path:           cfg.DataFile,
+		Backups:        cfg.Backups,
+		BackupInterval: cfg.BackupInterval,
+		SchemaVersion:  service.CurrentSchemaVersion,
+	}, log)
+	if err != nil {
+		log.Errorf("Ошибка открытия хранилища: %v", err)
+		return nil, err
+	}
+
+	// При запущенном демоне данные изменяются только через него
+	var remote *daemon.Client
+	if !isDaemonCommand(cfg) {
+		if remote, err = daemon.Dial(cfg.Socket); err == nil {
+			log.Infof("Подключение к демону: %s", cfg.Socket)
+		}
+	}
+
+	// Второй экземпляр приложения работает с данными только для чтения
+	var lock *storage.FileLock
+	switch {
+	case cfg.ReadOnly && isDaemonCommand(cfg):
+		store.Close()
+		return nil, fmt.Errorf("демон не может работать в режиме -readonly")
+	case cfg.ReadOnly:
+		store = storage.NewReadOnlyStorage(store, "включен режим -readonly")
+	case remote != nil:
+		store = storage.NewReadOnlyStorage(store, "данные принадлежат запущенному демону")
+	default:
+		lock, err = storage.Lock(cfg.DataFile)
+		// Демону нужны данные для записи, поэтому он не запускается рядом с другим экземпляром
+		if errors.Is(err, storage.ErrLocked) && !isDaemonCommand(cfg) {
+			log.Warnf("Данные заблокированы: %v", err)
+			fmt.Fprintf(os.Stderr, "Внимание: %v. Данные открыты только для чтения.\n", err)
+			store = storage.NewReadOnlyStorage(store, err.Error())
+		} else if err != nil {
+			store.Close()
+			log.Errorf("Ошибка блокировки данных: %v", err)
+			return nil, err
+		}
+	}
+
+	projectService := service.NewProjectService(log, store)
 	trackingService := service.NewTrackingService(projectService, log, cfg)
-	systrayHandler := systray.NewSystrayHandler(log)
+	entryService := service.NewEntryService(projectService, log)
+	reportService := service.NewReportService(projectService, log)
+	systrayHandler := systray.NewSystrayHandler(log, cfg.TimerMode == config.TimerModeMulti)
 
 	app := &App{
 		ProjectService:  projectService,
 		TrackingService: trackingService,
+		EntryService:    entryService,
+		ReportService:   reportService,
 		SystrayHandler:  systrayHandler,
 		Logger:          log,
 		Config:          cfg,
+		Lock:            lock,
+		quit:            make(chan struct{}),
 	}
 
 	// Инициализируем обработчики
-	app.Handlers = handlers.NewHandlers(app.ProjectService, app.TrackingService, systrayHandler, app.Logger, app.Config)
+	app.Handlers = handlers.NewHandlers(app.ProjectService, app.TrackingService, app.EntryService, app.ReportService, systrayHandler, app.Logger, app.Config)
+	if remote != nil {
+		app.Remote = remote
+		app.Handlers.Remote = remote
+	}
+
+	// Действия трея не используют терминал: проект выбирается в подменю,
+	// описание при остановке запрашивается диалогом
+	systrayHandler.StartProject = app.Handlers.StartProject
+	systrayHandler.StopTracking = app.Handlers.StopTracking
+	systrayHandler.PauseTracking = app.Handlers.PauseTracking
+	systrayHandler.ResumeTracking = app.Handlers.ResumeTracking
+	systrayHandler.StopProject = app.Handlers.StopProject
+	systrayHandler.OnExit = app.Quit
+	trackingService.OnStopAction = app.Handlers.StopProject
 
-	return app
+	if cfg.Pomodoro {
+		app.Handlers.Pomodoro = pomodoro.NewEngine(pomodoro.Settings{
+			Work:           cfg.PomodoroWork,
+			ShortBreak:     cfg.PomodoroShortBreak,
+			LongBreak:      cfg.PomodoroLongBreak,
+			LongBreakEvery: cfg.PomodoroLongEvery,
+		}, clock.Real{}, app.Handlers.OnPomodoroPhase)
+	}
+
+	return app, nil
 }
 
 // Initialize - инициализация приложения
@@ -52,6 +166,18 @@ func (a *App) Initialize() error {
 	a.Logger.Info("Инициализация приложения")
 	var err error
 	a.Projects, err = a.ProjectService.LoadData()
+	if errors.Is(err, storage.ErrCorrupt) {
+		a.Logger.Warnf("Данные повреждены: %v", err)
+		switch {
+		case len(a.Config.Args) == 0:
+			a.Projects, err = a.Handlers.RecoverData(err)
+		case a.Config.Args[0] == "recover":
+			// Восстановление выполнит сама команда
+			a.Projects, err = make(map[string]*domain.Project), nil
+		default:
+			err = fmt.Errorf("%w (для восстановления выполните команду recover)", err)
+		}
+	}
 	if err != nil {
 		a.Logger.Errorf("Ошибка загрузки данных: %v", err)
 		return err
@@ -79,6 +205,234 @@ func (a *App) Run() {
 
 		a.SystrayHandler.Run()
 	}()
-	a.Handlers.GeneralMenu()
+
+	// Уведомления и напоминания при запущенном демоне обрабатывает он,
+	// меню и трей только передают ему команды
+	menu := a.Handlers.GeneralMenu
+	var apiServer *api.Server
+	if a.Remote != nil {
+		fmt.Printf("Подключено к демону: %s\n", a.Config.Socket)
+		go a.syncTray()
+	} else {
+		a.TrackingService.Notifier = a.newNotifier()
+		if closer, ok := a.TrackingService.Notifier.(io.Closer); ok {
+			defer closer.Close()
+		}
+
+		a.TrackingService.Scheduler = a.newScheduler()
+		defer a.TrackingService.Scheduler.Stop()
+
+		a.Handlers.RestoreSessions()
+
+		// При запущенном демоне HTTP API работает в нем
+		apiServer = a.startAPI()
+	}
+
+	// Бездействие отслеживается в сеансе пользователя и разбирается вопросами меню,
+	// поэтому монитор работает в клиенте, а не в демоне, и не запускается для tui.
+	// Исключение времени из сессии при запущенном демоне выполняет он.
+	if a.Config.UI == config.UIMenu {
+		if monitor := a.newIdleMonitor(); monitor != nil {
+			monitor.Start()
+			defer monitor.Stop()
+		}
+	}
+
+	if prompter, err := dialog.New(); err == nil {
+		a.Handlers.Dialog = prompter
+	} else {
+		a.Logger.Warnf("Остановка из трея без описания: %v", err)
+	}
+
+	// Полноэкранный интерфейс при выходе из трея закрывается
+	// и возвращает терминал в исходное состояние
+	var program *tea.Program
+	if a.Config.UI == config.UITUI {
+		program = a.newTUI()
+		menu = func() {
+			if _, err := program.Run(); err != nil {
+				a.Logger.Errorf("Ошибка полноэкранного интерфейса: %v", err)
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+
+	// Меню ждет ввода в терминале, поэтому при выходе из трея
+	// оно остается заблокированным до завершения процесса
+	terminal, _ := readline.GetState(int(os.Stdin.Fd()))
+	go func() {
+		menu()
+		a.Quit()
+	}()
+	<-a.quit
+
+	if program != nil {
+		program.Quit()
+		program.Wait()
+	}
+
+	a.Logger.Info("Завершение работы")
+	a.stopAPI(apiServer)
+	a.Handlers.Shutdown()
 	a.SystrayHandler.Quit()
+
+	// При выходе из трея терминал может остаться в режиме ввода меню
+	if terminal != nil {
+		readline.Restore(int(os.Stdin.Fd()), terminal)
+		fmt.Println()
+	}
+}
+
+// Quit - завершение работы приложения из главного меню или из трея
+func (a *App) Quit() {
+	a.quitOnce.Do(func() {
+		close(a.quit)
+	})
+}
+
+// newTUI - полноэкранный интерфейс над запущенным демоном или данными приложения
+func (a *App) newTUI() *tea.Program {
+	var backend daemon.Backend = a.Handlers
+	if a.Remote != nil {
+		backend = a.Remote
+	}
+
+	model := tui.New(backend, a.Handlers)
+	return tea.NewProgram(model, tea.WithAltScreen())
+}
+
+// startAPI - запуск HTTP API, если задан его адрес; nil - API не запущен
+func (a *App) startAPI() *api.Server {
+	if a.Config.HTTPAddr == "" {
+		return nil
+	}
+
+	token := a.Config.HTTPToken
+	if token == "" {
+		var err error
+		if token, err = api.LoadToken(a.Config.HTTPTokenFile); err != nil {
+			a.Logger.Errorf("HTTP API не запущен: %v", err)
+			fmt.Fprintf(os.Stderr, "HTTP API не запущен: %v\n", err)
+			return nil
+		}
+	}
+
+	server := api.NewServer(a.Handlers, a.Handlers, a.ProjectService, a.TrackingService, a.EntryService, token, a.Logger)
+	server.Static = web.Handler()
+	if err := server.Start(a.Config.HTTPAddr); err != nil {
+		a.Logger.Errorf("HTTP API не запущен: %v", err)
+		fmt.Fprintf(os.Stderr, "HTTP API не запущен: %v\n", err)
+		return nil
+	}
+
+	fmt.Printf("HTTP API: http://%s/api/\n", a.Config.HTTPAddr)
+	return server
+}
+
+// stopAPI - остановка HTTP API до сохранения данных при выходе
+func (a *App) stopAPI(server *api.Server) {
+	if server == nil {
+		return
+	}
+
+	if err := server.Close(); err != nil {
+		a.Logger.Warnf("Ошибка остановки HTTP API: %v", err)
+	}
+}
+
+// syncTray - обновление трея по состоянию демона до завершения работы
+func (a *App) syncTray() {
+	ticker := time.NewTicker(trayPollInterval)
+	defer ticker.Stop()
+
+	for {
+		a.Handlers.SyncTray()
+
+		select {
+		case <-a.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// newNotifier - отправитель уведомлений из конфигурации,
+// при ошибке уведомления выводятся в консоль
+func (a *App) newNotifier() notify.Notifier {
+	notifier, err := notify.New(notify.Options{
+		Kind:       a.Config.Notifier,
+		WebhookURL: a.Config.WebhookURL,
+	})
+	if err != nil {
+		a.Logger.Warnf("Ошибка настройки уведомлений: %v", err)
+		fmt.Fprintf(os.Stderr, "Уведомления будут выводиться в консоль: %v\n", err)
+		return &notify.ConsoleNotifier{Writer: os.Stderr, Bell: true}
+	}
+
+	a.Logger.Infof("Уведомления: %T", notifier)
+	return notifier
+}
+
+// newScheduler - планировщик напоминаний; в режиме помидоров
+// о перерывах напоминает движок помидоров
+func (a *App) newScheduler() *service.Scheduler {
+	workStart, workEnd, _ := service.ParseWorkHours(a.Config.WorkHours)
+
+	settings := service.ReminderSettings{
+		BreakEvery: time.Duration(a.Config.NotificationTime) * time.Second,
+		WorkStart:  workStart,
+		WorkEnd:    workEnd,
+		Interval:   a.Config.RemindInterval,
+	}
+	if a.Config.Pomodoro {
+		settings.BreakEvery = 0
+	}
+
+	return service.NewScheduler(settings, clock.Real{}, a.TrackingService.SendReminder)
+}
+
+// newIdleMonitor - монитор бездействия, nil если он отключен или команда недоступна
+func (a *App) newIdleMonitor() *idle.Monitor {
+	if a.Config.IdleThreshold <= 0 {
+		return nil
+	}
+
+	source := idle.NewCommandSource(a.Config.IdleCommand)
+	if _, err := source.IdleTime(); err != nil {
+		a.Logger.Warnf("Отслеживание бездействия отключено: %v", err)
+		return nil
+	}
+
+	a.Logger.Infof("Отслеживание бездействия: порог %v", a.Config.IdleThreshold)
+	return idle.NewMonitor(source, a.Config.IdleThreshold, a.Handlers.QueueIdleSpan)
+}
+
+// Close - освобождение ресурсов приложения
+func (a *App) Close() {
+	if a.Remote != nil {
+		a.Remote.Close()
+	}
+
+	if err := a.ProjectService.Storage.Close(); err != nil {
+		a.Logger.Errorf("Ошибка закрытия хранилища: %v", err)
+	}
+
+	if a.Lock != nil {
+		if err := a.Lock.Unlock(); err != nil {
+			a.Logger.Errorf("Ошибка снятия блокировки данных: %v", err)
+		}
+	}
+}
+
+// RunCommand - выполнение неинтерактивной команды, возвращает код завершения
+func (a *App) RunCommand(args []string) int {
+	a.Logger.Infof("Запуск команды: %v", args)
+	if len(args) > 0 && args[0] == "daemon" {
+		return a.RunDaemon(args[1:])
+	}
+	if len(args) > 0 && args[0] == "web" {
+		return a.RunWeb(args[1:])
+	}
+
+	return a.Handlers.RunCommand(args)
 }
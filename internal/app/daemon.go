@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
+	"github.com/MWT-proger/time-tracking/internal/app/handlers"
+	"github.com/MWT-proger/time-tracking/pkg/config"
+	"github.com/MWT-proger/time-tracking/pkg/pomodoro"
+)
+
+// isDaemonCommand - запуск демона командой daemon или web
+func isDaemonCommand(cfg *config.Config) bool {
+	return len(cfg.Args) > 0 && (cfg.Args[0] == "daemon" || cfg.Args[0] == "web")
+}
+
+// headlessTray - трей демона, который работает без графического окружения
+type headlessTray struct{}
+
+func (headlessTray) SetTracking(string, *time.Time)                 {}
+func (headlessTray) SetPaused(string, *time.Time, time.Duration)    {}
+func (headlessTray) SetPomodoro(string, pomodoro.Phase, *time.Time) {}
+func (headlessTray) SetProjects([]string)                           {}
+func (headlessTray) StopTrayTicker()                                {}
+
+var _ handlers.SystrayHandler = headlessTray{}
+
+// RunDaemon - работа в фоне: демон владеет данными и таймерами и выполняет
+// команды клиентов через Unix-сокет до сигнала завершения
+func (a *App) RunDaemon(args []string) int {
+	if len(args) != 0 {
+		fmt.Fprintln(os.Stderr, "Использование: daemon")
+		return handlers.ExitUsage
+	}
+
+	return a.serve(false)
+}
+
+// serve - работа демона до сигнала завершения; web - обязательный запуск
+// HTTP API с веб-интерфейсом и вывод адреса панели с токеном
+func (a *App) serve(web bool) int {
+	a.Logger.Infof("Запуск демона: %s", a.Config.Socket)
+	a.Handlers.SystrayHandler = headlessTray{}
+
+	a.TrackingService.Notifier = a.newNotifier()
+	if closer, ok := a.TrackingService.Notifier.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	a.TrackingService.Scheduler = a.newScheduler()
+	defer a.TrackingService.Scheduler.Stop()
+
+	// Слишком долгие сессии демон не останавливает, о них сообщает команда status
+	a.Handlers.RestoreTimers()
+
+	server, err := daemon.Listen(a.Config.Socket, a.Handlers, a.Logger)
+	if err != nil {
+		a.Logger.Errorf("Ошибка запуска демона: %v", err)
+		fmt.Fprintln(os.Stderr, err)
+		return handlers.ExitError
+	}
+
+	apiServer := a.startAPI()
+	if web && apiServer == nil {
+		server.Close()
+		a.Handlers.Shutdown()
+		return handlers.ExitError
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.Serve()
+	}()
+
+	fmt.Printf("Демон запущен: %s\n", a.Config.Socket)
+	if web {
+		fmt.Printf("Веб-интерфейс: http://%s/#token=%s\n", a.Config.HTTPAddr, apiServer.Token)
+	}
+
+	code := handlers.ExitOK
+	select {
+	case sig := <-signals:
+		a.Logger.Infof("Демон получил сигнал %v", sig)
+	case err := <-errs:
+		a.Logger.Errorf("Ошибка работы демона: %v", err)
+		fmt.Fprintln(os.Stderr, err)
+		code = handlers.ExitError
+	}
+
+	server.Close()
+	a.stopAPI(apiServer)
+	a.Handlers.Shutdown()
+	a.Logger.Info("Демон остановлен")
+	fmt.Println("Демон остановлен")
+
+	return code
+}
@@ -0,0 +1,301 @@
+// Package daemon - фоновый процесс, владеющий данными и таймерами, и клиент
+// для управления им через Unix-сокет по протоколу JSON-RPC
+package daemon
+
+import (
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/importer"
+	"github.com/MWT-proger/time-tracking/internal/service"
+)
+
+// Backend - операции, которые демон выполняет по запросам клиентов.
+// Реализуется обработчиками приложения в процессе демона и клиентом в остальных процессах.
+type Backend interface {
+	// Start - начало отслеживания проекта; sprint - имя спринта (пусто - активный),
+	// description - описание сессий, остановленных в режиме одного таймера.
+	// Возвращает проекты, отслеживание которых было остановлено.
+	Start(project, sprint, description string) ([]string, error)
+
+	// Stop - остановка отслеживания проекта, возвращает записанное время
+	Stop(project, description string) (time.Duration, error)
+
+	// Pause - приостановка отслеживания проекта
+	Pause(project string) error
+
+	// Resume - продолжение отслеживания проекта, возвращает длительность перерыва
+	Resume(project string) (time.Duration, error)
+
+	// Status - запущенные отслеживания
+	Status() ([]Session, error)
+
+	// List - проекты по алфавиту, all - вместе с архивными
+	List(all bool) ([]ProjectInfo, error)
+
+	// Report - отчет за период
+	Report(filter service.ReportFilter) (service.Report, error)
+
+	// Export - записи за период для выгрузки
+	Export(filter service.ReportFilter) ([]service.ExportRow, error)
+
+	// Cap - остановка отслеживания с записью не длиннее -max-session
+	Cap(project, description string) (time.Duration, error)
+
+	// Discard - отмена сессии без сохранения записи
+	Discard(project string) error
+
+	// ExcludeSpan - исключение периода из сессии, например времени бездействия.
+	// Возвращает длительность, на которую уменьшилось рабочее время.
+	ExcludeSpan(project string, start, end time.Time) (time.Duration, error)
+
+	// AddProject - создание проекта
+	AddProject(name string) error
+
+	// Archive - перенос проекта в архив или возврат из него
+	Archive(project string, archived bool) error
+
+	// Sprints - спринты проекта с затраченным временем
+	Sprints(project string) ([]SprintInfo, error)
+
+	// CreateSprint - создание спринта, он становится активным
+	CreateSprint(project, name, description string) error
+
+	// SetActiveSprint - выбор активного спринта проекта по имени
+	SetActiveSprint(project, sprint string) error
+
+	// Entries - записи времени проекта
+	Entries(project string) ([]Entry, error)
+
+	// FindEntry - запись по ID или его началу
+	FindEntry(project, id string) (domain.TimeEntry, error)
+
+	// AddEntry - добавление записи вручную; sprint - имя спринта (пусто - активный)
+	AddEntry(project string, start, end time.Time, description, sprint string, noSprint bool) (domain.TimeEntry, error)
+
+	// EditEntry - изменение записи
+	EditEntry(project, id string, edit EntryEdit) (domain.TimeEntry, error)
+
+	// DeleteEntry - удаление записи
+	DeleteEntry(project, id string) error
+
+	// SplitEntry - разделение записи на две в момент at
+	SplitEntry(project, id string, at time.Time) (domain.TimeEntry, domain.TimeEntry, error)
+
+	// MergeEntries - объединение двух соседних записей
+	MergeEntries(project, firstID, secondID string) (domain.TimeEntry, error)
+
+	// Import - добавление импортированных записей, dryRun - только проверка
+	Import(records []importer.Record, defaultProject string, dryRun bool) (service.ImportResult, error)
+
+	// RestoreBackup - восстановление данных из резервной копии с номером n (1 - самая новая)
+	RestoreBackup(n int) error
+}
+
+// Session - запущенное отслеживание проекта
+type Session struct {
+	Project  string        `json:"project"`
+	Sprint   string        `json:"sprint,omitempty"`
+	Start    time.Time     `json:"start"`
+	PausedAt *time.Time    `json:"paused_at,omitempty"`
+	Breaks   time.Duration `json:"breaks"`
+	Elapsed  time.Duration `json:"elapsed"`
+
+	// Сессия идет дольше -max-session
+	Long bool `json:"long,omitempty"`
+}
+
+// ProjectInfo - проект в списке
+type ProjectInfo struct {
+	Name     string `json:"name"`
+	Archived bool   `json:"archived,omitempty"`
+	Running  bool   `json:"running,omitempty"`
+	Paused   bool   `json:"paused,omitempty"`
+}
+
+// SprintInfo - спринт проекта в списке
+type SprintInfo struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	StartDate   string        `json:"start_date,omitempty"`
+	Active      bool          `json:"active,omitempty"`
+	Spent       time.Duration `json:"spent"`
+}
+
+// Entry - запись времени с именем ее спринта
+type Entry struct {
+	domain.TimeEntry
+	Sprint string `json:"sprint,omitempty"`
+}
+
+// EntryEdit - изменяемые поля записи, nil - поле не меняется.
+// Sprint - имя спринта, пустая строка убирает запись из спринта.
+type EntryEdit struct {
+	Description *string        `json:"description,omitempty"`
+	Start       *time.Time     `json:"start,omitempty"`
+	Duration    *time.Duration `json:"duration,omitempty"`
+	Sprint      *string        `json:"sprint,omitempty"`
+}
+
+// Аргументы и ответы методов RPC
+
+// StartArgs - аргументы Tracker.Start
+type StartArgs struct {
+	Project     string `json:"project"`
+	Sprint      string `json:"sprint,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// StartReply - ответ Tracker.Start
+type StartReply struct {
+	Stopped []string `json:"stopped,omitempty"`
+}
+
+// StopArgs - аргументы Tracker.Stop
+type StopArgs struct {
+	Project     string `json:"project"`
+	Description string `json:"description,omitempty"`
+}
+
+// ProjectArgs - аргументы методов, которым нужен только проект
+type ProjectArgs struct {
+	Project string `json:"project"`
+}
+
+// ExcludeArgs - аргументы Tracker.ExcludeSpan
+type ExcludeArgs struct {
+	Project string    `json:"project"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+}
+
+// DurationReply - ответ с длительностью
+type DurationReply struct {
+	Duration time.Duration `json:"duration"`
+}
+
+// Empty - пустые аргументы или ответ
+type Empty struct{}
+
+// StatusReply - ответ Tracker.Status
+type StatusReply struct {
+	Sessions []Session `json:"sessions"`
+}
+
+// ListArgs - аргументы Tracker.List
+type ListArgs struct {
+	All bool `json:"all,omitempty"`
+}
+
+// ListReply - ответ Tracker.List
+type ListReply struct {
+	Projects []ProjectInfo `json:"projects"`
+}
+
+// ReportArgs - аргументы Tracker.Report и Tracker.Export, даты в формате ГГГГ-ММ-ДД
+type ReportArgs struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Project string `json:"project,omitempty"`
+	GroupBy string `json:"group_by,omitempty"`
+}
+
+// ReportReply - ответ Tracker.Report
+type ReportReply struct {
+	Rows  []service.ReportRow `json:"rows"`
+	Total time.Duration       `json:"total"`
+}
+
+// ExportReply - ответ Tracker.Export
+type ExportReply struct {
+	Rows []service.ExportRow `json:"rows"`
+}
+
+// ArchiveArgs - аргументы Tracker.Archive
+type ArchiveArgs struct {
+	Project  string `json:"project"`
+	Archived bool   `json:"archived"`
+}
+
+// SprintsReply - ответ Tracker.Sprints
+type SprintsReply struct {
+	Sprints []SprintInfo `json:"sprints"`
+}
+
+// SprintArgs - аргументы Tracker.CreateSprint и Tracker.SetActiveSprint
+type SprintArgs struct {
+	Project     string `json:"project"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// EntriesReply - ответ Tracker.Entries
+type EntriesReply struct {
+	Entries []Entry `json:"entries"`
+}
+
+// EntryArgs - аргументы методов одной записи
+type EntryArgs struct {
+	Project string `json:"project"`
+	ID      string `json:"id"`
+}
+
+// EntryReply - ответ с записью
+type EntryReply struct {
+	Entry domain.TimeEntry `json:"entry"`
+}
+
+// AddEntryArgs - аргументы Tracker.AddEntry
+type AddEntryArgs struct {
+	Project     string    `json:"project"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Description string    `json:"description,omitempty"`
+	Sprint      string    `json:"sprint,omitempty"`
+	NoSprint    bool      `json:"no_sprint,omitempty"`
+}
+
+// EditEntryArgs - аргументы Tracker.EditEntry
+type EditEntryArgs struct {
+	Project string    `json:"project"`
+	ID      string    `json:"id"`
+	Edit    EntryEdit `json:"edit"`
+}
+
+// SplitEntryArgs - аргументы Tracker.SplitEntry
+type SplitEntryArgs struct {
+	Project string    `json:"project"`
+	ID      string    `json:"id"`
+	At      time.Time `json:"at"`
+}
+
+// SplitEntryReply - ответ Tracker.SplitEntry
+type SplitEntryReply struct {
+	First  domain.TimeEntry `json:"first"`
+	Second domain.TimeEntry `json:"second"`
+}
+
+// MergeEntriesArgs - аргументы Tracker.MergeEntries
+type MergeEntriesArgs struct {
+	Project  string `json:"project"`
+	FirstID  string `json:"first_id"`
+	SecondID string `json:"second_id"`
+}
+
+// ImportArgs - аргументы Tracker.Import
+type ImportArgs struct {
+	Records        []importer.Record `json:"records"`
+	DefaultProject string            `json:"default_project,omitempty"`
+	DryRun         bool              `json:"dry_run,omitempty"`
+}
+
+// ImportReply - ответ Tracker.Import
+type ImportReply struct {
+	Result service.ImportResult `json:"result"`
+}
+
+// BackupArgs - аргументы Tracker.RestoreBackup
+type BackupArgs struct {
+	N int `json:"n"`
+}
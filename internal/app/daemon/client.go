@@ -0,0 +1,222 @@
+package daemon
+
+import (
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/importer"
+	"github.com/MWT-proger/time-tracking/internal/service"
+)
+
+// dialTimeout - время ожидания подключения к демону
+const dialTimeout = time.Second
+
+// Client - клиент демона, реализует Backend вызовами RPC
+type Client struct {
+	rpc *rpc.Client
+}
+
+// Dial - подключение к демону через сокет path
+func Dial(path string) (*Client, error) {
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{rpc: jsonrpc.NewClient(conn)}, nil
+}
+
+// Close - отключение от демона
+func (c *Client) Close() error {
+	return c.rpc.Close()
+}
+
+// Start - начало отслеживания
+func (c *Client) Start(project, sprint, description string) ([]string, error) {
+	var reply StartReply
+	err := c.rpc.Call("Tracker.Start", StartArgs{Project: project, Sprint: sprint, Description: description}, &reply)
+	return reply.Stopped, err
+}
+
+// Stop - остановка отслеживания
+func (c *Client) Stop(project, description string) (time.Duration, error) {
+	var reply DurationReply
+	err := c.rpc.Call("Tracker.Stop", StopArgs{Project: project, Description: description}, &reply)
+	return reply.Duration, err
+}
+
+// Pause - приостановка отслеживания
+func (c *Client) Pause(project string) error {
+	return c.rpc.Call("Tracker.Pause", ProjectArgs{Project: project}, &Empty{})
+}
+
+// Resume - продолжение отслеживания
+func (c *Client) Resume(project string) (time.Duration, error) {
+	var reply DurationReply
+	err := c.rpc.Call("Tracker.Resume", ProjectArgs{Project: project}, &reply)
+	return reply.Duration, err
+}
+
+// Status - запущенные отслеживания
+func (c *Client) Status() ([]Session, error) {
+	var reply StatusReply
+	err := c.rpc.Call("Tracker.Status", Empty{}, &reply)
+	return reply.Sessions, err
+}
+
+// List - список проектов
+func (c *Client) List(all bool) ([]ProjectInfo, error) {
+	var reply ListReply
+	err := c.rpc.Call("Tracker.List", ListArgs{All: all}, &reply)
+	return reply.Projects, err
+}
+
+// Report - отчет за период, строится демоном в его часовом поясе
+func (c *Client) Report(filter service.ReportFilter) (service.Report, error) {
+	args := ReportArgs{
+		From:    filter.From.Format("2006-01-02"),
+		To:      filter.To.Format("2006-01-02"),
+		Project: filter.Project,
+		GroupBy: string(filter.GroupBy),
+	}
+
+	var reply ReportReply
+	if err := c.rpc.Call("Tracker.Report", args, &reply); err != nil {
+		return service.Report{}, err
+	}
+
+	return service.Report{Filter: filter, Rows: reply.Rows, Total: reply.Total}, nil
+}
+
+// Export - записи за период для выгрузки
+func (c *Client) Export(filter service.ReportFilter) ([]service.ExportRow, error) {
+	args := ReportArgs{
+		From:    filter.From.Format("2006-01-02"),
+		To:      filter.To.Format("2006-01-02"),
+		Project: filter.Project,
+	}
+
+	var reply ExportReply
+	if err := c.rpc.Call("Tracker.Export", args, &reply); err != nil {
+		return nil, err
+	}
+
+	return reply.Rows, nil
+}
+
+// Cap - остановка отслеживания с ограничением длительности
+func (c *Client) Cap(project, description string) (time.Duration, error) {
+	var reply DurationReply
+	err := c.rpc.Call("Tracker.Cap", StopArgs{Project: project, Description: description}, &reply)
+	return reply.Duration, err
+}
+
+// Discard - отмена сессии
+func (c *Client) Discard(project string) error {
+	return c.rpc.Call("Tracker.Discard", ProjectArgs{Project: project}, &Empty{})
+}
+
+// ExcludeSpan - исключение периода из сессии
+func (c *Client) ExcludeSpan(project string, start, end time.Time) (time.Duration, error) {
+	var reply DurationReply
+	err := c.rpc.Call("Tracker.ExcludeSpan", ExcludeArgs{Project: project, Start: start, End: end}, &reply)
+	return reply.Duration, err
+}
+
+// AddProject - создание проекта
+func (c *Client) AddProject(name string) error {
+	return c.rpc.Call("Tracker.AddProject", ProjectArgs{Project: name}, &Empty{})
+}
+
+// Archive - перенос проекта в архив или возврат из него
+func (c *Client) Archive(project string, archived bool) error {
+	return c.rpc.Call("Tracker.Archive", ArchiveArgs{Project: project, Archived: archived}, &Empty{})
+}
+
+// Sprints - спринты проекта
+func (c *Client) Sprints(project string) ([]SprintInfo, error) {
+	var reply SprintsReply
+	err := c.rpc.Call("Tracker.Sprints", ProjectArgs{Project: project}, &reply)
+	return reply.Sprints, err
+}
+
+// CreateSprint - создание спринта
+func (c *Client) CreateSprint(project, name, description string) error {
+	return c.rpc.Call("Tracker.CreateSprint", SprintArgs{Project: project, Name: name, Description: description}, &Empty{})
+}
+
+// SetActiveSprint - выбор активного спринта
+func (c *Client) SetActiveSprint(project, sprint string) error {
+	return c.rpc.Call("Tracker.SetActiveSprint", SprintArgs{Project: project, Name: sprint}, &Empty{})
+}
+
+// Entries - записи времени проекта
+func (c *Client) Entries(project string) ([]Entry, error) {
+	var reply EntriesReply
+	err := c.rpc.Call("Tracker.Entries", ProjectArgs{Project: project}, &reply)
+	return reply.Entries, err
+}
+
+// FindEntry - поиск записи
+func (c *Client) FindEntry(project, id string) (domain.TimeEntry, error) {
+	var reply EntryReply
+	err := c.rpc.Call("Tracker.FindEntry", EntryArgs{Project: project, ID: id}, &reply)
+	return reply.Entry, err
+}
+
+// AddEntry - добавление записи вручную
+func (c *Client) AddEntry(project string, start, end time.Time, description, sprint string, noSprint bool) (domain.TimeEntry, error) {
+	args := AddEntryArgs{
+		Project:     project,
+		Start:       start,
+		End:         end,
+		Description: description,
+		Sprint:      sprint,
+		NoSprint:    noSprint,
+	}
+
+	var reply EntryReply
+	err := c.rpc.Call("Tracker.AddEntry", args, &reply)
+	return reply.Entry, err
+}
+
+// EditEntry - изменение записи
+func (c *Client) EditEntry(project, id string, edit EntryEdit) (domain.TimeEntry, error) {
+	var reply EntryReply
+	err := c.rpc.Call("Tracker.EditEntry", EditEntryArgs{Project: project, ID: id, Edit: edit}, &reply)
+	return reply.Entry, err
+}
+
+// DeleteEntry - удаление записи
+func (c *Client) DeleteEntry(project, id string) error {
+	return c.rpc.Call("Tracker.DeleteEntry", EntryArgs{Project: project, ID: id}, &Empty{})
+}
+
+// SplitEntry - разделение записи
+func (c *Client) SplitEntry(project, id string, at time.Time) (domain.TimeEntry, domain.TimeEntry, error) {
+	var reply SplitEntryReply
+	err := c.rpc.Call("Tracker.SplitEntry", SplitEntryArgs{Project: project, ID: id, At: at}, &reply)
+	return reply.First, reply.Second, err
+}
+
+// MergeEntries - объединение записей
+func (c *Client) MergeEntries(project, firstID, secondID string) (domain.TimeEntry, error) {
+	var reply EntryReply
+	err := c.rpc.Call("Tracker.MergeEntries", MergeEntriesArgs{Project: project, FirstID: firstID, SecondID: secondID}, &reply)
+	return reply.Entry, err
+}
+
+// Import - импорт записей демоном
+func (c *Client) Import(records []importer.Record, defaultProject string, dryRun bool) (service.ImportResult, error) {
+	var reply ImportReply
+	err := c.rpc.Call("Tracker.Import", ImportArgs{Records: records, DefaultProject: defaultProject, DryRun: dryRun}, &reply)
+	return reply.Result, err
+}
+
+// RestoreBackup - восстановление данных демоном из резервной копии
+func (c *Client) RestoreBackup(n int) error {
+	return c.rpc.Call("Tracker.RestoreBackup", BackupArgs{N: n}, &Empty{})
+}
@@ -0,0 +1,284 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/service"
+	"github.com/MWT-proger/time-tracking/pkg/logger"
+)
+
+// ErrRunning - демон для этого сокета уже запущен
+var ErrRunning = errors.New("демон уже запущен")
+
+// Tracker - методы RPC, доступные клиентам под именем "Tracker"
+type Tracker struct {
+	Backend Backend
+}
+
+// Start - начало отслеживания
+func (t *Tracker) Start(args StartArgs, reply *StartReply) error {
+	stopped, err := t.Backend.Start(args.Project, args.Sprint, args.Description)
+	reply.Stopped = stopped
+	return err
+}
+
+// Stop - остановка отслеживания
+func (t *Tracker) Stop(args StopArgs, reply *DurationReply) error {
+	elapsed, err := t.Backend.Stop(args.Project, args.Description)
+	reply.Duration = elapsed
+	return err
+}
+
+// Pause - приостановка отслеживания
+func (t *Tracker) Pause(args ProjectArgs, reply *Empty) error {
+	return t.Backend.Pause(args.Project)
+}
+
+// Resume - продолжение отслеживания
+func (t *Tracker) Resume(args ProjectArgs, reply *DurationReply) error {
+	pause, err := t.Backend.Resume(args.Project)
+	reply.Duration = pause
+	return err
+}
+
+// Status - запущенные отслеживания
+func (t *Tracker) Status(args Empty, reply *StatusReply) error {
+	sessions, err := t.Backend.Status()
+	reply.Sessions = sessions
+	return err
+}
+
+// List - список проектов
+func (t *Tracker) List(args ListArgs, reply *ListReply) error {
+	projects, err := t.Backend.List(args.All)
+	reply.Projects = projects
+	return err
+}
+
+// Report - отчет за период
+func (t *Tracker) Report(args ReportArgs, reply *ReportReply) error {
+	filter, err := reportFilter(args)
+	if err != nil {
+		return err
+	}
+
+	report, err := t.Backend.Report(filter)
+	reply.Rows = report.Rows
+	reply.Total = report.Total
+	return err
+}
+
+// Export - записи за период для выгрузки
+func (t *Tracker) Export(args ReportArgs, reply *ExportReply) error {
+	filter, err := reportFilter(args)
+	if err != nil {
+		return err
+	}
+
+	reply.Rows, err = t.Backend.Export(filter)
+	return err
+}
+
+// reportFilter - фильтр отчета из аргументов, даты в местном времени демона
+func reportFilter(args ReportArgs) (service.ReportFilter, error) {
+	filter := service.ReportFilter{Project: args.Project, GroupBy: service.GroupByDay}
+
+	var err error
+	if filter.From, err = time.ParseInLocation("2006-01-02", args.From, time.Local); err != nil {
+		return filter, fmt.Errorf("неверная дата начала '%s'", args.From)
+	}
+	if filter.To, err = time.ParseInLocation("2006-01-02", args.To, time.Local); err != nil {
+		return filter, fmt.Errorf("неверная дата окончания '%s'", args.To)
+	}
+	if args.GroupBy != "" {
+		if filter.GroupBy, err = service.ParseGroupBy(args.GroupBy); err != nil {
+			return filter, err
+		}
+	}
+
+	return filter, nil
+}
+
+// Cap - остановка отслеживания с ограничением длительности
+func (t *Tracker) Cap(args StopArgs, reply *DurationReply) error {
+	elapsed, err := t.Backend.Cap(args.Project, args.Description)
+	reply.Duration = elapsed
+	return err
+}
+
+// Discard - отмена сессии
+func (t *Tracker) Discard(args ProjectArgs, reply *Empty) error {
+	return t.Backend.Discard(args.Project)
+}
+
+// ExcludeSpan - исключение периода из сессии
+func (t *Tracker) ExcludeSpan(args ExcludeArgs, reply *DurationReply) error {
+	excluded, err := t.Backend.ExcludeSpan(args.Project, args.Start, args.End)
+	reply.Duration = excluded
+	return err
+}
+
+// AddProject - создание проекта
+func (t *Tracker) AddProject(args ProjectArgs, reply *Empty) error {
+	return t.Backend.AddProject(args.Project)
+}
+
+// Archive - перенос проекта в архив или возврат из него
+func (t *Tracker) Archive(args ArchiveArgs, reply *Empty) error {
+	return t.Backend.Archive(args.Project, args.Archived)
+}
+
+// Sprints - спринты проекта
+func (t *Tracker) Sprints(args ProjectArgs, reply *SprintsReply) error {
+	sprints, err := t.Backend.Sprints(args.Project)
+	reply.Sprints = sprints
+	return err
+}
+
+// CreateSprint - создание спринта
+func (t *Tracker) CreateSprint(args SprintArgs, reply *Empty) error {
+	return t.Backend.CreateSprint(args.Project, args.Name, args.Description)
+}
+
+// SetActiveSprint - выбор активного спринта
+func (t *Tracker) SetActiveSprint(args SprintArgs, reply *Empty) error {
+	return t.Backend.SetActiveSprint(args.Project, args.Name)
+}
+
+// Entries - записи времени проекта
+func (t *Tracker) Entries(args ProjectArgs, reply *EntriesReply) error {
+	entries, err := t.Backend.Entries(args.Project)
+	reply.Entries = entries
+	return err
+}
+
+// FindEntry - поиск записи
+func (t *Tracker) FindEntry(args EntryArgs, reply *EntryReply) error {
+	entry, err := t.Backend.FindEntry(args.Project, args.ID)
+	reply.Entry = entry
+	return err
+}
+
+// AddEntry - добавление записи вручную
+func (t *Tracker) AddEntry(args AddEntryArgs, reply *EntryReply) error {
+	entry, err := t.Backend.AddEntry(args.Project, args.Start, args.End, args.Description, args.Sprint, args.NoSprint)
+	reply.Entry = entry
+	return err
+}
+
+// EditEntry - изменение записи
+func (t *Tracker) EditEntry(args EditEntryArgs, reply *EntryReply) error {
+	entry, err := t.Backend.EditEntry(args.Project, args.ID, args.Edit)
+	reply.Entry = entry
+	return err
+}
+
+// DeleteEntry - удаление записи
+func (t *Tracker) DeleteEntry(args EntryArgs, reply *Empty) error {
+	return t.Backend.DeleteEntry(args.Project, args.ID)
+}
+
+// SplitEntry - разделение записи
+func (t *Tracker) SplitEntry(args SplitEntryArgs, reply *SplitEntryReply) error {
+	first, second, err := t.Backend.SplitEntry(args.Project, args.ID, args.At)
+	reply.First, reply.Second = first, second
+	return err
+}
+
+// MergeEntries - объединение записей
+func (t *Tracker) MergeEntries(args MergeEntriesArgs, reply *EntryReply) error {
+	entry, err := t.Backend.MergeEntries(args.Project, args.FirstID, args.SecondID)
+	reply.Entry = entry
+	return err
+}
+
+// Import - импорт записей
+func (t *Tracker) Import(args ImportArgs, reply *ImportReply) error {
+	result, err := t.Backend.Import(args.Records, args.DefaultProject, args.DryRun)
+	reply.Result = result
+	return err
+}
+
+// RestoreBackup - восстановление данных из резервной копии
+func (t *Tracker) RestoreBackup(args BackupArgs, reply *Empty) error {
+	return t.Backend.RestoreBackup(args.N)
+}
+
+// Server - сервер JSON-RPC на Unix-сокете
+type Server struct {
+	Path   string
+	Logger logger.Logger
+
+	rpc      *rpc.Server
+	listener net.Listener
+}
+
+// Listen - открытие сокета path. Оставшийся от упавшего демона сокет удаляется,
+// если к нему нельзя подключиться.
+func Listen(path string, backend Backend, log logger.Logger) (*Server, error) {
+	if client, err := Dial(path); err == nil {
+		client.Close()
+		return nil, fmt.Errorf("%w: %s", ErrRunning, path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("ошибка удаления старого сокета: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("ошибка создания директории сокета: %w", err)
+	}
+
+	server := rpc.NewServer()
+	if err := server.Register(&Tracker{Backend: backend}); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия сокета %s: %w", path, err)
+	}
+
+	// Управлять демоном может только владелец
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("ошибка установки прав сокета: %w", err)
+	}
+
+	return &Server{
+		Path:     path,
+		Logger:   log,
+		rpc:      server,
+		listener: listener,
+	}, nil
+}
+
+// Serve - прием подключений до вызова Close
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка приема подключения: %w", err)
+		}
+
+		s.Logger.Debug("Подключение клиента к демону")
+		go s.rpc.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// Close - закрытие сокета; уже подключенные клиенты обслуживаются до отключения
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	os.Remove(s.Path)
+
+	return err
+}
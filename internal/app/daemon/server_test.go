@@ -0,0 +1,307 @@
+package daemon_test
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
+	"github.com/MWT-proger/time-tracking/internal/app/handlers"
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/importer"
+	"github.com/MWT-proger/time-tracking/internal/service"
+	"github.com/MWT-proger/time-tracking/internal/storage"
+	"github.com/MWT-proger/time-tracking/pkg/config"
+	"github.com/MWT-proger/time-tracking/pkg/logger"
+	"github.com/MWT-proger/time-tracking/pkg/pomodoro"
+)
+
+// trayStub - трей, который ничего не показывает
+type trayStub struct{}
+
+func (trayStub) SetTracking(string, *time.Time)                 {}
+func (trayStub) SetPaused(string, *time.Time, time.Duration)    {}
+func (trayStub) SetPomodoro(string, pomodoro.Phase, *time.Time) {}
+func (trayStub) SetProjects([]string)                           {}
+func (trayStub) StopTrayTicker()                                {}
+
+// newTestClient - демон поверх обработчиков с данными в памяти и подключенный к нему клиент
+func newTestClient(t *testing.T) (*daemon.Client, *handlers.Handlers) {
+	t.Helper()
+
+	log := logger.NewLogger("error", io.Discard)
+	cfg := config.DefaultConfig()
+
+	projectService := service.NewProjectService(log, storage.NewMemoryStorage(service.CurrentSchemaVersion))
+	trackingService := service.NewTrackingService(projectService, log, cfg)
+	entryService := service.NewEntryService(projectService, log)
+	reportService := service.NewReportService(projectService, log)
+
+	h := handlers.NewHandlers(projectService, trackingService, entryService, reportService, trayStub{}, log, cfg)
+	h.SetProjects(map[string]*domain.Project{})
+
+	server, err := daemon.Listen(filepath.Join(t.TempDir(), "tt.sock"), h, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve()
+	t.Cleanup(func() { server.Close() })
+
+	client, err := daemon.Dial(server.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client, h
+}
+
+func TestProjectsAndSprints(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	if err := client.AddProject("alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AddProject("alpha"); err == nil {
+		t.Error("повторное создание проекта без ошибки")
+	}
+
+	for _, name := range []string{"S1", "S2"} {
+		if err := client.CreateSprint("alpha", name, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.SetActiveSprint("alpha", "S1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetActiveSprint("alpha", "S3"); err == nil {
+		t.Error("выбор несуществующего спринта без ошибки")
+	}
+
+	sprints, err := client.Sprints("alpha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	active := map[string]bool{}
+	for _, sprint := range sprints {
+		active[sprint.Name] = sprint.Active
+	}
+	if len(sprints) != 2 || !active["S1"] || active["S2"] {
+		t.Errorf("спринты: %+v", sprints)
+	}
+
+	if err := client.Archive("alpha", true); err != nil {
+		t.Fatal(err)
+	}
+	projects, err := client.List(true)
+	if err != nil || len(projects) != 1 || !projects[0].Archived {
+		t.Errorf("проекты после архивирования: %+v, %v", projects, err)
+	}
+	if err := client.Archive("alpha", false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEntries(t *testing.T) {
+	client, _ := newTestClient(t)
+	if err := client.AddProject("alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CreateSprint("alpha", "S1", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	entry, err := client.AddEntry("alpha", start, start.Add(2*time.Hour), "анализ", "S1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !entry.Start.Equal(start) || entry.Description != "анализ" {
+		t.Errorf("добавленная запись: %+v", entry)
+	}
+
+	entries, err := client.Entries("alpha")
+	if err != nil || len(entries) != 1 || entries[0].Sprint != "S1" || entries[0].ID != entry.ID {
+		t.Fatalf("записи: %+v, %v", entries, err)
+	}
+
+	found, err := client.FindEntry("alpha", entry.ID[:8])
+	if err != nil || found.ID != entry.ID {
+		t.Errorf("поиск записи: %+v, %v", found, err)
+	}
+
+	noSprint := ""
+	description := "разработка"
+	edited, err := client.EditEntry("alpha", entry.ID, daemon.EntryEdit{Description: &description, Sprint: &noSprint})
+	if err != nil || edited.Description != description || edited.SprintID != "" {
+		t.Errorf("измененная запись: %+v, %v", edited, err)
+	}
+
+	first, second, err := client.SplitEntry("alpha", entry.ID, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.End.Equal(start.Add(time.Hour)) || !second.Start.Equal(start.Add(time.Hour)) {
+		t.Errorf("разделенные записи: %+v, %+v", first, second)
+	}
+
+	merged, err := client.MergeEntries("alpha", first.ID, second.ID)
+	if err != nil || merged.Duration() != 2*time.Hour {
+		t.Errorf("объединенная запись: %+v, %v", merged, err)
+	}
+
+	if err := client.DeleteEntry("alpha", merged.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteEntry("alpha", merged.ID); err == nil {
+		t.Error("удаление удаленной записи без ошибки")
+	}
+}
+
+func TestExport(t *testing.T) {
+	client, _ := newTestClient(t)
+	if err := client.AddProject("alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CreateSprint("alpha", "S1", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	if _, err := client.AddEntry("alpha", start, start.Add(90*time.Minute), "анализ", "", false); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := client.Export(service.ReportFilter{From: start, To: start})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Project != "alpha" || rows[0].Sprint != "S1" || !rows[0].Start.Equal(start) ||
+		rows[0].Duration != 90*time.Minute || rows[0].Description != "анализ" {
+		t.Errorf("выгрузка: %+v", rows)
+	}
+
+	if _, err := client.Export(service.ReportFilter{From: start, To: start, Project: "beta"}); err == nil {
+		t.Error("выгрузка несуществующего проекта без ошибки")
+	}
+}
+
+func TestCapDiscard(t *testing.T) {
+	client, h := newTestClient(t)
+	if err := client.AddProject("alpha"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Сессия, оставленная запущенной на сутки
+	shiftStart := func() {
+		t.Helper()
+		err := h.WithProjects(func(projects map[string]*domain.Project) error {
+			start := projects["alpha"].StartTime.Add(-24 * time.Hour)
+			projects["alpha"].StartTime = &start
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := client.Start("alpha", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	shiftStart()
+
+	elapsed, err := client.Cap("alpha", "забыл остановить")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit := config.DefaultConfig().MaxSession; elapsed != limit {
+		t.Errorf("записано %v, ожидалось %v", elapsed, limit)
+	}
+
+	if _, err := client.Start("alpha", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Discard("alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Discard("alpha"); err == nil {
+		t.Error("отмена без запущенной сессии без ошибки")
+	}
+
+	entries, err := client.Entries("alpha")
+	if err != nil || len(entries) != 1 {
+		t.Errorf("записи после отмены: %+v, %v", entries, err)
+	}
+}
+
+func TestExcludeSpan(t *testing.T) {
+	client, h := newTestClient(t)
+	if err := client.AddProject("alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Start("alpha", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Сессия, запущенная два часа назад
+	var start time.Time
+	err := h.WithProjects(func(projects map[string]*domain.Project) error {
+		start = projects["alpha"].StartTime.Add(-2 * time.Hour)
+		projects["alpha"].StartTime = &start
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ExcludeSpan("alpha", start.Add(-2*time.Hour), start.Add(-time.Hour)); err == nil {
+		t.Error("исключение периода вне сессии без ошибки")
+	}
+
+	// Период до начала сессии обрезается по нему
+	excluded, err := client.ExcludeSpan("alpha", start.Add(-time.Hour), start.Add(30*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if excluded != 30*time.Minute {
+		t.Errorf("исключено %v, ожидалось 30m", excluded)
+	}
+
+	if sessions, err := client.Status(); err != nil || len(sessions) != 1 || sessions[0].Breaks != 30*time.Minute {
+		t.Errorf("сессии после исключения: %+v, %v", sessions, err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	records := []importer.Record{
+		{Project: "alpha", Sprint: "S1", Start: start, End: start.Add(time.Hour), Description: "анализ"},
+		{Project: "beta", Start: start, End: start.Add(30 * time.Minute)},
+	}
+
+	result, err := client.Import(records, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.DryRun || result.Added != 2 || len(result.Projects) != 2 {
+		t.Errorf("проверка импорта: %+v", result)
+	}
+	if projects, _ := client.List(true); len(projects) != 0 {
+		t.Errorf("проверка импорта изменила данные: %+v", projects)
+	}
+
+	if result, err = client.Import(records, "", false); err != nil || result.Added != 2 {
+		t.Fatalf("импорт: %+v, %v", result, err)
+	}
+	if result, err = client.Import(records, "", false); err != nil || result.Duplicates != 2 {
+		t.Errorf("повторный импорт: %+v, %v", result, err)
+	}
+
+	entries, err := client.Entries("alpha")
+	if err != nil || len(entries) != 1 || entries[0].Sprint != "S1" {
+		t.Errorf("импортированные записи: %+v, %v", entries, err)
+	}
+}
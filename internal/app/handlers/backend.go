@@ -0,0 +1,544 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/importer"
+	"github.com/MWT-proger/time-tracking/internal/service"
+	"github.com/MWT-proger/time-tracking/internal/storage"
+)
+
+// Handlers выполняет операции демона над своими данными без вопросов в терминале
+var _ daemon.Backend = (*Handlers)(nil)
+
+// backend - исполнитель операций: запущенный демон или сами обработчики
+func (h *Handlers) backend() daemon.Backend {
+	if h.Remote != nil {
+		return h.Remote
+	}
+
+	return h
+}
+
+// WithProjects - выполнение fn с проектами под блокировкой обработчиков,
+// через него данные читают и изменяют HTTP API и веб-интерфейс
+func (h *Handlers) WithProjects(fn func(projects map[string]*domain.Project) error) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return fn(h.Projects)
+}
+
+// Start - начало отслеживания проекта. В режиме одного таймера запущенные
+// проекты останавливаются с описанием description.
+func (h *Handlers) Start(projectName, sprintName, description string) ([]string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if sprintName != "" {
+		sprint, err := h.ProjectService.FindSprintByName(h.Projects, projectName, sprintName)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := h.ProjectService.SetActiveSprint(h.Projects, projectName, sprint.ID); err != nil {
+			h.Logger.Errorf("Ошибка установки активного спринта: %v", err)
+			return nil, err
+		}
+	}
+
+	var stopped []string
+	for _, running := range h.TrackingService.ConflictingSessions(h.Projects, projectName) {
+		if _, err := h.stop(running, description); err != nil {
+			return stopped, err
+		}
+		stopped = append(stopped, running)
+	}
+
+	if err := h.TrackingService.StartTracking(h.Projects, projectName); err != nil {
+		h.Logger.Errorf("Ошибка начала отслеживания: %v", err)
+		return stopped, err
+	}
+
+	h.Logger.Infof("Начато отслеживание для проекта: %s", projectName)
+	h.SystrayHandler.SetTracking(projectName, h.Projects[projectName].StartTime)
+	h.startPomodoro(projectName)
+
+	return stopped, nil
+}
+
+// Stop - остановка отслеживания проекта
+func (h *Handlers) Stop(projectName, description string) (time.Duration, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.stop(projectName, description)
+}
+
+// stop - остановка отслеживания, вызывается под h.mu
+func (h *Handlers) stop(projectName, description string) (time.Duration, error) {
+	elapsed, err := h.TrackingService.StopTracking(h.Projects, projectName, description)
+	if err != nil {
+		h.Logger.Errorf("Ошибка остановки отслеживания: %v", err)
+		return 0, err
+	}
+
+	h.Logger.Infof("Отслеживание остановлено для проекта %s. Время: %v", projectName, elapsed)
+	h.stopPomodoro(projectName)
+	h.SystrayHandler.SetTracking(projectName, nil)
+
+	return elapsed, nil
+}
+
+// Pause - приостановка отслеживания проекта
+func (h *Handlers) Pause(projectName string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.TrackingService.PauseTracking(h.Projects, projectName); err != nil {
+		h.Logger.Errorf("Ошибка приостановки отслеживания: %v", err)
+		return err
+	}
+
+	// Ручная пауза прерывает текущий помидор
+	h.stopPomodoro(projectName)
+
+	project := h.Projects[projectName]
+	h.Logger.Infof("Отслеживание приостановлено для проекта: %s", projectName)
+	h.SystrayHandler.SetPaused(projectName, project.PausedAt, domain.BreaksDuration(project.Breaks))
+
+	return nil
+}
+
+// Resume - продолжение отслеживания проекта, возвращает длительность перерыва
+func (h *Handlers) Resume(projectName string) (time.Duration, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	pause, err := h.TrackingService.ResumeTracking(h.Projects, projectName)
+	if err != nil {
+		h.Logger.Errorf("Ошибка продолжения отслеживания: %v", err)
+		return 0, err
+	}
+
+	project := h.Projects[projectName]
+	h.Logger.Infof("Отслеживание продолжено для проекта %s. Перерыв: %v", projectName, pause)
+	h.SystrayHandler.SetPaused(projectName, nil, domain.BreaksDuration(project.Breaks))
+	h.startPomodoro(projectName)
+
+	return pause, nil
+}
+
+// Status - запущенные отслеживания по алфавиту
+func (h *Handlers) Status() ([]daemon.Session, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	long := make(map[string]bool)
+	for _, name := range h.TrackingService.LongSessions(h.Projects, now, h.Config.MaxSession) {
+		long[name] = true
+	}
+
+	sessions := []daemon.Session{}
+	for _, name := range h.TrackingService.ActiveProjects(h.Projects) {
+		project := h.Projects[name]
+
+		session := daemon.Session{
+			Project:  name,
+			Start:    *project.StartTime,
+			PausedAt: project.PausedAt,
+			Breaks:   domain.BreaksDuration(project.Breaks),
+			Elapsed:  project.Elapsed(now),
+			Long:     long[name],
+		}
+		if sprint, exists := project.Sprints[project.ActiveSprint]; exists {
+			session.Sprint = sprint.Name
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// List - проекты по алфавиту, all - вместе с архивными
+func (h *Handlers) List(all bool) ([]daemon.ProjectInfo, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	projects := []daemon.ProjectInfo{}
+	for _, name := range h.ProjectService.GetProjectNames(h.Projects, all) {
+		project := h.Projects[name]
+		projects = append(projects, daemon.ProjectInfo{
+			Name:     name,
+			Archived: project.Archived,
+			Running:  project.StartTime != nil,
+			Paused:   project.PausedAt != nil,
+		})
+	}
+
+	return projects, nil
+}
+
+// Report - отчет за период
+func (h *Handlers) Report(filter service.ReportFilter) (service.Report, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	report, err := h.ReportService.Report(h.Projects, filter)
+	if err != nil {
+		h.Logger.Errorf("Ошибка построения отчета: %v", err)
+	}
+
+	return report, err
+}
+
+// Export - записи за период для выгрузки
+func (h *Handlers) Export(filter service.ReportFilter) ([]service.ExportRow, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rows, err := h.ReportService.ExportEntries(h.Projects, filter)
+	if err != nil {
+		h.Logger.Errorf("Ошибка выгрузки записей: %v", err)
+	}
+
+	return rows, err
+}
+
+// Cap - остановка отслеживания с записью не длиннее -max-session
+func (h *Handlers) Cap(projectName, description string) (time.Duration, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	elapsed, err := h.TrackingService.CapTracking(h.Projects, projectName, description, h.Config.MaxSession)
+	if err != nil {
+		h.Logger.Errorf("Ошибка остановки сессии: %v", err)
+		return 0, err
+	}
+
+	h.Logger.Infof("Отслеживание остановлено для проекта %s. Время: %v", projectName, elapsed)
+	h.stopPomodoro(projectName)
+	h.SystrayHandler.SetTracking(projectName, nil)
+
+	return elapsed, nil
+}
+
+// Discard - отмена сессии без сохранения записи
+func (h *Handlers) Discard(projectName string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.TrackingService.DiscardTracking(h.Projects, projectName); err != nil {
+		h.Logger.Errorf("Ошибка отмены сессии: %v", err)
+		return err
+	}
+
+	h.stopPomodoro(projectName)
+	h.SystrayHandler.SetTracking(projectName, nil)
+
+	return nil
+}
+
+// ExcludeSpan - исключение периода из сессии с обновлением трея
+func (h *Handlers) ExcludeSpan(projectName string, start, end time.Time) (time.Duration, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	excluded, err := h.TrackingService.ExcludeSpan(h.Projects, projectName, start, end)
+	if err != nil {
+		h.Logger.Errorf("Ошибка исключения периода из сессии: %v", err)
+		return 0, err
+	}
+
+	project := h.Projects[projectName]
+	h.Logger.Infof("Из сессии проекта %s исключено %v", projectName, excluded)
+	h.SystrayHandler.SetPaused(projectName, project.PausedAt, domain.BreaksDuration(project.Breaks))
+
+	return excluded, nil
+}
+
+// AddProject - создание проекта
+func (h *Handlers) AddProject(name string) error {
+	err := h.WithProjects(func(projects map[string]*domain.Project) error {
+		return h.ProjectService.CreateProject(projects, name)
+	})
+	if err != nil {
+		h.Logger.Errorf("Ошибка создания проекта: %v", err)
+		return err
+	}
+
+	h.UpdateTrayProjects()
+	return nil
+}
+
+// Archive - перенос проекта в архив или возврат из него
+func (h *Handlers) Archive(projectName string, archived bool) error {
+	err := h.WithProjects(func(projects map[string]*domain.Project) error {
+		if archived {
+			return h.ProjectService.ArchiveProject(projects, projectName)
+		}
+		return h.ProjectService.RestoreProject(projects, projectName)
+	})
+	if err != nil {
+		h.Logger.Errorf("Ошибка изменения архива проектов: %v", err)
+		return err
+	}
+
+	h.UpdateTrayProjects()
+	return nil
+}
+
+// Sprints - спринты проекта с затраченным временем
+func (h *Handlers) Sprints(projectName string) ([]daemon.SprintInfo, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sprints, err := h.ProjectService.GetProjectSprints(h.Projects, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := h.TrackingService.Summary(h.Projects)[projectName]
+
+	result := []daemon.SprintInfo{}
+	for _, sprint := range sprints {
+		result = append(result, daemon.SprintInfo{
+			ID:          sprint.ID,
+			Name:        sprint.Name,
+			Description: sprint.Description,
+			StartDate:   sprint.StartDate,
+			Active:      sprint.IsActive,
+			Spent:       time.Duration(summary.Sprints[sprint.ID]) * time.Second,
+		})
+	}
+
+	return result, nil
+}
+
+// CreateSprint - создание спринта, он становится активным
+func (h *Handlers) CreateSprint(projectName, sprintName, description string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.ProjectService.CreateSprint(h.Projects, projectName, sprintName, description); err != nil {
+		h.Logger.Errorf("Ошибка создания спринта: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// SetActiveSprint - выбор активного спринта проекта по имени
+func (h *Handlers) SetActiveSprint(projectName, sprintName string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sprint, err := h.ProjectService.FindSprintByName(h.Projects, projectName, sprintName)
+	if err != nil {
+		return err
+	}
+
+	if err := h.ProjectService.SetActiveSprint(h.Projects, projectName, sprint.ID); err != nil {
+		h.Logger.Errorf("Ошибка установки активного спринта: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Entries - записи времени проекта с именами спринтов
+func (h *Handlers) Entries(projectName string) ([]daemon.Entry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries, err := h.EntryService.GetEntries(h.Projects, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	project := h.Projects[projectName]
+
+	result := []daemon.Entry{}
+	for _, entry := range entries {
+		item := daemon.Entry{TimeEntry: entry}
+		if sprint, exists := project.Sprints[entry.SprintID]; exists {
+			item.Sprint = sprint.Name
+		}
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+// FindEntry - запись по ID или его началу
+func (h *Handlers) FindEntry(projectName, id string) (domain.TimeEntry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.EntryService.FindEntry(h.Projects, projectName, id)
+}
+
+// AddEntry - добавление записи вручную; sprintName - имя спринта (пусто - активный)
+func (h *Handlers) AddEntry(projectName string, start, end time.Time, description, sprintName string, noSprint bool) (domain.TimeEntry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var sprintID string
+	if sprintName != "" {
+		sprint, err := h.ProjectService.FindSprintByName(h.Projects, projectName, sprintName)
+		if err != nil {
+			return domain.TimeEntry{}, err
+		}
+		sprintID = sprint.ID
+	}
+
+	entry, err := h.EntryService.AddEntry(h.Projects, projectName, start, end, description, sprintID, noSprint)
+	if err != nil {
+		h.Logger.Errorf("Ошибка добавления записи: %v", err)
+	}
+
+	return entry, err
+}
+
+// EditEntry - изменение записи, спринт задается именем
+func (h *Handlers) EditEntry(projectName, id string, edit daemon.EntryEdit) (domain.TimeEntry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	change := service.EntryEdit{
+		Description: edit.Description,
+		Start:       edit.Start,
+		Duration:    edit.Duration,
+	}
+
+	if edit.Sprint != nil {
+		sprintID := ""
+		if *edit.Sprint != "" {
+			sprint, err := h.ProjectService.FindSprintByName(h.Projects, projectName, *edit.Sprint)
+			if err != nil {
+				return domain.TimeEntry{}, err
+			}
+			sprintID = sprint.ID
+		}
+		change.SprintID = &sprintID
+	}
+
+	entry, err := h.EntryService.EditEntry(h.Projects, projectName, id, change)
+	if err != nil {
+		h.Logger.Errorf("Ошибка изменения записи: %v", err)
+	}
+
+	return entry, err
+}
+
+// DeleteEntry - удаление записи
+func (h *Handlers) DeleteEntry(projectName, id string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	err := h.EntryService.DeleteEntry(h.Projects, projectName, id)
+	if err != nil {
+		h.Logger.Errorf("Ошибка удаления записи: %v", err)
+	}
+
+	return err
+}
+
+// SplitEntry - разделение записи на две в момент at
+func (h *Handlers) SplitEntry(projectName, id string, at time.Time) (domain.TimeEntry, domain.TimeEntry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	first, second, err := h.EntryService.SplitEntry(h.Projects, projectName, id, at)
+	if err != nil {
+		h.Logger.Errorf("Ошибка разделения записи: %v", err)
+	}
+
+	return first, second, err
+}
+
+// MergeEntries - объединение двух соседних записей
+func (h *Handlers) MergeEntries(projectName, firstID, secondID string) (domain.TimeEntry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	merged, err := h.EntryService.MergeEntries(h.Projects, projectName, firstID, secondID)
+	if err != nil {
+		h.Logger.Errorf("Ошибка объединения записей: %v", err)
+	}
+
+	return merged, err
+}
+
+// Import - добавление импортированных записей, dryRun - только проверка
+func (h *Handlers) Import(records []importer.Record, defaultProject string, dryRun bool) (service.ImportResult, error) {
+	var result service.ImportResult
+	err := h.WithProjects(func(projects map[string]*domain.Project) error {
+		var err error
+		result, err = h.EntryService.Import(projects, records, defaultProject, dryRun)
+		return err
+	})
+	if err != nil {
+		h.Logger.Errorf("Ошибка импорта: %v", err)
+		return result, err
+	}
+
+	if len(result.Projects) > 0 && !dryRun {
+		h.UpdateTrayProjects()
+	}
+
+	return result, nil
+}
+
+// RestoreBackup - восстановление данных из резервной копии с номером n.
+// Запущенные отслеживания заменяются состоянием из копии.
+func (h *Handlers) RestoreBackup(n int) error {
+	backupStorage, ok := h.ProjectService.Storage.(storage.BackupStorage)
+	if !ok {
+		return fmt.Errorf("хранилище не поддерживает резервные копии")
+	}
+
+	h.mu.Lock()
+
+	if err := backupStorage.RestoreBackup(n); err != nil {
+		h.mu.Unlock()
+		h.Logger.Errorf("Ошибка восстановления резервной копии: %v", err)
+		return err
+	}
+
+	projects, err := h.ProjectService.LoadData()
+	if err != nil {
+		h.mu.Unlock()
+		h.Logger.Errorf("Ошибка загрузки восстановленных данных: %v", err)
+		return err
+	}
+
+	for _, projectName := range h.TrackingService.ActiveProjects(h.Projects) {
+		h.stopPomodoro(projectName)
+		h.SystrayHandler.SetTracking(projectName, nil)
+	}
+
+	h.Projects = projects
+	h.TrackingService.SyncReminders(h.Projects)
+
+	for _, projectName := range h.TrackingService.ActiveProjects(h.Projects) {
+		project := h.Projects[projectName]
+		h.SystrayHandler.SetTracking(projectName, project.StartTime)
+		h.SystrayHandler.SetPaused(projectName, project.PausedAt, domain.BreaksDuration(project.Breaks))
+		if project.PausedAt == nil {
+			h.startPomodoro(projectName)
+		}
+	}
+
+	h.mu.Unlock()
+
+	h.Logger.Infof("Данные восстановлены из резервной копии №%d", n)
+	h.UpdateTrayProjects()
+
+	return nil
+}
@@ -0,0 +1,1029 @@
+package handlers
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
+	"github.com/MWT-proger/time-tracking/internal/importer"
+	"github.com/MWT-proger/time-tracking/internal/service"
+	"github.com/MWT-proger/time-tracking/internal/storage"
+)
+
+// Коды завершения неинтерактивных команд
+const (
+	ExitOK         = 0 // Команда выполнена успешно
+	ExitError      = 1 // Ошибка выполнения команды
+	ExitUsage      = 2 // Неверные аргументы команды
+	ExitNotRunning = 3 // Нет запущенных отслеживаний
+)
+
+// RunCommand - выполнение неинтерактивной команды, возвращает код завершения
+func (h *Handlers) RunCommand(args []string) int {
+	if len(args) == 0 {
+		h.printCommandUsage(os.Stderr)
+		return ExitUsage
+	}
+
+	h.Logger.Debugf("Выполнение команды: %v", args)
+
+	switch args[0] {
+	case "start":
+		return h.cmdStart(args[1:])
+	case "stop":
+		return h.cmdStop(args[1:])
+	case "pause":
+		return h.cmdPause(args[1:])
+	case "resume":
+		return h.cmdResume(args[1:])
+	case "status":
+		return h.cmdStatus(args[1:])
+	case "projects":
+		return h.cmdProjects(args[1:])
+	case "sprints":
+		return h.cmdSprints(args[1:])
+	case "add":
+		return h.cmdAdd(args[1:])
+	case "entries":
+		return h.cmdEntries(args[1:])
+	case "entry":
+		return h.cmdEntry(args[1:])
+	case "report":
+		return h.cmdReport(args[1:])
+	case "export":
+		return h.cmdExport(args[1:])
+	case "import":
+		return h.cmdImport(args[1:])
+	case "backup":
+		return h.cmdBackup(args[1:])
+	case "recover":
+		return h.cmdRecover(args[1:])
+	case "help":
+		h.printCommandUsage(os.Stdout)
+		return ExitOK
+	default:
+		fmt.Fprintf(os.Stderr, "Неизвестная команда: %s\n\n", args[0])
+		h.printCommandUsage(os.Stderr)
+		return ExitUsage
+	}
+}
+
+// printCommandUsage - вывод списка доступных команд
+func (h *Handlers) printCommandUsage(w io.Writer) {
+	fmt.Fprintln(w, "Команды:")
+	fmt.Fprintln(w, "  daemon                          Работать в фоне, принимая команды через -socket")
+	fmt.Fprintln(w, "  web [--addr адрес]              Работать в фоне с веб-интерфейсом в браузере")
+	fmt.Fprintln(w, "  start <проект> [--sprint имя]   Начать отслеживание (-m описание остановленной сессии)")
+	fmt.Fprintln(w, "  stop [проект] [-m описание]     Остановить отслеживание (--cap, --discard)")
+	fmt.Fprintln(w, "  pause [проект]                  Приостановить отслеживание")
+	fmt.Fprintln(w, "  resume [проект]                 Продолжить приостановленное отслеживание")
+	fmt.Fprintln(w, "  status                          Показать активные отслеживания")
+	fmt.Fprintln(w, "  projects [--all]                Список проектов")
+	fmt.Fprintln(w, "  sprints <проект>                Список спринтов проекта")
+	fmt.Fprintln(w, "  add <проект> --from --to        Добавить запись вручную ([--date вчера] [-m описание])")
+	fmt.Fprintln(w, "  entries <проект>                Список записей времени проекта")
+	fmt.Fprintln(w, "  entry edit <проект> <ID>        Изменить запись (-m, --start, --duration, --sprint, --no-sprint)")
+	fmt.Fprintln(w, "  entry delete <проект> <ID>      Удалить запись")
+	fmt.Fprintln(w, "  entry split <проект> <ID> --at  Разделить запись на две")
+	fmt.Fprintln(w, "  entry merge <проект> <ID> <ID>  Объединить соседние записи")
+	fmt.Fprintln(w, "  report [--from] [--to]          Отчет за период (--period, --group-by, --project)")
+	fmt.Fprintln(w, "  export [--format csv|json|md]   Выгрузка записей за период (html, --from, --to, --project, -o)")
+	fmt.Fprintln(w, "  import <формат> <файл>...       Импорт из toggl, clockify, watson, timewarrior, csv (--dry-run)")
+	fmt.Fprintln(w, "  backup list                     Список резервных копий данных")
+	fmt.Fprintln(w, "  backup restore <номер>          Восстановить данные из резервной копии")
+	fmt.Fprintln(w, "  recover [--partial]             Восстановить поврежденный файл данных")
+	fmt.Fprintln(w, "  help                            Список команд")
+}
+
+// newCommandFlags - создание набора флагов для команды
+func newCommandFlags(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Использование: %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseCommandFlags - разбор флагов команды, которые могут идти после позиционных аргументов
+func parseCommandFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// cmdStart - команда начала отслеживания
+func (h *Handlers) cmdStart(args []string) int {
+	fs := newCommandFlags("start", "start <проект> [--sprint имя] [-m описание]")
+	sprintName := fs.String("sprint", "", "Спринт, в который будет записано время")
+	description := fs.String("m", "", "Что сделано в проекте, отслеживание которого останавливается в режиме -timers single")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+	projectName := positional[0]
+
+	// Спринт и остановку других проектов в режиме одного таймера
+	// выполняет демон или сами обработчики
+	stopped, err := h.backend().Start(projectName, *sprintName, *description)
+	for _, running := range stopped {
+		fmt.Printf("Отслеживание остановлено для проекта %s\n", running)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	fmt.Println("Начато отслеживание для проекта:", projectName)
+	return ExitOK
+}
+
+// cmdStop - команда остановки отслеживания
+func (h *Handlers) cmdStop(args []string) int {
+	fs := newCommandFlags("stop", "stop [проект] [-m описание] [--cap | --discard]")
+	description := fs.String("m", "", "Что сделано")
+	capSession := fs.Bool("cap", false, fmt.Sprintf("Ограничить запись длительностью -max-session (%v)", h.Config.MaxSession))
+	discard := fs.Bool("discard", false, "Отменить сессию без сохранения записи")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) > 1 || (*capSession && *discard) {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	var projectName string
+	if len(positional) == 1 {
+		projectName = positional[0]
+	} else {
+		// Без имени проекта останавливаем единственное активное отслеживание
+		sessions, err := h.backend().Status()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return ExitError
+		}
+
+		var active []string
+		for _, session := range sessions {
+			active = append(active, session.Project)
+		}
+
+		switch len(active) {
+		case 0:
+			fmt.Fprintln(os.Stderr, "Нет активных отслеживаний")
+			return ExitNotRunning
+		case 1:
+			projectName = active[0]
+		default:
+			fmt.Fprintf(os.Stderr, "Запущено несколько отслеживаний, укажите проект: %v\n", active)
+			return ExitUsage
+		}
+	}
+
+	if *discard {
+		if err := h.backend().Discard(projectName); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return ExitError
+		}
+		fmt.Printf("Сессия проекта %s отменена\n", projectName)
+		return ExitOK
+	}
+
+	var elapsed time.Duration
+	if *capSession {
+		elapsed, err = h.backend().Cap(projectName, *description)
+	} else {
+		elapsed, err = h.backend().Stop(projectName, *description)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	fmt.Printf("Отслеживание остановлено для проекта %s. Время: %s\n", projectName, h.FormatDuration(elapsed))
+	return ExitOK
+}
+
+// cmdPause - команда приостановки отслеживания
+func (h *Handlers) cmdPause(args []string) int {
+	fs := newCommandFlags("pause", "pause [проект]")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) > 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	var projectName string
+	if len(positional) == 1 {
+		projectName = positional[0]
+	} else {
+		// Без имени проекта приостанавливаем единственное идущее отслеживание
+		sessions, err := h.backend().Status()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return ExitError
+		}
+
+		var running []string
+		for _, session := range sessions {
+			if session.PausedAt == nil {
+				running = append(running, session.Project)
+			}
+		}
+
+		switch len(running) {
+		case 0:
+			fmt.Fprintln(os.Stderr, "Нет идущих отслеживаний")
+			return ExitNotRunning
+		case 1:
+			projectName = running[0]
+		default:
+			fmt.Fprintf(os.Stderr, "Запущено несколько отслеживаний, укажите проект: %v\n", running)
+			return ExitUsage
+		}
+	}
+
+	if err := h.backend().Pause(projectName); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	// Отработанное время берется у владельца данных уже после паузы
+	sessions, err := h.backend().Status()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+	for _, session := range sessions {
+		if session.Project == projectName {
+			fmt.Printf("Отслеживание приостановлено для проекта %s. Отработано: %s\n", projectName, h.FormatDuration(session.Elapsed))
+			return ExitOK
+		}
+	}
+
+	fmt.Printf("Отслеживание приостановлено для проекта %s\n", projectName)
+	return ExitOK
+}
+
+// cmdResume - команда продолжения приостановленного отслеживания
+func (h *Handlers) cmdResume(args []string) int {
+	fs := newCommandFlags("resume", "resume [проект]")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) > 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	var projectName string
+	if len(positional) == 1 {
+		projectName = positional[0]
+	} else {
+		sessions, err := h.backend().Status()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return ExitError
+		}
+
+		var paused []string
+		for _, session := range sessions {
+			if session.PausedAt != nil {
+				paused = append(paused, session.Project)
+			}
+		}
+
+		switch len(paused) {
+		case 0:
+			fmt.Fprintln(os.Stderr, "Нет приостановленных отслеживаний")
+			return ExitNotRunning
+		case 1:
+			projectName = paused[0]
+		default:
+			fmt.Fprintf(os.Stderr, "Приостановлено несколько отслеживаний, укажите проект: %v\n", paused)
+			return ExitUsage
+		}
+	}
+
+	pause, err := h.backend().Resume(projectName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	fmt.Printf("Отслеживание продолжено для проекта %s. Перерыв: %s\n", projectName, h.FormatDuration(pause))
+	return ExitOK
+}
+
+// cmdStatus - команда вывода активных отслеживаний
+func (h *Handlers) cmdStatus(args []string) int {
+	fs := newCommandFlags("status", "status")
+	if _, err := parseCommandFlags(fs, args); err != nil {
+		return ExitUsage
+	}
+
+	sessions, err := h.backend().Status()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+	if len(sessions) == 0 {
+		fmt.Println("Нет активных отслеживаний")
+		return ExitNotRunning
+	}
+
+	h.printSessions(sessions)
+	return ExitOK
+}
+
+// printSessions - вывод запущенных отслеживаний
+func (h *Handlers) printSessions(sessions []daemon.Session) {
+	for _, session := range sessions {
+		sprintLabel := ""
+		if session.Sprint != "" {
+			sprintLabel = fmt.Sprintf(" [%s]", session.Sprint)
+		}
+
+		if session.PausedAt != nil {
+			fmt.Printf("⏸ %s%s: %s (с %s, пауза с %s)\n", session.Project, sprintLabel,
+				h.FormatDuration(session.Elapsed),
+				session.Start.Local().Format("2006-01-02 15:04:05"),
+				session.PausedAt.Local().Format("15:04:05"))
+			continue
+		}
+
+		fmt.Printf("▶ %s%s: %s (с %s)\n", session.Project, sprintLabel,
+			h.FormatDuration(session.Elapsed),
+			session.Start.Local().Format("2006-01-02 15:04:05"))
+
+		if session.Long {
+			fmt.Fprintf(os.Stderr, "  Сессия идет дольше %s, возможно, отслеживание забыли остановить (stop --cap или stop --discard)\n",
+				h.FormatDuration(h.Config.MaxSession))
+		}
+	}
+}
+
+// cmdProjects - команда вывода списка проектов
+func (h *Handlers) cmdProjects(args []string) int {
+	fs := newCommandFlags("projects", "projects [--all]")
+	all := fs.Bool("all", false, "Включить архивные проекты")
+
+	if _, err := parseCommandFlags(fs, args); err != nil {
+		return ExitUsage
+	}
+
+	projects, err := h.backend().List(*all)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	for _, project := range projects {
+		switch {
+		case project.Archived:
+			fmt.Printf("📦 %s\n", project.Name)
+		case project.Paused:
+			fmt.Printf("⏸ %s\n", project.Name)
+		case project.Running:
+			fmt.Printf("▶ %s\n", project.Name)
+		default:
+			fmt.Printf("⏹ %s\n", project.Name)
+		}
+	}
+
+	return ExitOK
+}
+
+// cmdSprints - команда вывода спринтов проекта
+func (h *Handlers) cmdSprints(args []string) int {
+	fs := newCommandFlags("sprints", "sprints <проект>")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	sprints, err := h.backend().Sprints(positional[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	for _, sprint := range sprints {
+		prefix := "  "
+		if sprint.Active {
+			prefix = "▶ "
+		}
+
+		fmt.Printf("%s%s: %s\n", prefix, sprint.Name, h.FormatTimeSpent(int(sprint.Spent.Seconds())))
+	}
+
+	return ExitOK
+}
+
+// cmdBackup - команды работы с резервными копиями данных
+func (h *Handlers) cmdBackup(args []string) int {
+	fs := newCommandFlags("backup", "backup list | backup restore <номер>")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	backupStorage, ok := h.ProjectService.Storage.(storage.BackupStorage)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Хранилище не поддерживает резервные копии")
+		return ExitError
+	}
+
+	switch {
+	case positional[0] == "list" && len(positional) == 1:
+		backups, err := backupStorage.Backups()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return ExitError
+		}
+
+		if len(backups) == 0 {
+			fmt.Println("Резервных копий нет")
+			return ExitOK
+		}
+
+		for i, backup := range backups {
+			fmt.Printf("%3d  %s  %8d байт  %s\n", i+1, backup.Time.Format("2006-01-02 15:04:05"), backup.Size, backup.Path)
+		}
+		return ExitOK
+
+	case positional[0] == "restore" && len(positional) == 2:
+		n, err := strconv.Atoi(positional[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Неверный номер резервной копии: %s\n", positional[1])
+			return ExitUsage
+		}
+
+		if sessions, err := h.backend().Status(); err == nil && len(sessions) > 0 {
+			fmt.Fprintln(os.Stderr, "Внимание: запущенные отслеживания будут заменены состоянием из резервной копии")
+		}
+
+		// Запущенный демон восстанавливает данные сам и продолжает работать с ними
+		if err := h.backend().RestoreBackup(n); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return ExitError
+		}
+
+		fmt.Printf("Данные восстановлены из резервной копии №%d\n", n)
+		return ExitOK
+
+	default:
+		fs.Usage()
+		return ExitUsage
+	}
+}
+
+// cmdRecover - команда восстановления поврежденных данных
+func (h *Handlers) cmdRecover(args []string) int {
+	fs := newCommandFlags("recover", "recover [--partial]")
+	partial := fs.Bool("partial", false, "Если исправных резервных копий нет, восстановить уцелевшие проекты из поврежденного файла")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) > 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	report, err := h.ProjectService.RecoverData(*partial)
+	if errors.Is(err, storage.ErrNoValidBackup) {
+		fmt.Fprintln(os.Stderr, "Исправных резервных копий нет. Для частичного восстановления используйте флаг --partial")
+		return ExitError
+	}
+	if err != nil {
+		h.Logger.Errorf("Ошибка восстановления данных: %v", err)
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	h.PrintRecoveryReport(report)
+	return ExitOK
+}
+
+// cmdEntries - команда вывода записей времени проекта
+func (h *Handlers) cmdEntries(args []string) int {
+	fs := newCommandFlags("entries", "entries <проект>")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	entries, err := h.backend().Entries(positional[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	for _, entry := range entries {
+		line := h.FormatEntry(entry.TimeEntry)
+		if entry.Sprint != "" {
+			line += fmt.Sprintf(" [%s]", entry.Sprint)
+		}
+		fmt.Println(line)
+	}
+
+	return ExitOK
+}
+
+// cmdEntry - команды изменения записей времени
+func (h *Handlers) cmdEntry(args []string) int {
+	usage := "entry edit|delete|split|merge <проект> <ID записи> ..."
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Использование: %s\n", usage)
+		return ExitUsage
+	}
+
+	switch args[0] {
+	case "edit":
+		return h.cmdEntryEdit(args[1:])
+	case "delete":
+		return h.cmdEntryDelete(args[1:])
+	case "split":
+		return h.cmdEntrySplit(args[1:])
+	case "merge":
+		return h.cmdEntryMerge(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Использование: %s\n", usage)
+		return ExitUsage
+	}
+}
+
+// cmdEntryEdit - изменение описания, начала, длительности или спринта записи
+func (h *Handlers) cmdEntryEdit(args []string) int {
+	fs := newCommandFlags("entry edit", "entry edit <проект> <ID записи> [-m описание] [--start время] [--duration длительность] [--sprint имя | --no-sprint]")
+	description := fs.String("m", "", "Новое описание")
+	startValue := fs.String("start", "", "Новое время начала (ГГГГ-ММ-ДД ЧЧ:ММ или ЧЧ:ММ), длительность сохраняется")
+	durationValue := fs.String("duration", "", "Новая длительность (например, 1h30m или 90)")
+	sprintName := fs.String("sprint", "", "Спринт записи")
+	noSprint := fs.Bool("no-sprint", false, "Убрать запись из спринта")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) != 2 || (*sprintName != "" && *noSprint) {
+		fs.Usage()
+		return ExitUsage
+	}
+	projectName, entryID := positional[0], positional[1]
+
+	entry, err := h.backend().FindEntry(projectName, entryID)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	var edit daemon.EntryEdit
+
+	// Пустое описание допустимо, поэтому проверяем, был ли указан флаг
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "m" {
+			edit.Description = description
+		}
+	})
+
+	if *startValue != "" {
+		start, err := ParseUserTime(*startValue, entry.Start)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return ExitUsage
+		}
+		edit.Start = &start
+	}
+
+	if *durationValue != "" {
+		duration, err := ParseUserDuration(*durationValue)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return ExitUsage
+		}
+		edit.Duration = &duration
+	}
+
+	if *sprintName != "" || *noSprint {
+		edit.Sprint = sprintName
+	}
+
+	entry, err = h.backend().EditEntry(projectName, entry.ID, edit)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	fmt.Printf("Запись изменена: %s\n", h.FormatEntry(entry))
+	return ExitOK
+}
+
+// cmdEntryDelete - удаление записи
+func (h *Handlers) cmdEntryDelete(args []string) int {
+	fs := newCommandFlags("entry delete", "entry delete <проект> <ID записи>")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) != 2 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	if err := h.backend().DeleteEntry(positional[0], positional[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	fmt.Println("Запись удалена")
+	return ExitOK
+}
+
+// cmdEntrySplit - разделение записи на две
+func (h *Handlers) cmdEntrySplit(args []string) int {
+	fs := newCommandFlags("entry split", "entry split <проект> <ID записи> --at время")
+	atValue := fs.String("at", "", "Момент разделения (ГГГГ-ММ-ДД ЧЧ:ММ или ЧЧ:ММ)")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) != 2 || *atValue == "" {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	entry, err := h.backend().FindEntry(positional[0], positional[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	at, err := ParseUserTime(*atValue, entry.Start)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitUsage
+	}
+
+	first, second, err := h.backend().SplitEntry(positional[0], entry.ID, at)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	fmt.Println(h.FormatEntry(first))
+	fmt.Println(h.FormatEntry(second))
+	return ExitOK
+}
+
+// cmdEntryMerge - объединение двух соседних записей
+func (h *Handlers) cmdEntryMerge(args []string) int {
+	fs := newCommandFlags("entry merge", "entry merge <проект> <ID записи> <ID записи>")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) != 3 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	merged, err := h.backend().MergeEntries(positional[0], positional[1], positional[2])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	fmt.Printf("Записи объединены: %s\n", h.FormatEntry(merged))
+	return ExitOK
+}
+
+// cmdReport - команда отчета за период
+func (h *Handlers) cmdReport(args []string) int {
+	fs := newCommandFlags("report", "report [--period today|week|month|30d] [--from дата] [--to дата] [--group-by day|week|month|project|sprint] [--project имя]")
+	period := fs.String("period", "week", "Период: today, week (с понедельника), month или 30d")
+	fromValue := fs.String("from", "", "Начало периода (ГГГГ-ММ-ДД, вчера или -N), заменяет --period")
+	toValue := fs.String("to", "", "Окончание периода включительно, только вместе с --from (по умолчанию сегодня)")
+	groupValue := fs.String("group-by", "day", "Группировка: day, week, month, project, sprint")
+	projectName := fs.String("project", "", "Только указанный проект")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	from, to, err := parseCommandPeriod(*period, *fromValue, *toValue)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitUsage
+	}
+
+	group, err := service.ParseGroupBy(*groupValue)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitUsage
+	}
+
+	report, err := h.backend().Report(service.ReportFilter{
+		From:    from,
+		To:      to,
+		Project: *projectName,
+		GroupBy: group,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	h.PrintReport(os.Stdout, report)
+	return ExitOK
+}
+
+// parseCommandPeriod - период команды: --from и --to заменяют --period.
+// Окончание без начала - ошибка, окончание по умолчанию - сегодня.
+func parseCommandPeriod(period, fromValue, toValue string) (time.Time, time.Time, error) {
+	switch period {
+	case "today", "week", "month", "30d":
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("неизвестный период '%s' (допустимо: today, week, month, 30d)", period)
+	}
+
+	now := time.Now()
+	from, to := ReportPeriod(period, now)
+	if fromValue == "" {
+		if toValue != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("--to задается вместе с --from")
+		}
+		return from, to, nil
+	}
+	if toValue == "" {
+		toValue = "сегодня"
+	}
+
+	from, err := ParseUserDate(fromValue, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err = ParseUserDate(toValue, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return from, to, nil
+}
+
+// cmdExport - команда выгрузки записей за период в файл или на стандартный вывод
+func (h *Handlers) cmdExport(args []string) int {
+	fs := newCommandFlags("export", "export [--format csv|json|md|html] [--period today|week|month|30d] [--from дата] [--to дата] [--project имя] [-o файл]")
+	formatValue := fs.String("format", "csv", "Формат: csv, json, md или html")
+	period := fs.String("period", "week", "Период: today, week (с понедельника), month или 30d")
+	fromValue := fs.String("from", "", "Начало периода (ГГГГ-ММ-ДД, вчера или -N), заменяет --period")
+	toValue := fs.String("to", "", "Окончание периода включительно, только вместе с --from (по умолчанию сегодня)")
+	projectName := fs.String("project", "", "Только указанный проект")
+	output := fs.String("o", "", "Файл для выгрузки (по умолчанию стандартный вывод)")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	format, err := service.ParseExportFormat(*formatValue)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitUsage
+	}
+
+	from, to, err := parseCommandPeriod(*period, *fromValue, *toValue)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitUsage
+	}
+
+	rows, err := h.backend().Export(service.ReportFilter{
+		From:    from,
+		To:      to,
+		Project: *projectName,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	if *output == "" {
+		if err := service.WriteExport(os.Stdout, format, rows); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return ExitError
+		}
+		return ExitOK
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ошибка создания файла выгрузки: %v\n", err)
+		return ExitError
+	}
+	if err := service.WriteExport(file, format, rows); err != nil {
+		file.Close()
+		fmt.Fprintf(os.Stderr, "ошибка записи выгрузки: %v\n", err)
+		return ExitError
+	}
+	if err := file.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "ошибка записи выгрузки: %v\n", err)
+		return ExitError
+	}
+
+	h.Logger.Infof("Выгружено записей: %d в %s", len(rows), *output)
+	fmt.Printf("Выгружено записей: %d в %s\n", len(rows), *output)
+	return ExitOK
+}
+
+// cmdImport - команда импорта записей из других трекеров
+func (h *Handlers) cmdImport(args []string) int {
+	fs := newCommandFlags("import", "import toggl|clockify|watson|timewarrior|csv <файл>... [--dry-run] [--project имя] [--map поле=столбец,...]")
+	dryRun := fs.Bool("dry-run", false, "Показать результат без изменения данных")
+	defaultProject := fs.String("project", "", "Проект для записей без проекта")
+	mappingValue := fs.String("map", "", "Столбцы CSV для формата csv, например project=Клиент,start=Начало,end=Конец")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) < 2 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	format, err := importer.ParseFormat(positional[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitUsage
+	}
+	mapping, err := importer.ParseMapping(*mappingValue)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitUsage
+	}
+
+	var records []importer.Record
+	for _, path := range positional[1:] {
+		fileRecords, err := readImportFile(format, path, mapping)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			return ExitError
+		}
+		records = append(records, fileRecords...)
+	}
+
+	result, err := h.backend().Import(records, *defaultProject, *dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	h.PrintImportResult(result)
+	return ExitOK
+}
+
+// readImportFile - чтение записей из файла, "-" - стандартный ввод
+func readImportFile(format importer.Format, path string, mapping importer.Mapping) ([]importer.Record, error) {
+	if path == "-" {
+		return importer.Read(format, os.Stdin, mapping, time.Local)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return importer.Read(format, file, mapping, time.Local)
+}
+
+// PrintImportResult - вывод итога импорта
+func (h *Handlers) PrintImportResult(result service.ImportResult) {
+	if result.DryRun {
+		fmt.Println("Проверка без изменения данных (--dry-run)")
+	}
+	if len(result.Projects) > 0 {
+		fmt.Printf("Новые проекты: %s\n", strings.Join(result.Projects, ", "))
+	}
+	if len(result.Sprints) > 0 {
+		fmt.Printf("Новые спринты: %s\n", strings.Join(result.Sprints, ", "))
+	}
+
+	if result.DryRun {
+		fmt.Printf("Будет добавлено записей: %d\n", result.Added)
+	} else {
+		fmt.Printf("Добавлено записей: %d\n", result.Added)
+	}
+	fmt.Printf("Дубликаты: %d\n", result.Duplicates)
+
+	if len(result.Skipped) > 0 {
+		fmt.Printf("Пропущено: %d\n", len(result.Skipped))
+		for _, skip := range result.Skipped {
+			fmt.Printf("  %s %s: %s\n", skip.Record.Start.Local().Format("2006-01-02 15:04"), skip.Record.Project, skip.Reason)
+		}
+	}
+}
+
+// cmdAdd - команда ручного добавления записи о работе в прошлом
+func (h *Handlers) cmdAdd(args []string) int {
+	fs := newCommandFlags("add", "add <проект> --from время --to время [--date дата] [-m описание] [--sprint имя | --no-sprint]")
+	from := fs.String("from", "", "Время начала (ЧЧ:ММ, ГГГГ-ММ-ДД ЧЧ:ММ или вчера ЧЧ:ММ)")
+	to := fs.String("to", "", "Время окончания, если оно раньше начала - на следующий день")
+	dateValue := fs.String("date", "сегодня", "День записи (ГГГГ-ММ-ДД, сегодня, вчера, позавчера или -N)")
+	description := fs.String("m", "", "Что сделано")
+	sprintName := fs.String("sprint", "", "Спринт записи (по умолчанию активный)")
+	noSprint := fs.Bool("no-sprint", false, "Запись без спринта")
+
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) != 1 || *from == "" || *to == "" || (*sprintName != "" && *noSprint) {
+		fs.Usage()
+		return ExitUsage
+	}
+	projectName := positional[0]
+
+	date, err := ParseUserDate(*dateValue, time.Now())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitUsage
+	}
+
+	start, end, err := ParseUserPeriod(*from, *to, date)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitUsage
+	}
+
+	entry, err := h.backend().AddEntry(projectName, start, end, *description, *sprintName, *noSprint)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitError
+	}
+
+	fmt.Printf("Запись добавлена: %s\n", h.FormatEntry(entry))
+	return ExitOK
+}
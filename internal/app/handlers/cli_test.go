@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCommandPeriod(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	weekFrom, weekTo := ReportPeriod("week", now)
+
+	tests := []struct {
+		name             string
+		period, from, to string
+		wantFrom, wantTo time.Time
+		wantErr          bool
+	}{
+		{name: "период", period: "week", wantFrom: weekFrom, wantTo: weekTo},
+		{name: "начало без окончания", period: "week", from: "2024-03-04", wantFrom: time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local), wantTo: today},
+		{name: "начало и окончание", period: "week", from: "-2", to: "вчера", wantFrom: today.AddDate(0, 0, -2), wantTo: today.AddDate(0, 0, -1)},
+		{name: "окончание без начала", period: "week", to: "вчера", wantErr: true},
+		{name: "неизвестный период", period: "year", wantErr: true},
+		{name: "неверная дата", period: "week", from: "4 марта", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := parseCommandPeriod(tt.period, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+			if !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+				t.Errorf("период %s - %s, ожидался %s - %s", from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
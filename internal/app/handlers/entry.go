@@ -0,0 +1,475 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/manifoldco/promptui"
+)
+
+// userTimeFormats - форматы ввода даты и времени пользователем
+var userTimeFormats = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.RFC3339,
+}
+
+// relativeDays - относительные даты и смещение в днях от сегодняшнего дня
+var relativeDays = map[string]int{
+	"today":     0,
+	"сегодня":   0,
+	"yesterday": -1,
+	"вчера":     -1,
+	"позавчера": -2,
+}
+
+// ParseUserDate - разбор даты: ГГГГ-ММ-ДД, today/сегодня, yesterday/вчера,
+// позавчера или -N (N дней назад). Возвращает полночь дня в локальном поясе.
+func ParseUserDate(value string, now time.Time) (time.Time, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	now = now.In(time.Local)
+
+	offset, relative := relativeDays[value]
+	if !relative && strings.HasPrefix(value, "-") {
+		if days, err := strconv.Atoi(value); err == nil {
+			offset, relative = days, true
+		}
+	}
+	if relative {
+		return time.Date(now.Year(), now.Month(), now.Day()+offset, 0, 0, 0, 0, time.Local), nil
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("неверный формат даты '%s' (ожидается ГГГГ-ММ-ДД, сегодня, вчера или -N)", value)
+	}
+
+	return date, nil
+}
+
+// ParseUserTime - разбор введенного времени в локальном часовом поясе.
+// Время без даты ("15:04") относится к дню base, дата может быть
+// относительной ("вчера 15:04").
+func ParseUserTime(value string, base time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	for _, layout := range userTimeFormats {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	if fields := strings.Fields(value); len(fields) == 2 {
+		if date, err := ParseUserDate(fields[0], time.Now()); err == nil {
+			base, value = date, fields[1]
+		}
+	}
+
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			base = base.In(time.Local)
+			return time.Date(base.Year(), base.Month(), base.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.Local), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("неверный формат времени '%s' (ожидается ГГГГ-ММ-ДД ЧЧ:ММ, вчера ЧЧ:ММ или ЧЧ:ММ)", value)
+}
+
+// ParseUserPeriod - разбор начала и окончания периода в день date.
+// Если окончание раньше начала, период переходит через полночь.
+func ParseUserPeriod(from, to string, date time.Time) (time.Time, time.Time, error) {
+	start, err := ParseUserTime(from, date)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	end, err := ParseUserTime(to, start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if !end.After(start) && !strings.Contains(strings.TrimSpace(to), " ") {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return start, end, nil
+}
+
+// ParseUserDuration - разбор длительности: "1h30m" или количество минут
+func ParseUserDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+
+	if minutes, err := strconv.Atoi(value); err == nil {
+		return time.Duration(minutes) * time.Minute, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("неверный формат длительности '%s' (например, 1h30m или 90)", value)
+	}
+
+	return duration, nil
+}
+
+// FormatEntry - строка записи для списков
+func (h *Handlers) FormatEntry(entry domain.TimeEntry) string {
+	result := fmt.Sprintf("%s  %s  %s", h.FormatEntryPeriod(entry), h.FormatDuration(entry.Duration()), shortID(entry.ID))
+	if entry.Description != "" {
+		result += "  " + entry.Description
+	}
+
+	return result
+}
+
+// shortID - сокращенный ID записи для вывода
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+
+	return id
+}
+
+// AddEntryForProject - ручное добавление записи о работе в прошлом
+func (h *Handlers) AddEntryForProject(projectName string) {
+	datePrompt := promptui.Prompt{
+		Label:     "День (ГГГГ-ММ-ДД, сегодня, вчера или -N)",
+		Default:   "сегодня",
+		AllowEdit: true,
+		Validate: func(input string) error {
+			_, err := ParseUserDate(input, time.Now())
+			return err
+		},
+	}
+
+	dateValue, err := datePrompt.Run()
+	if err != nil {
+		return
+	}
+	date, _ := ParseUserDate(dateValue, time.Now())
+
+	validateTime := func(input string) error {
+		_, err := ParseUserTime(input, date)
+		return err
+	}
+
+	fromPrompt := promptui.Prompt{Label: "Начало (ЧЧ:ММ)", Validate: validateTime}
+	from, err := fromPrompt.Run()
+	if err != nil {
+		return
+	}
+
+	toPrompt := promptui.Prompt{Label: "Окончание (ЧЧ:ММ)", Validate: validateTime}
+	to, err := toPrompt.Run()
+	if err != nil {
+		return
+	}
+
+	descPrompt := promptui.Prompt{Label: "Что сделано"}
+	description, _ := descPrompt.Run()
+
+	start, end, err := ParseUserPeriod(from, to, date)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	entry, err := h.backend().AddEntry(projectName, start, end, description, "", false)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Запись добавлена: %s\n", h.FormatEntry(entry))
+}
+
+// ManageEntriesForProject - просмотр и изменение записей времени проекта
+func (h *Handlers) ManageEntriesForProject(projectName string) {
+	for {
+		entries, err := h.backend().Entries(projectName)
+		if err != nil {
+			fmt.Printf("Ошибка: %v\n", err)
+			return
+		}
+
+		if len(entries) == 0 {
+			fmt.Printf("У проекта '%s' нет записей\n", projectName)
+			return
+		}
+
+		options := []string{"← Назад"}
+		for _, entry := range entries {
+			options = append(options, h.FormatEntry(entry.TimeEntry))
+		}
+
+		prompt := promptui.Select{
+			Label: fmt.Sprintf("Записи проекта: %s", projectName),
+			Items: options,
+			Size:  15,
+		}
+
+		idx, _, err := prompt.Run()
+		if err != nil || idx == 0 {
+			return
+		}
+
+		h.ManageEntry(projectName, entries[idx-1].ID)
+	}
+}
+
+// ManageEntry - меню действий с одной записью
+func (h *Handlers) ManageEntry(projectName, entryID string) {
+	for {
+		entry, err := h.backend().FindEntry(projectName, entryID)
+		if err != nil {
+			fmt.Printf("Ошибка: %v\n", err)
+			return
+		}
+
+		prompt := promptui.Select{
+			Label: h.FormatEntry(entry),
+			Items: []string{
+				"Изменить описание",
+				"Изменить время начала",
+				"Изменить длительность",
+				"Изменить спринт",
+				"Разделить запись",
+				"Объединить со следующей записью",
+				"Удалить запись",
+				"Назад к записям",
+			},
+		}
+		_, cmd, err := prompt.Run()
+		if err != nil {
+			return
+		}
+
+		switch cmd {
+		case "Изменить описание":
+			h.editEntryDescription(projectName, entry)
+		case "Изменить время начала":
+			h.editEntryStart(projectName, entry)
+		case "Изменить длительность":
+			h.editEntryDuration(projectName, entry)
+		case "Изменить спринт":
+			h.editEntrySprint(projectName, entry)
+		case "Разделить запись":
+			h.splitEntry(projectName, entry)
+		case "Объединить со следующей записью":
+			h.mergeWithNextEntry(projectName, entry)
+		case "Удалить запись":
+			if h.deleteEntry(projectName, entry) {
+				return
+			}
+		case "Назад к записям":
+			return
+		}
+	}
+}
+
+// editEntryDescription - изменение описания записи
+func (h *Handlers) editEntryDescription(projectName string, entry domain.TimeEntry) {
+	prompt := promptui.Prompt{
+		Label:     "Описание",
+		Default:   entry.Description,
+		AllowEdit: true,
+	}
+
+	description, err := prompt.Run()
+	if err != nil {
+		return
+	}
+
+	h.applyEntryEdit(projectName, entry.ID, daemon.EntryEdit{Description: &description})
+}
+
+// editEntryStart - изменение времени начала с сохранением длительности
+func (h *Handlers) editEntryStart(projectName string, entry domain.TimeEntry) {
+	prompt := promptui.Prompt{
+		Label:     "Время начала (ГГГГ-ММ-ДД ЧЧ:ММ или ЧЧ:ММ)",
+		Default:   entry.Start.Local().Format("2006-01-02 15:04"),
+		AllowEdit: true,
+		Validate: func(input string) error {
+			_, err := ParseUserTime(input, entry.Start)
+			return err
+		},
+	}
+
+	value, err := prompt.Run()
+	if err != nil {
+		return
+	}
+
+	start, _ := ParseUserTime(value, entry.Start)
+	h.applyEntryEdit(projectName, entry.ID, daemon.EntryEdit{Start: &start})
+}
+
+// editEntryDuration - изменение длительности записи
+func (h *Handlers) editEntryDuration(projectName string, entry domain.TimeEntry) {
+	prompt := promptui.Prompt{
+		Label:     "Длительность (например, 1h30m или 90)",
+		Default:   entry.Duration().Round(time.Second).String(),
+		AllowEdit: true,
+		Validate: func(input string) error {
+			_, err := ParseUserDuration(input)
+			return err
+		},
+	}
+
+	value, err := prompt.Run()
+	if err != nil {
+		return
+	}
+
+	duration, _ := ParseUserDuration(value)
+	h.applyEntryEdit(projectName, entry.ID, daemon.EntryEdit{Duration: &duration})
+}
+
+// editEntrySprint - изменение спринта записи
+func (h *Handlers) editEntrySprint(projectName string, entry domain.TimeEntry) {
+	sprints, err := h.backend().Sprints(projectName)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	options := []string{"← Назад", "Без спринта"}
+	for _, sprint := range sprints {
+		prefix := "  "
+		if sprint.ID == entry.SprintID {
+			prefix = "● "
+		}
+		options = append(options, prefix+sprint.Name)
+	}
+
+	prompt := promptui.Select{
+		Label: "Выберите спринт",
+		Items: options,
+	}
+
+	idx, _, err := prompt.Run()
+	if err != nil || idx == 0 {
+		return
+	}
+
+	sprintName := ""
+	if idx > 1 {
+		sprintName = sprints[idx-2].Name
+	}
+
+	h.applyEntryEdit(projectName, entry.ID, daemon.EntryEdit{Sprint: &sprintName})
+}
+
+// applyEntryEdit - сохранение изменений записи с выводом результата
+func (h *Handlers) applyEntryEdit(projectName, entryID string, edit daemon.EntryEdit) {
+	entry, err := h.backend().EditEntry(projectName, entryID, edit)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Запись изменена: %s\n", h.FormatEntry(entry))
+}
+
+// splitEntry - разделение записи на две
+func (h *Handlers) splitEntry(projectName string, entry domain.TimeEntry) {
+	middle := entry.Start.Add(entry.Duration() / 2)
+
+	prompt := promptui.Prompt{
+		Label:     "Момент разделения (ГГГГ-ММ-ДД ЧЧ:ММ или ЧЧ:ММ)",
+		Default:   middle.Local().Format("2006-01-02 15:04"),
+		AllowEdit: true,
+		Validate: func(input string) error {
+			_, err := ParseUserTime(input, entry.Start)
+			return err
+		},
+	}
+
+	value, err := prompt.Run()
+	if err != nil {
+		return
+	}
+
+	at, _ := ParseUserTime(value, entry.Start)
+	first, second, err := h.backend().SplitEntry(projectName, entry.ID, at)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	fmt.Println("Запись разделена:")
+	fmt.Printf("  %s\n", h.FormatEntry(first))
+	fmt.Printf("  %s\n", h.FormatEntry(second))
+}
+
+// mergeWithNextEntry - объединение записи со следующей по времени
+func (h *Handlers) mergeWithNextEntry(projectName string, entry domain.TimeEntry) {
+	entries, err := h.backend().Entries(projectName)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	var next *domain.TimeEntry
+	for i := range entries {
+		if entries[i].ID == entry.ID && i+1 < len(entries) {
+			next = &entries[i+1].TimeEntry
+			break
+		}
+	}
+
+	if next == nil {
+		fmt.Println("Это последняя запись проекта")
+		return
+	}
+
+	fmt.Printf("Следующая запись: %s\n", h.FormatEntry(*next))
+	if gap := next.Start.Sub(entry.End); gap > 0 {
+		fmt.Printf("Промежуток между записями (%s) станет перерывом\n", h.FormatDuration(gap))
+	}
+
+	prompt := promptui.Select{
+		Label: "Объединить записи?",
+		Items: []string{"Да", "Нет"},
+	}
+
+	idx, _, err := prompt.Run()
+	if err != nil || idx != 0 {
+		fmt.Println("Объединение отменено")
+		return
+	}
+
+	merged, err := h.backend().MergeEntries(projectName, entry.ID, next.ID)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Записи объединены: %s\n", h.FormatEntry(merged))
+}
+
+// deleteEntry - удаление записи с подтверждением, возвращает true при удалении
+func (h *Handlers) deleteEntry(projectName string, entry domain.TimeEntry) bool {
+	prompt := promptui.Select{
+		Label: "Вы уверены, что хотите удалить запись?",
+		Items: []string{"Да", "Нет"},
+	}
+
+	idx, _, err := prompt.Run()
+	if err != nil || idx != 0 {
+		fmt.Println("Удаление отменено")
+		return false
+	}
+
+	if err := h.backend().DeleteEntry(projectName, entry.ID); err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return false
+	}
+
+	fmt.Println("Запись удалена")
+	return true
+}
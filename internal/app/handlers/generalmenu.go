@@ -1,15 +1,34 @@
 package handlers
 
-import "github.com/manifoldco/promptui"
+import (
+	"fmt"
 
+	"github.com/manifoldco/promptui"
+)
+
+// GeneralMenu - главное меню. При запущенном демоне данными владеет он,
+// и все изменения выполняются через него.
 func (h *Handlers) GeneralMenu() {
+	label := "Главное меню"
+	if h.Remote != nil {
+		label = "Главное меню (через демон)"
+	}
+
 	for {
+		// Файл данных при запущенном демоне изменяет только он
+		if h.Remote == nil {
+			h.ReloadIfChanged()
+		}
+		h.ResolveIdleSpans()
+		h.UpdateTrayProjects()
+
 		prompt := promptui.Select{
-			Label: "Главное меню",
+			Label: label,
 			Items: []string{
 				"Выбрать проект",
 				"Создать проект",
 				"Сводка по всем проектам",
+				"Отчет за период",
 				"Выход",
 			},
 		}
@@ -22,9 +41,45 @@ func (h *Handlers) GeneralMenu() {
 			h.CreateProject()
 		case "Сводка по всем проектам":
 			h.ShowSummary()
+		case "Отчет за период":
+			h.ShowReport()
 		case "Выход":
-			h.ProjectService.SaveData(h.Projects)
 			return
 		}
 	}
 }
+
+// ReloadIfChanged - перезагрузка данных, если их изменила другая программа
+func (h *Handlers) ReloadIfChanged() {
+	reloaded, err := h.ReloadChanged()
+	if err != nil {
+		fmt.Printf("Данные изменены другой программой, но не могут быть перезагружены: %v\n", err)
+		return
+	}
+
+	if reloaded {
+		fmt.Println("Данные изменены другой программой и перезагружены")
+	}
+}
+
+// ReloadChanged - перезагрузка данных без вывода в терминал;
+// true - данные были изменены другой программой и перезагружены
+func (h *Handlers) ReloadChanged() (bool, error) {
+	if !h.ProjectService.ChangedOnDisk() {
+		return false, nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	projects, err := h.ProjectService.LoadData()
+	if err != nil {
+		h.Logger.Errorf("Ошибка перезагрузки измененных данных: %v", err)
+		return false, err
+	}
+
+	h.Logger.Info("Данные изменены другой программой и перезагружены")
+	h.Projects = projects
+	h.TrackingService.SyncReminders(h.Projects)
+	return true, nil
+}
@@ -1,17 +1,26 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
 	"github.com/MWT-proger/time-tracking/internal/domain"
 	"github.com/MWT-proger/time-tracking/internal/service"
 	"github.com/MWT-proger/time-tracking/pkg/config"
+	"github.com/MWT-proger/time-tracking/pkg/dialog"
+	"github.com/MWT-proger/time-tracking/pkg/idle"
 	"github.com/MWT-proger/time-tracking/pkg/logger"
-	"github.com/manifoldco/promptui"
+	"github.com/MWT-proger/time-tracking/pkg/pomodoro"
 )
 
 type SystrayHandler interface {
 	SetTracking(project string, start *time.Time)
+	SetPaused(project string, pausedAt *time.Time, breaks time.Duration)
+	SetPomodoro(project string, phase pomodoro.Phase, end *time.Time)
+	SetProjects(projects []string)
 	StopTrayTicker()
 }
 
@@ -19,16 +28,42 @@ type SystrayHandler interface {
 type Handlers struct {
 	ProjectService  *service.ProjectService
 	TrackingService *service.TrackingService
+	EntryService    *service.EntryService
+	ReportService   *service.ReportService
 	SystrayHandler  SystrayHandler
 	Logger          logger.Logger
 	Config          *config.Config
 	Projects        map[string]*domain.Project
+
+	// Движок помидоров, nil если режим помидоров отключен
+	Pomodoro *pomodoro.Engine
+
+	// Диалог для ввода описания при остановке из трея,
+	// nil - остановка без описания
+	Dialog dialog.Prompter
+
+	// Клиент запущенного демона, nil - обработчики сами владеют данными.
+	// Через него работают трей, меню и команды отслеживания.
+	Remote daemon.Backend
+
+	// Сессии демона, показанные в трее
+	traySessions map[string]daemon.Session
+
+	// Защищает Projects от одновременного изменения из меню и из трея,
+	// таймеров помидоров и уведомлений. Не удерживается во время ввода в терминале.
+	mu sync.Mutex
+
+	// Периоды бездействия, ожидающие решения пользователя
+	idleMu    sync.Mutex
+	idleSpans []idle.Span
 }
 
 // NewHandlers - создание новых обработчиков
 func NewHandlers(
 	projectService *service.ProjectService,
 	trackingService *service.TrackingService,
+	entryService *service.EntryService,
+	reportService *service.ReportService,
 	systrayHandler SystrayHandler,
 	logger logger.Logger,
 	config *config.Config,
@@ -36,6 +71,8 @@ func NewHandlers(
 	return &Handlers{
 		ProjectService:  projectService,
 		TrackingService: trackingService,
+		EntryService:    entryService,
+		ReportService:   reportService,
 		SystrayHandler:  systrayHandler,
 		Logger:          logger,
 		Config:          config,
@@ -44,50 +81,195 @@ func NewHandlers(
 
 // SetProjects - установка проектов
 func (h *Handlers) SetProjects(projects map[string]*domain.Project) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	h.Projects = projects
 }
 
-// StartTracking - начало отслеживания времени через системный трей
-func (h *Handlers) StartTracking() {
-	// Выбор проекта
-	projectName := h.ChooseProject()
-	if projectName == "" {
+// UpdateTrayProjects - список неархивных проектов для запуска из трея
+func (h *Handlers) UpdateTrayProjects() {
+	projects, err := h.backend().List(false)
+	if err != nil {
+		h.Logger.Errorf("Ошибка получения списка проектов для трея: %v", err)
 		return
 	}
 
-	h.StartTrackingForProject(projectName)
+	names := make([]string, 0, len(projects))
+	for _, project := range projects {
+		names = append(names, project.Name)
+	}
+	h.SystrayHandler.SetProjects(names)
 }
 
-// StopTracking - остановка отслеживания времени через системный трей
+// StartProject - начало отслеживания проекта из трея с активным спринтом проекта.
+// В режиме одного таймера описание запущенных проектов запрашивается диалогом
+// перед их остановкой, отмена диалога оставляет их запущенными.
+func (h *Handlers) StartProject(projectName string) {
+	conflicting, err := h.conflictingSessions(projectName)
+	if err != nil {
+		h.Logger.Errorf("Ошибка получения запущенных отслеживаний: %v", err)
+		return
+	}
+
+	for _, running := range conflicting {
+		description, err := h.askDescription(running)
+		if errors.Is(err, dialog.ErrCanceled) {
+			h.Logger.Infof("Запуск проекта %s из трея отменен: проект %s остается запущенным", projectName, running)
+			return
+		}
+		if err != nil {
+			h.Logger.Warnf("Описание не запрошено: %v", err)
+		}
+
+		h.stopFromTray(running, description)
+	}
+
+	if _, err := h.backend().Start(projectName, "", ""); err != nil {
+		h.Logger.Errorf("Ошибка начала отслеживания из трея: %v", err)
+		return
+	}
+
+	h.Logger.Infof("Начато отслеживание из трея для проекта: %s", projectName)
+}
+
+// StopTracking - остановка всех запущенных отслеживаний через системный трей.
+// Описание запрашивается диалогом, отмена диалога оставляет проект запущенным.
 func (h *Handlers) StopTracking() {
-	// Создаем список активных проектов
-	var activeProjects []string
+	sessions, err := h.backend().Status()
+	if err != nil {
+		h.Logger.Errorf("Ошибка получения запущенных отслеживаний: %v", err)
+		return
+	}
+
+	for _, session := range sessions {
+		description, err := h.askDescription(session.Project)
+		if errors.Is(err, dialog.ErrCanceled) {
+			h.Logger.Infof("Остановка проекта %s из трея отменена", session.Project)
+			continue
+		}
+		if err != nil {
+			h.Logger.Warnf("Описание не запрошено: %v", err)
+		}
+
+		h.stopFromTray(session.Project, description)
+	}
+}
+
+// conflictingSessions - запущенные проекты, которые нужно остановить перед
+// запуском projectName. В режиме нескольких таймеров их нет.
+func (h *Handlers) conflictingSessions(projectName string) ([]string, error) {
+	if h.Config.TimerMode != config.TimerModeSingle {
+		return nil, nil
+	}
+
+	sessions, err := h.backend().Status()
+	if err != nil {
+		return nil, err
+	}
 
-	for name, project := range h.Projects {
-		if project.StartTime != nil {
-			activeProjects = append(activeProjects, name)
+	var names []string
+	for _, session := range sessions {
+		if session.Project != projectName {
+			names = append(names, session.Project)
 		}
 	}
 
-	if len(activeProjects) == 0 {
+	return names, nil
+}
+
+// askDescription - запрос описания остановленной сессии диалогом
+func (h *Handlers) askDescription(projectName string) (string, error) {
+	if h.Dialog == nil {
+		return "", nil
+	}
+
+	return h.Dialog.Entry("Остановить отслеживание", fmt.Sprintf("Что сделано в проекте %s?", projectName))
+}
+
+// StopProject - остановка отслеживания проекта пунктом его таймера в трее.
+// Описание не запрашивается, его можно добавить позже в записях времени.
+func (h *Handlers) StopProject(projectName string) {
+	h.stopFromTray(projectName, "")
+}
+
+// stopFromTray - остановка отслеживания без вывода в терминал
+func (h *Handlers) stopFromTray(projectName, description string) {
+	elapsed, err := h.backend().Stop(projectName, description)
+	if err != nil {
+		h.Logger.Errorf("Ошибка остановки отслеживания из трея: %v", err)
 		return
 	}
 
-	if len(activeProjects) == 1 {
-		h.StopTrackingForProject(activeProjects[0])
+	h.Logger.Infof("Отслеживание остановлено из трея для проекта %s. Время: %v", projectName, elapsed)
+}
+
+// PauseTracking - приостановка отслеживания через системный трей
+func (h *Handlers) PauseTracking() {
+	sessions, err := h.backend().Status()
+	if err != nil {
+		h.Logger.Errorf("Ошибка получения запущенных отслеживаний: %v", err)
 		return
 	}
 
-	// Если активных проектов несколько, предлагаем выбрать
-	prompt := promptui.Select{
-		Label: "Выберите проект для остановки",
-		Items: activeProjects,
+	// Пункт паузы в трее общий для всех таймеров, поэтому приостанавливаем единственную запущенную
+	var running []string
+	for _, session := range sessions {
+		if session.PausedAt == nil {
+			running = append(running, session.Project)
+		}
+	}
+	if len(running) != 1 {
+		return
 	}
 
-	_, projectName, err := prompt.Run()
+	if err := h.backend().Pause(running[0]); err != nil {
+		h.Logger.Errorf("Ошибка приостановки отслеживания из трея: %v", err)
+	}
+}
+
+// ResumeTracking - продолжение отслеживания через системный трей
+func (h *Handlers) ResumeTracking() {
+	sessions, err := h.backend().Status()
 	if err != nil {
+		h.Logger.Errorf("Ошибка получения запущенных отслеживаний: %v", err)
 		return
 	}
 
-	h.StopTrackingForProject(projectName)
+	var paused []string
+	for _, session := range sessions {
+		if session.PausedAt != nil {
+			paused = append(paused, session.Project)
+		}
+	}
+	if len(paused) != 1 {
+		return
+	}
+
+	if _, err := h.backend().Resume(paused[0]); err != nil {
+		h.Logger.Errorf("Ошибка продолжения отслеживания из трея: %v", err)
+	}
+}
+
+// Shutdown - завершение работы: остановка таймеров помидоров и трея
+// и сохранение данных. Запущенные сессии продолжатся после перезапуска.
+func (h *Handlers) Shutdown() {
+	// Данные и таймеры помидоров принадлежат демону
+	if h.Remote != nil {
+		h.SystrayHandler.StopTrayTicker()
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, projectName := range h.TrackingService.ActiveProjects(h.Projects) {
+		h.stopPomodoro(projectName)
+	}
+	h.SystrayHandler.StopTrayTicker()
+
+	if err := h.ProjectService.SaveData(h.Projects); err != nil {
+		h.Logger.Warnf("Данные не сохранены при выходе: %v", err)
+		fmt.Printf("Данные не сохранены: %v\n", err)
+	}
 }
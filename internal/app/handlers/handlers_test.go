@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/pkg/config"
+	"github.com/MWT-proger/time-tracking/pkg/dialog"
+)
+
+// dialogStub - диалог с заранее заданным ответом
+type dialogStub struct {
+	answer string
+	err    error
+	asked  []string
+}
+
+func (d *dialogStub) Entry(title, text string) (string, error) {
+	d.asked = append(d.asked, text)
+	return d.answer, d.err
+}
+
+func TestStartProjectSingleTimer(t *testing.T) {
+	tests := []struct {
+		name        string
+		dialog      *dialogStub
+		wantRunning string
+		wantEntry   string
+	}{
+		{
+			name:        "описание из диалога",
+			dialog:      &dialogStub{answer: "анализ"},
+			wantRunning: "beta",
+			wantEntry:   "анализ",
+		},
+		{
+			name:        "отмена диалога",
+			dialog:      &dialogStub{err: dialog.ErrCanceled},
+			wantRunning: "alpha",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newIdleTestHandlers(t, time.Now())
+			h.Config.TimerMode = config.TimerModeSingle
+			h.TrackingService.TimerMode = config.TimerModeSingle
+			h.Dialog = tt.dialog
+
+			h.StartProject("beta")
+
+			if len(tt.dialog.asked) != 1 {
+				t.Errorf("вопросы диалога: %v", tt.dialog.asked)
+			}
+
+			err := h.WithProjects(func(projects map[string]*domain.Project) error {
+				if running := h.TrackingService.ActiveProjects(projects); len(running) != 1 || running[0] != tt.wantRunning {
+					t.Errorf("запущены %v, ожидался %s", running, tt.wantRunning)
+				}
+
+				entries := projects["alpha"].Entries
+				if tt.wantEntry == "" && len(entries) != 0 {
+					t.Errorf("записи alpha: %+v", entries)
+				}
+				if tt.wantEntry != "" && (len(entries) != 1 || entries[0].Description != tt.wantEntry) {
+					t.Errorf("записи alpha: %+v, ожидалось описание '%s'", entries, tt.wantEntry)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/pkg/idle"
+	"github.com/manifoldco/promptui"
+)
+
+// QueueIdleSpan - сохранение периода бездействия до возвращения в меню.
+// Вызывается из горутины монитора, данные меняются только в ResolveIdleSpans.
+func (h *Handlers) QueueIdleSpan(span idle.Span) {
+	h.Logger.Infof("Обнаружено бездействие: %s - %s", span.Start.Format(time.RFC3339), span.End.Format(time.RFC3339))
+
+	h.idleMu.Lock()
+	h.idleSpans = append(h.idleSpans, span)
+	h.idleMu.Unlock()
+}
+
+// ResolveIdleSpans - выбор действия для накопленных периодов бездействия
+// в каждой сессии, которая шла в это время
+func (h *Handlers) ResolveIdleSpans() {
+	h.idleMu.Lock()
+	spans := h.idleSpans
+	h.idleSpans = nil
+	h.idleMu.Unlock()
+
+	now := time.Now()
+	for _, span := range spans {
+		// Пересечения находятся под блокировкой, вопросы задаются без нее
+		for _, overlap := range h.idleOverlaps(span, now) {
+			h.resolveIdleSpan(overlap.project, overlap.span)
+		}
+	}
+}
+
+// idleOverlaps - части периода бездействия внутри запущенных сессий.
+// Сессии берутся у демона, если он запущен.
+func (h *Handlers) idleOverlaps(span idle.Span, now time.Time) []idleOverlap {
+	sessions, err := h.backend().Status()
+	if err != nil {
+		h.Logger.Errorf("Ошибка получения запущенных отслеживаний: %v", err)
+		return nil
+	}
+
+	var overlaps []idleOverlap
+	for _, session := range sessions {
+		project := &domain.Project{StartTime: &session.Start, PausedAt: session.PausedAt}
+		overlap, ok := h.TrackingService.SessionOverlap(project, span.Start, span.End, now)
+		if ok {
+			overlaps = append(overlaps, idleOverlap{project: session.Project, span: idle.Span{Start: overlap.Start, End: overlap.End}})
+		}
+	}
+
+	return overlaps
+}
+
+// idleOverlap - часть периода бездействия внутри сессии проекта
+type idleOverlap struct {
+	project string
+	span    idle.Span
+}
+
+// resolveIdleSpan - оставить, исключить или перенести в другой проект период бездействия
+func (h *Handlers) resolveIdleSpan(projectName string, span idle.Span) {
+	prompt := promptui.Select{
+		Label: fmt.Sprintf("Вы отсутствовали %s (%s - %s), проект %s",
+			h.FormatDuration(span.Duration()),
+			span.Start.Local().Format("15:04"), span.End.Local().Format("15:04"), projectName),
+		Items: []string{
+			"Оставить время в сессии",
+			"Исключить время из сессии",
+			"Перенести время в другой проект",
+		},
+	}
+
+	_, choice, err := prompt.Run()
+	if err != nil {
+		h.Logger.Warnf("Выбор действия для бездействия отменен: %v", err)
+		return
+	}
+
+	switch choice {
+	case "Исключить время из сессии":
+		h.excludeIdleSpan(projectName, span)
+
+	case "Перенести время в другой проект":
+		target := h.chooseIdleTarget(projectName)
+		if target == "" {
+			return
+		}
+
+		descPrompt := promptui.Prompt{Label: "Что сделано"}
+		description, _ := descPrompt.Run()
+
+		h.moveIdleSpan(projectName, target, description, span)
+	}
+}
+
+// moveIdleSpan - запись периода в проект target и исключение его из сессии
+func (h *Handlers) moveIdleSpan(projectName, target, description string, span idle.Span) {
+	entry, err := h.backend().AddEntry(target, span.Start, span.End, description, "", false)
+	if err != nil {
+		h.Logger.Errorf("Ошибка переноса времени бездействия: %v", err)
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+	fmt.Printf("Добавлена запись в проект %s: %s\n", target, h.FormatEntry(entry))
+
+	h.excludeIdleSpan(projectName, span)
+}
+
+// excludeIdleSpan - исключение периода из сессии
+func (h *Handlers) excludeIdleSpan(projectName string, span idle.Span) {
+	excluded, err := h.backend().ExcludeSpan(projectName, span.Start, span.End)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Из сессии проекта %s исключено %s\n", projectName, h.FormatDuration(excluded))
+}
+
+// chooseIdleTarget - выбор проекта, в который переносится время бездействия
+func (h *Handlers) chooseIdleTarget(projectName string) string {
+	projects, err := h.backend().List(false)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return ""
+	}
+
+	var names []string
+	for _, project := range projects {
+		if project.Name != projectName {
+			names = append(names, project.Name)
+		}
+	}
+
+	if len(names) == 0 {
+		fmt.Println("Нет других проектов для переноса времени")
+		return ""
+	}
+
+	prompt := promptui.Select{
+		Label: "Перенести время в проект",
+		Items: names,
+	}
+
+	_, name, err := prompt.Run()
+	if err != nil {
+		return ""
+	}
+
+	return name
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/service"
+	"github.com/MWT-proger/time-tracking/internal/storage"
+	"github.com/MWT-proger/time-tracking/pkg/config"
+	"github.com/MWT-proger/time-tracking/pkg/idle"
+	"github.com/MWT-proger/time-tracking/pkg/logger"
+	"github.com/MWT-proger/time-tracking/pkg/pomodoro"
+)
+
+// trayStub - трей, который ничего не показывает
+type trayStub struct{}
+
+func (trayStub) SetTracking(string, *time.Time)                 {}
+func (trayStub) SetPaused(string, *time.Time, time.Duration)    {}
+func (trayStub) SetPomodoro(string, pomodoro.Phase, *time.Time) {}
+func (trayStub) SetProjects([]string)                           {}
+func (trayStub) StopTrayTicker()                                {}
+
+// newIdleTestHandlers - обработчики с проектом alpha, запущенным два часа назад,
+// и остановленным проектом beta
+func newIdleTestHandlers(t *testing.T, now time.Time) *Handlers {
+	t.Helper()
+
+	log := logger.NewLogger("error", io.Discard)
+	cfg := config.DefaultConfig()
+
+	projectService := service.NewProjectService(log, storage.NewMemoryStorage(service.CurrentSchemaVersion))
+	trackingService := service.NewTrackingService(projectService, log, cfg)
+	h := NewHandlers(projectService, trackingService, service.NewEntryService(projectService, log),
+		service.NewReportService(projectService, log), trayStub{}, log, cfg)
+
+	data, err := projectService.LoadData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"alpha", "beta"} {
+		if err := projectService.CreateProject(data, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := trackingService.StartTracking(data, "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	start := now.Add(-2 * time.Hour)
+	data["alpha"].StartTime = &start
+
+	h.SetProjects(data)
+	return h
+}
+
+// detectIdleSpan - период, который монитор передает обработчикам при возвращении
+// пользователя после часа бездействия, закончившегося 30 минут назад
+func detectIdleSpan(t *testing.T, h *Handlers, now time.Time) idle.Span {
+	t.Helper()
+
+	source := &idle.FakeSource{}
+	monitor := idle.NewMonitor(source, 5*time.Minute, h.QueueIdleSpan)
+
+	monitor.Now = func() time.Time { return now.Add(-30 * time.Minute) }
+	source.Set(time.Hour, nil)
+	if err := monitor.Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	monitor.Now = func() time.Time { return now.Add(-29 * time.Minute) }
+	source.Set(time.Minute, nil)
+	if err := monitor.Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	h.idleMu.Lock()
+	spans := h.idleSpans
+	h.idleSpans = nil
+	h.idleMu.Unlock()
+
+	if len(spans) != 1 {
+		t.Fatalf("периоды бездействия %v, ожидался один", spans)
+	}
+	return spans[0]
+}
+
+func TestIdleOverlaps(t *testing.T) {
+	now := time.Now()
+	h := newIdleTestHandlers(t, now)
+	span := detectIdleSpan(t, h, now)
+
+	want := idle.Span{Start: now.Add(-90 * time.Minute), End: now.Add(-30 * time.Minute)}
+	if span != want {
+		t.Errorf("период %v, ожидался %v", span, want)
+	}
+
+	overlaps := h.idleOverlaps(span, now)
+	if len(overlaps) != 1 || overlaps[0].project != "alpha" || overlaps[0].span != want {
+		t.Errorf("пересечения с сессиями: %+v", overlaps)
+	}
+
+	// Бездействие до начала сессии обрезается по её началу
+	early := idle.Span{Start: now.Add(-3 * time.Hour), End: now.Add(-time.Hour)}
+	overlaps = h.idleOverlaps(early, now)
+	if len(overlaps) != 1 || !overlaps[0].span.Start.Equal(*h.Projects["alpha"].StartTime) {
+		t.Errorf("пересечения с сессиями: %+v", overlaps)
+	}
+
+	if overlaps := h.idleOverlaps(idle.Span{Start: now.Add(-4 * time.Hour), End: now.Add(-3 * time.Hour)}, now); len(overlaps) != 0 {
+		t.Errorf("бездействие вне сессии: %+v", overlaps)
+	}
+}
+
+func TestResolveIdleSpan(t *testing.T) {
+	tests := []struct {
+		name       string
+		resolve    func(h *Handlers, span idle.Span)
+		wantBreaks time.Duration
+		wantMoved  time.Duration
+	}{
+		{
+			name:    "оставить время в сессии",
+			resolve: func(*Handlers, idle.Span) {},
+		},
+		{
+			name: "исключить время из сессии",
+			resolve: func(h *Handlers, span idle.Span) {
+				h.excludeIdleSpan("alpha", span)
+			},
+			wantBreaks: time.Hour,
+		},
+		{
+			name: "перенести время в другой проект",
+			resolve: func(h *Handlers, span idle.Span) {
+				h.moveIdleSpan("alpha", "beta", "встреча", span)
+			},
+			wantBreaks: time.Hour,
+			wantMoved:  time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			h := newIdleTestHandlers(t, now)
+			span := detectIdleSpan(t, h, now)
+
+			for _, overlap := range h.idleOverlaps(span, now) {
+				tt.resolve(h, overlap.span)
+			}
+
+			err := h.WithProjects(func(projects map[string]*domain.Project) error {
+				if breaks := domain.BreaksDuration(projects["alpha"].Breaks); breaks != tt.wantBreaks {
+					t.Errorf("перерывы сессии %v, ожидалось %v", breaks, tt.wantBreaks)
+				}
+
+				var moved time.Duration
+				for _, entry := range projects["beta"].Entries {
+					moved += entry.Duration()
+				}
+				if moved != tt.wantMoved {
+					t.Errorf("перенесено %v, ожидалось %v", moved, tt.wantMoved)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/pkg/notify"
+	"github.com/MWT-proger/time-tracking/pkg/pomodoro"
+)
+
+// startPomodoro - начало помидора для проекта, false если режим помидоров отключен
+func (h *Handlers) startPomodoro(projectName string) bool {
+	if h.Pomodoro == nil {
+		return false
+	}
+
+	state := h.Pomodoro.Start(projectName)
+	h.Logger.Infof("Начат помидор для проекта %s до %s", projectName, state.End.Format("15:04:05"))
+	h.SystrayHandler.SetPomodoro(projectName, state.Phase, &state.End)
+
+	return true
+}
+
+// stopPomodoro - отмена помидора проекта
+func (h *Handlers) stopPomodoro(projectName string) {
+	if h.Pomodoro == nil {
+		return
+	}
+
+	h.Pomodoro.Stop(projectName)
+	h.SystrayHandler.SetPomodoro(projectName, "", nil)
+}
+
+// OnPomodoroPhase - смена этапа помидора: на время перерыва отслеживание
+// приостанавливается, после перерыва продолжается
+func (h *Handlers) OnPomodoroPhase(state pomodoro.State) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Этап мог смениться, пока событие ждало блокировки: помидор остановлен
+	// или начат заново, и событие относится к прошлому циклу
+	if current, ok := h.Pomodoro.State(state.Project); !ok || current != state {
+		h.Logger.Debugf("Устаревшее событие помидора проекта %s пропущено: %s", state.Project, state.Phase)
+		return
+	}
+
+	project := h.Projects[state.Project]
+	if project == nil || project.StartTime == nil {
+		h.stopPomodoro(state.Project)
+		return
+	}
+
+	length := h.FormatDuration(state.End.Sub(state.Start))
+	if state.Phase.IsBreak() {
+		if project.PausedAt == nil {
+			if err := h.TrackingService.PauseTracking(h.Projects, state.Project); err != nil {
+				h.Logger.Errorf("Ошибка приостановки отслеживания на перерыв: %v", err)
+			}
+		}
+
+		h.Logger.Infof("Помидор %d проекта %s завершен, перерыв %s", state.Cycles, state.Project, length)
+		h.notify("Помидор", fmt.Sprintf("Помидор №%d за сегодня завершен (%s). Перерыв %s.", state.Cycles, state.Project, length))
+	} else {
+		if project.PausedAt != nil {
+			if _, err := h.TrackingService.ResumeTracking(h.Projects, state.Project); err != nil {
+				h.Logger.Errorf("Ошибка продолжения отслеживания после перерыва: %v", err)
+			}
+		}
+
+		h.Logger.Infof("Перерыв окончен, начат помидор проекта %s", state.Project)
+		h.notify("Помидор", fmt.Sprintf("Перерыв окончен. Следующий помидор (%s): %s.", state.Project, length))
+	}
+
+	h.SystrayHandler.SetPaused(state.Project, project.PausedAt, domain.BreaksDuration(project.Breaks))
+	h.SystrayHandler.SetPomodoro(state.Project, state.Phase, &state.End)
+}
+
+// notify - отправка уведомления о смене этапа
+func (h *Handlers) notify(title, message string) {
+	err := h.TrackingService.Notifier.Notify(notify.Notification{Title: title, Message: message})
+	if err != nil {
+		h.Logger.Warnf("Ошибка отправки уведомления: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/pkg/clock"
+	"github.com/MWT-proger/time-tracking/pkg/pomodoro"
+)
+
+func TestOnPomodoroPhase(t *testing.T) {
+	now := time.Now()
+	h := newIdleTestHandlers(t, now)
+
+	clk := clock.NewFake(now)
+	h.Pomodoro = pomodoro.NewEngine(pomodoro.DefaultSettings(), clk, h.OnPomodoroPhase)
+
+	paused := func() bool {
+		t.Helper()
+		var paused bool
+		err := h.WithProjects(func(projects map[string]*domain.Project) error {
+			paused = projects["alpha"].PausedAt != nil
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return paused
+	}
+
+	// Событие перерыва остановленного цикла приходит после нового запуска
+	h.mu.Lock()
+	h.startPomodoro("alpha")
+	h.mu.Unlock()
+	stale, _ := h.Pomodoro.State("alpha")
+	stale.Phase = pomodoro.PhaseShortBreak
+
+	h.mu.Lock()
+	h.stopPomodoro("alpha")
+	h.startPomodoro("alpha")
+	h.mu.Unlock()
+
+	h.OnPomodoroPhase(stale)
+	if paused() {
+		t.Error("устаревшее событие перерыва приостановило новую сессию")
+	}
+
+	// Перерыв текущего цикла приостанавливает отслеживание, его окончание продолжает
+	clk.Advance(pomodoro.DefaultSettings().Work)
+	if !paused() {
+		t.Error("отслеживание не приостановлено на перерыв")
+	}
+	clk.Advance(pomodoro.DefaultSettings().ShortBreak)
+	if paused() {
+		t.Error("отслеживание не продолжено после перерыва")
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
+	"github.com/MWT-proger/time-tracking/internal/domain"
 	"github.com/manifoldco/promptui"
 )
 
@@ -19,10 +22,9 @@ func (h *Handlers) CreateProject() {
 			}
 
 			// Проверка на уникальность имени
-			if _, exists := h.Projects[input]; exists {
+			if _, err := h.projectInfo(input); err == nil {
 				return fmt.Errorf("проект с именем '%s' уже существует", input)
 			}
-
 			return nil
 		},
 	}
@@ -36,9 +38,7 @@ func (h *Handlers) CreateProject() {
 
 	h.Logger.Infof("Попытка создания проекта: %s", name)
 
-	err = h.ProjectService.CreateProject(h.Projects, name)
-	if err != nil {
-		h.Logger.Errorf("Ошибка создания проекта: %v", err)
+	if err := h.backend().AddProject(name); err != nil {
 		fmt.Printf("Ошибка: %v\n", err)
 		return
 	}
@@ -52,8 +52,13 @@ func (h *Handlers) ArchiveProject(projectName string) {
 	h.Logger.Infof("Попытка архивировать проект: %s", projectName)
 
 	// Проверяем, запущено ли отслеживание для проекта
-	project := h.Projects[projectName]
-	if project.StartTime != nil {
+	info, err := h.projectInfo(projectName)
+	if err != nil {
+		h.Logger.Errorf("Ошибка архивирования проекта: %v", err)
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+	if info.Running {
 		h.Logger.Warnf("Невозможно архивировать проект '%s' с запущенным отслеживанием", projectName)
 		fmt.Printf("Невозможно архивировать проект '%s' с запущенным отслеживанием\n", projectName)
 		return
@@ -79,9 +84,7 @@ func (h *Handlers) ArchiveProject(projectName string) {
 		return
 	}
 
-	err = h.ProjectService.ArchiveProject(h.Projects, projectName)
-	if err != nil {
-		h.Logger.Errorf("Ошибка архивирования проекта: %v", err)
+	if err := h.backend().Archive(projectName, true); err != nil {
 		fmt.Printf("Ошибка: %v\n", err)
 		return
 	}
@@ -114,9 +117,7 @@ func (h *Handlers) RestoreProject(projectName string) {
 		return
 	}
 
-	err = h.ProjectService.RestoreProject(h.Projects, projectName)
-	if err != nil {
-		h.Logger.Errorf("Ошибка восстановления проекта: %v", err)
+	if err := h.backend().Archive(projectName, false); err != nil {
 		fmt.Printf("Ошибка: %v\n", err)
 		return
 	}
@@ -125,6 +126,22 @@ func (h *Handlers) RestoreProject(projectName string) {
 	fmt.Printf("Проект '%s' успешно восстановлен из архива\n", projectName)
 }
 
+// projectInfo - состояние проекта у исполнителя операций
+func (h *Handlers) projectInfo(projectName string) (daemon.ProjectInfo, error) {
+	projects, err := h.backend().List(true)
+	if err != nil {
+		return daemon.ProjectInfo{}, err
+	}
+
+	for _, project := range projects {
+		if project.Name == projectName {
+			return project, nil
+		}
+	}
+
+	return daemon.ProjectInfo{}, fmt.Errorf("проект '%s' не найден", projectName)
+}
+
 // ChooseProject - выбор проекта из списка
 func (h *Handlers) ChooseProject() string {
 	h.Logger.Debug("Выбор проекта из списка")
@@ -134,21 +151,26 @@ func (h *Handlers) ChooseProject() string {
 	var inactiveProjects []string
 	var archivedProjects []string
 
-	for name, project := range h.Projects {
+	// Проекты приходят отсортированными по алфавиту
+	projects, err := h.backend().List(true)
+	if err != nil {
+		h.Logger.Errorf("Ошибка получения списка проектов: %v", err)
+		fmt.Printf("Ошибка: %v\n", err)
+		return ""
+	}
+
+	for _, project := range projects {
 		if project.Archived {
-			archivedProjects = append(archivedProjects, "📦 "+name)
-		} else if project.StartTime != nil {
-			activeProjects = append(activeProjects, "▶ "+name)
+			archivedProjects = append(archivedProjects, "📦 "+project.Name)
+		} else if project.Paused {
+			activeProjects = append(activeProjects, "⏸ "+project.Name)
+		} else if project.Running {
+			activeProjects = append(activeProjects, "▶ "+project.Name)
 		} else {
-			inactiveProjects = append(inactiveProjects, "⏹ "+name)
+			inactiveProjects = append(inactiveProjects, "⏹ "+project.Name)
 		}
 	}
 
-	// Сортируем проекты по алфавиту
-	sort.Strings(activeProjects)
-	sort.Strings(inactiveProjects)
-	sort.Strings(archivedProjects)
-
 	// Объединяем списки: сначала "Назад", затем активные, затем неактивные, затем разделитель, затем архивные
 	options := append([]string{"← Назад"}, activeProjects...)
 	options = append(options, inactiveProjects...)
@@ -189,7 +211,10 @@ func (h *Handlers) ChooseProject() string {
 	}
 
 	// Удаляем префикс статуса из имени проекта
-	projectName := strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(result, "▶ "), "⏹ "), "📦 ")
+	projectName := result
+	for _, prefix := range []string{"▶ ", "⏸ ", "⏹ ", "📦 "} {
+		projectName = strings.TrimPrefix(projectName, prefix)
+	}
 	h.Logger.Debugf("Выбран проект: %s", projectName)
 
 	return projectName
@@ -206,15 +231,23 @@ func (h *Handlers) SelectAndManageProject() {
 		return
 	}
 
-	project := h.Projects[projectName]
-	h.Logger.Debugf("Управление проектом: %s (архивирован: %v)", projectName, project.Archived)
+	h.Logger.Debugf("Управление проектом: %s", projectName)
 
 	// Меню управления проектом
 	for {
 		var menuItems []string
 		var projectLabel string
 
-		if project.Archived {
+		// Состояние проекта читается заново: его могли изменить трей, таймеры
+		// или другие клиенты демона
+		info, err := h.projectInfo(projectName)
+		if err != nil {
+			h.Logger.Errorf("Ошибка управления проектом: %v", err)
+			fmt.Printf("Ошибка: %v\n", err)
+			return
+		}
+
+		if info.Archived {
 			menuItems = []string{
 				"Восстановить из архива",
 				"Статистика проекта",
@@ -226,11 +259,22 @@ func (h *Handlers) SelectAndManageProject() {
 			menuItems = []string{
 				"Начать отслеживание",
 				"Остановить отслеживание",
+			}
+			// Пауза доступна только для запущенного отслеживания
+			switch {
+			case info.Running && info.Paused:
+				menuItems = append(menuItems, "Продолжить отслеживание")
+			case info.Running:
+				menuItems = append(menuItems, "Приостановить отслеживание")
+			}
+			menuItems = append(menuItems,
 				"Управление спринтами",
 				"Статистика проекта",
+				"Добавить запись вручную",
+				"Записи времени",
 				"Архивировать проект",
 				"Назад в главное меню",
-			}
+			)
 			projectLabel = fmt.Sprintf("Проект: %s", projectName)
 			h.Logger.Debugf("Отображение меню для активного проекта: %s", projectName)
 		}
@@ -248,18 +292,24 @@ func (h *Handlers) SelectAndManageProject() {
 			h.StartTrackingForProject(projectName)
 		case "Остановить отслеживание":
 			h.StopTrackingForProject(projectName)
+		case "Приостановить отслеживание":
+			h.PauseTrackingForProject(projectName)
+		case "Продолжить отслеживание":
+			h.ResumeTrackingForProject(projectName)
 		case "Управление спринтами":
 			h.ManageSprintsForProject(projectName)
 		case "Статистика проекта":
 			h.ShowProjectStatistics(projectName)
+		case "Добавить запись вручную":
+			h.AddEntryForProject(projectName)
+		case "Записи времени":
+			h.ManageEntriesForProject(projectName)
 		case "Архивировать проект":
 			h.ArchiveProject(projectName)
 			// После архивирования возвращаемся в главное меню
 			return
 		case "Восстановить из архива":
 			h.RestoreProject(projectName)
-			// Обновляем проект после восстановления
-			project = h.Projects[projectName]
 			h.Logger.Debugf("Проект %s восстановлен из архива, обновление состояния", projectName)
 		case "Назад в главное меню":
 			h.Logger.Debugf("Возврат в главное меню из проекта %s", projectName)
@@ -270,41 +320,82 @@ func (h *Handlers) SelectAndManageProject() {
 
 // ShowProjectStatistics - вывод статистики по конкретному проекту
 func (h *Handlers) ShowProjectStatistics(projectName string) {
-	project := h.Projects[projectName]
+	entries, err := h.projectEntries(projectName)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
 
 	fmt.Printf("\nСтатистика проекта \"%s\":\n", projectName)
+	fmt.Printf("  Общее время: %s\n", h.FormatDuration(entriesDuration(entries)))
+	h.printSprintsSpent(projectName)
+
+	// Время по дням, сессии через полночь делятся между днями
+	totals := make(map[string]time.Duration)
+	for _, entry := range entries {
+		for _, part := range entry.SplitByDay(time.Local) {
+			totals[part.Day] += part.Duration
+		}
+	}
+	if len(totals) > 0 {
+		days := make([]string, 0, len(totals))
+		for day := range totals {
+			days = append(days, day)
+		}
+		sort.Strings(days)
 
-	// Общее время по проекту
-	var totalProject int
-	for _, entry := range project.Entries {
-		totalProject += entry.TimeSpent
+		fmt.Println("  По дням:")
+		for _, day := range days {
+			fmt.Printf("    %s: %s\n", day, h.FormatDuration(totals[day]))
+		}
 	}
 
-	fmt.Printf("  Общее время: %s\n", h.FormatTimeSpent(totalProject))
+	// Показываем записи проекта
+	fmt.Println("  Записи:")
+	for _, entry := range entries {
+		fmt.Printf("    %s - %s: %s\n", h.FormatEntryPeriod(entry), h.FormatDuration(entry.Duration()), entry.Description)
+	}
+}
+
+// printSprintsSpent - время по спринтам проекта, если они есть
+func (h *Handlers) printSprintsSpent(projectName string) {
+	sprints, err := h.backend().Sprints(projectName)
+	if err != nil || len(sprints) == 0 {
+		return
+	}
 
-	// Если есть спринты, показываем статистику по ним
-	if project.Sprints != nil && len(project.Sprints) > 0 {
-		fmt.Println("  Спринты:")
+	fmt.Println("  Спринты:")
+	for _, sprint := range sprints {
+		status := ""
+		if sprint.Active {
+			status = " (Активный)"
+		}
 
-		sprints, _ := h.ProjectService.GetProjectSprints(h.Projects, projectName)
-		for _, sprint := range sprints {
-			var totalSprint int
-			for _, entry := range sprint.Entries {
-				totalSprint += entry.TimeSpent
-			}
+		fmt.Printf("    %s%s: %s\n", sprint.Name, status, h.FormatDuration(sprint.Spent))
+	}
+}
 
-			status := ""
-			if sprint.IsActive {
-				status = " (Активный)"
-			}
+// projectEntries - записи времени проекта у исполнителя операций
+func (h *Handlers) projectEntries(projectName string) ([]domain.TimeEntry, error) {
+	infos, err := h.backend().Entries(projectName)
+	if err != nil {
+		return nil, err
+	}
 
-			fmt.Printf("    %s%s: %s\n", sprint.Name, status, h.FormatTimeSpent(totalSprint))
-		}
+	entries := make([]domain.TimeEntry, 0, len(infos))
+	for _, info := range infos {
+		entries = append(entries, info.TimeEntry)
 	}
 
-	// Показываем записи проекта
-	fmt.Println("  Записи:")
-	for _, entry := range project.Entries {
-		fmt.Printf("    %s - %s: %s\n", entry.Date, h.FormatTimeSpent(entry.TimeSpent), entry.Description)
+	return entries, nil
+}
+
+// entriesDuration - суммарное время записей
+func entriesDuration(entries []domain.TimeEntry) time.Duration {
+	var total time.Duration
+	for _, entry := range entries {
+		total += entry.Duration()
 	}
+
+	return total
 }
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/storage"
+	"github.com/manifoldco/promptui"
+)
+
+// RecoverData - интерактивное восстановление поврежденных данных
+func (h *Handlers) RecoverData(loadErr error) (map[string]*domain.Project, error) {
+	fmt.Printf("Не удалось прочитать данные: %v\n", loadErr)
+
+	report, err := h.ProjectService.RecoverData(false)
+	if errors.Is(err, storage.ErrNoValidBackup) {
+		prompt := promptui.Select{
+			Label: "Исправных резервных копий нет. Восстановить уцелевшие проекты из поврежденного файла?",
+			Items: []string{"Да", "Нет, выйти"},
+		}
+
+		idx, _, promptErr := prompt.Run()
+		if promptErr != nil || idx != 0 {
+			h.Logger.Info("Пользователь отказался от частичного восстановления данных")
+			return nil, loadErr
+		}
+
+		report, err = h.ProjectService.RecoverData(true)
+	}
+	if err != nil {
+		h.Logger.Errorf("Ошибка восстановления данных: %v", err)
+		return nil, err
+	}
+
+	h.PrintRecoveryReport(report)
+
+	return h.ProjectService.LoadData()
+}
+
+// PrintRecoveryReport - вывод отчета о восстановлении данных
+func (h *Handlers) PrintRecoveryReport(report *storage.RecoveryReport) {
+	fmt.Printf("Поврежденный файл сохранен как: %s\n", report.QuarantinePath)
+
+	if report.Backup != nil {
+		fmt.Printf("Данные восстановлены из резервной копии от %s\n", report.Backup.Time.Format("2006-01-02 15:04:05"))
+		if len(report.ChangedSinceBackup) > 0 {
+			fmt.Printf("Потеряны изменения после создания копии в проектах: %s\n", strings.Join(report.ChangedSinceBackup, ", "))
+		}
+	} else {
+		fmt.Printf("Восстановлено проектов: %d\n", len(report.Salvaged))
+		if len(report.Salvaged) > 0 {
+			fmt.Printf("  %s\n", strings.Join(report.Salvaged, ", "))
+		}
+	}
+
+	if len(report.Damaged) > 0 {
+		fmt.Printf("Не удалось прочитать проекты: %s\n", strings.Join(report.Damaged, ", "))
+	}
+
+	if report.Truncated {
+		fmt.Printf("Файл поврежден начиная с байта %d: проекты после этой позиции потеряны\n", report.Offset)
+	}
+}
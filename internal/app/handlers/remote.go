@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
+)
+
+// SyncTray - обновление таймеров трея по состоянию демона,
+// который мог изменить его по запросам других клиентов
+func (h *Handlers) SyncTray() {
+	h.UpdateTrayProjects()
+
+	sessions, err := h.Remote.Status()
+	if err != nil {
+		h.Logger.Warnf("Ошибка получения состояния демона: %v", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	current := make(map[string]daemon.Session, len(sessions))
+	for _, session := range sessions {
+		current[session.Project] = session
+		known, exists := h.traySessions[session.Project]
+
+		if !exists || !known.Start.Equal(session.Start) {
+			start := session.Start
+			h.SystrayHandler.SetTracking(session.Project, &start)
+		}
+		if !exists || !samePause(known, session) {
+			h.SystrayHandler.SetPaused(session.Project, session.PausedAt, session.Breaks)
+		}
+	}
+
+	for projectName := range h.traySessions {
+		if _, exists := current[projectName]; !exists {
+			h.SystrayHandler.SetTracking(projectName, nil)
+		}
+	}
+
+	h.traySessions = current
+}
+
+// samePause - совпадают ли пауза и перерывы двух состояний сессии
+func samePause(a, b daemon.Session) bool {
+	if a.Breaks != b.Breaks || (a.PausedAt == nil) != (b.PausedAt == nil) {
+		return false
+	}
+
+	return a.PausedAt == nil || a.PausedAt.Equal(*b.PausedAt)
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/service"
+	"github.com/manifoldco/promptui"
+)
+
+// PrintReport - вывод отчета: при группировке по дням, неделям и месяцам
+// для каждого периода выводится итог и время по проектам
+func (h *Handlers) PrintReport(w io.Writer, report service.Report) {
+	filter := report.Filter
+	fmt.Fprintf(w, "Отчет за %s - %s", filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02"))
+	if filter.Project != "" {
+		fmt.Fprintf(w, ", проект %s", filter.Project)
+	}
+	fmt.Fprintln(w)
+
+	if len(report.Rows) == 0 {
+		fmt.Fprintln(w, "Нет записей за период")
+		return
+	}
+
+	switch filter.GroupBy {
+	case service.GroupByProject:
+		for _, row := range report.Rows {
+			fmt.Fprintf(w, "  %s: %s\n", row.Project, h.FormatDuration(row.Duration))
+		}
+	case service.GroupBySprint:
+		for _, row := range report.Rows {
+			fmt.Fprintf(w, "  %s / %s: %s\n", row.Project, row.Sprint, h.FormatDuration(row.Duration))
+		}
+	default:
+		for i := 0; i < len(report.Rows); {
+			period := report.Rows[i].Period
+
+			// Строки отсортированы по периоду, считаем итог периода
+			j := i
+			var total time.Duration
+			for ; j < len(report.Rows) && report.Rows[j].Period == period; j++ {
+				total += report.Rows[j].Duration
+			}
+
+			fmt.Fprintf(w, "%s: %s\n", period, h.FormatDuration(total))
+			for _, row := range report.Rows[i:j] {
+				fmt.Fprintf(w, "  %s: %s\n", row.Project, h.FormatDuration(row.Duration))
+			}
+			i = j
+		}
+	}
+
+	fmt.Fprintf(w, "Итого: %s\n", h.FormatDuration(report.Total))
+}
+
+// ReportPeriod - период отчета по названию: сегодня, неделя (с понедельника),
+// месяц или последние 30 дней
+func ReportPeriod(name string, now time.Time) (time.Time, time.Time) {
+	now = now.In(time.Local)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	switch name {
+	case "week":
+		offset := (int(today.Weekday()) + 6) % 7
+		return today.AddDate(0, 0, -offset), today
+	case "month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local), today
+	case "30d":
+		return today.AddDate(0, 0, -29), today
+	default:
+		return today, today
+	}
+}
+
+// ShowReport - отчет за период из меню
+func (h *Handlers) ShowReport() {
+	periods := []struct{ label, name string }{
+		{"Сегодня", "today"},
+		{"Эта неделя", "week"},
+		{"Этот месяц", "month"},
+		{"Последние 30 дней", "30d"},
+		{"Другой период", ""},
+	}
+	var labels []string
+	for _, period := range periods {
+		labels = append(labels, period.label)
+	}
+
+	periodPrompt := promptui.Select{Label: "Период отчета", Items: labels}
+	idx, _, err := periodPrompt.Run()
+	if err != nil {
+		return
+	}
+
+	from, to := ReportPeriod(periods[idx].name, time.Now())
+	if periods[idx].name == "" {
+		if from, to, err = h.promptReportDates(); err != nil {
+			fmt.Printf("Ошибка: %v\n", err)
+			return
+		}
+	}
+
+	groups := []struct {
+		label string
+		group service.GroupBy
+	}{
+		{"По дням", service.GroupByDay},
+		{"По неделям", service.GroupByWeek},
+		{"По месяцам", service.GroupByMonth},
+		{"По проектам", service.GroupByProject},
+		{"По спринтам", service.GroupBySprint},
+	}
+	var groupLabels []string
+	for _, group := range groups {
+		groupLabels = append(groupLabels, group.label)
+	}
+
+	groupPrompt := promptui.Select{Label: "Группировка", Items: groupLabels}
+	idx, _, err = groupPrompt.Run()
+	if err != nil {
+		return
+	}
+
+	report, err := h.backend().Report(service.ReportFilter{
+		From:    from,
+		To:      to,
+		GroupBy: groups[idx].group,
+	})
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	fmt.Println()
+	h.PrintReport(os.Stdout, report)
+}
+
+// promptReportDates - ввод начала и окончания периода отчета
+func (h *Handlers) promptReportDates() (time.Time, time.Time, error) {
+	now := time.Now()
+	validate := func(input string) error {
+		_, err := ParseUserDate(input, now)
+		return err
+	}
+
+	fromPrompt := promptui.Prompt{Label: "С (ГГГГ-ММ-ДД, вчера, -N)", Validate: validate}
+	fromValue, err := fromPrompt.Run()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	toPrompt := promptui.Prompt{Label: "По", Default: "сегодня", AllowEdit: true, Validate: validate}
+	toValue, err := toPrompt.Run()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	from, _ := ParseUserDate(fromValue, now)
+	to, _ := ParseUserDate(toValue, now)
+	return from, to, nil
+}
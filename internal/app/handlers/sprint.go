@@ -2,8 +2,8 @@ package handlers
 
 import (
 	"fmt"
-	"strings"
 
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
 	"github.com/manifoldco/promptui"
 )
 
@@ -45,15 +45,12 @@ func (h *Handlers) CreateSprintForProject(projectName string) {
 			}
 
 			// Проверка на уникальность имени спринта
-			project := h.Projects[projectName]
-			if project.Sprints != nil {
-				for _, sprint := range project.Sprints {
-					if sprint.Name == input {
-						return fmt.Errorf("спринт с именем '%s' уже существует", input)
-					}
+			sprints, _ := h.backend().Sprints(projectName)
+			for _, sprint := range sprints {
+				if sprint.Name == input {
+					return fmt.Errorf("спринт с именем '%s' уже существует", input)
 				}
 			}
-
 			return nil
 		},
 	}
@@ -73,9 +70,7 @@ func (h *Handlers) CreateSprintForProject(projectName string) {
 	description, _ := descPrompt.Run()
 
 	// Создание спринта
-	err = h.ProjectService.CreateSprint(h.Projects, projectName, sprintName, description)
-	if err != nil {
-		h.Logger.Errorf("Ошибка создания спринта: %v", err)
+	if err := h.backend().CreateSprint(projectName, sprintName, description); err != nil {
 		fmt.Printf("Ошибка: %v\n", err)
 		return
 	}
@@ -86,29 +81,27 @@ func (h *Handlers) CreateSprintForProject(projectName string) {
 
 // SetActiveSprintForProject - установка активного спринта для конкретного проекта
 func (h *Handlers) SetActiveSprintForProject(projectName string) {
-	project := h.Projects[projectName]
-
-	// Проверка наличия спринтов
-	if project.Sprints == nil || len(project.Sprints) == 0 {
-		fmt.Printf("У проекта '%s' нет спринтов\n", projectName)
-		return
-	}
-
 	// Получение списка спринтов
-	sprints, err := h.ProjectService.GetProjectSprints(h.Projects, projectName)
+	sprints, err := h.backend().Sprints(projectName)
 	if err != nil {
 		h.Logger.Errorf("Ошибка получения спринтов: %v", err)
 		fmt.Printf("Ошибка: %v\n", err)
 		return
 	}
 
+	// Проверка наличия спринтов
+	if len(sprints) == 0 {
+		fmt.Printf("У проекта '%s' нет спринтов\n", projectName)
+		return
+	}
+
 	// Создание списка для выбора
 	var options []string
 	options = append(options, "← Назад")
 
 	for _, sprint := range sprints {
 		prefix := "  "
-		if sprint.IsActive {
+		if sprint.Active {
 			prefix = "▶ "
 		}
 		options = append(options, prefix+sprint.Name)
@@ -120,26 +113,14 @@ func (h *Handlers) SetActiveSprintForProject(projectName string) {
 		Items: options,
 	}
 
-	idx, result, err := prompt.Run()
+	idx, _, err := prompt.Run()
 	if err != nil || idx == 0 {
 		return
 	}
 
-	// Получение ID выбранного спринта
-	selectedName := strings.TrimPrefix(strings.TrimPrefix(result, "▶ "), "  ")
-	var selectedID string
-
-	for _, sprint := range sprints {
-		if sprint.Name == selectedName {
-			selectedID = sprint.ID
-			break
-		}
-	}
-
 	// Установка активного спринта
-	err = h.ProjectService.SetActiveSprint(h.Projects, projectName, selectedID)
-	if err != nil {
-		h.Logger.Errorf("Ошибка установки активного спринта: %v", err)
+	selectedName := sprints[idx-1].Name
+	if err := h.backend().SetActiveSprint(projectName, selectedName); err != nil {
 		fmt.Printf("Ошибка: %v\n", err)
 		return
 	}
@@ -150,18 +131,22 @@ func (h *Handlers) SetActiveSprintForProject(projectName string) {
 
 // ViewSprintsForProject - просмотр спринтов для конкретного проекта
 func (h *Handlers) ViewSprintsForProject(projectName string) {
-	project := h.Projects[projectName]
+	sprints, err := h.backend().Sprints(projectName)
+	if err != nil {
+		h.Logger.Errorf("Ошибка получения спринтов: %v", err)
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
 
 	// Проверка наличия спринтов
-	if project.Sprints == nil || len(project.Sprints) == 0 {
+	if len(sprints) == 0 {
 		fmt.Printf("У проекта '%s' нет спринтов\n", projectName)
 		return
 	}
 
-	// Получение списка спринтов
-	sprints, err := h.ProjectService.GetProjectSprints(h.Projects, projectName)
+	entries, err := h.backend().Entries(projectName)
 	if err != nil {
-		h.Logger.Errorf("Ошибка получения спринтов: %v", err)
+		h.Logger.Errorf("Ошибка получения записей: %v", err)
 		fmt.Printf("Ошибка: %v\n", err)
 		return
 	}
@@ -170,7 +155,7 @@ func (h *Handlers) ViewSprintsForProject(projectName string) {
 
 	for _, sprint := range sprints {
 		status := "Неактивный"
-		if sprint.IsActive {
+		if sprint.Active {
 			status = "Активный"
 		}
 
@@ -180,19 +165,19 @@ func (h *Handlers) ViewSprintsForProject(projectName string) {
 		}
 		fmt.Printf("  Дата начала: %s\n", sprint.StartDate)
 
-		// Подсчет времени по спринту
-		var total int
-		for _, entry := range sprint.Entries {
-			total += entry.TimeSpent
-		}
-
-		fmt.Printf("  Общее время: %s\n", h.FormatTimeSpent(total))
+		fmt.Printf("  Общее время: %s\n", h.FormatTimeSpent(int(sprint.Spent.Seconds())))
 
 		// Вывод записей спринта
-		if len(sprint.Entries) > 0 {
+		var sprintEntries []daemon.Entry
+		for _, entry := range entries {
+			if entry.Sprint == sprint.Name {
+				sprintEntries = append(sprintEntries, entry)
+			}
+		}
+		if len(sprintEntries) > 0 {
 			fmt.Println("  Записи:")
-			for _, entry := range sprint.Entries {
-				fmt.Printf("    %s - %s: %s\n", entry.Date, h.FormatTimeSpent(entry.TimeSpent), entry.Description)
+			for _, entry := range sprintEntries {
+				fmt.Printf("    %s - %s: %s\n", h.FormatEntryPeriod(entry.TimeEntry), h.FormatDuration(entry.Duration()), entry.Description)
 			}
 		}
 	}
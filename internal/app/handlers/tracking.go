@@ -10,10 +10,14 @@ import (
 
 // StartTrackingForProject - начало отслеживания времени для конкретного проекта
 func (h *Handlers) StartTrackingForProject(projectName string) {
-	project := h.Projects[projectName]
+	sprints, err := h.backend().Sprints(projectName)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
 	// Если у проекта есть спринты, предлагаем выбрать активный спринт
-	if project.Sprints != nil && len(project.Sprints) > 0 {
+	if len(sprints) > 0 {
 		prompt := promptui.Select{
 			Label: "Выберите спринт для отслеживания",
 			Items: []string{
@@ -28,53 +32,187 @@ func (h *Handlers) StartTrackingForProject(projectName string) {
 		}
 	}
 
-	h.Logger.Infof("Попытка начать отслеживание для проекта: %s", projectName)
-	err := h.TrackingService.StartTracking(h.Projects, projectName)
+	// В режиме одного таймера сначала останавливаем запущенные проекты
+	conflicting, err := h.conflictingSessions(projectName)
 	if err != nil {
-		h.Logger.Errorf("Ошибка начала отслеживания: %v", err)
 		fmt.Println(err)
 		return
 	}
 
-	h.Logger.Infof("Начато отслеживание для проекта: %s", projectName)
+	for _, running := range conflicting {
+		fmt.Printf("Запущено отслеживание проекта %s, оно будет остановлено\n", running)
+		h.StopTrackingForProject(running)
+	}
+
+	h.Logger.Infof("Попытка начать отслеживание для проекта: %s", projectName)
+	if _, err := h.backend().Start(projectName, "", ""); err != nil {
+		fmt.Println(err)
+		return
+	}
+
 	fmt.Println("Начато отслеживание для проекта:", projectName)
+}
+
+// RestoreSessions - восстановление запущенных сессий после перезапуска:
+// слишком долгие сессии можно оставить, ограничить или отменить,
+// для остальных восстанавливается таймер в трее и напоминания о перерыве
+func (h *Handlers) RestoreSessions() {
+	now := time.Now()
+
+	h.mu.Lock()
+	long := h.TrackingService.LongSessions(h.Projects, now, h.Config.MaxSession)
+	h.mu.Unlock()
+
+	for _, projectName := range long {
+		h.resolveLongSession(projectName, now)
+	}
+
+	h.RestoreTimers()
+}
+
+// RestoreTimers - восстановление таймеров в трее, помидоров и напоминаний
+// для запущенных сессий без вопросов в терминале
+func (h *Handlers) RestoreTimers() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
 
-	project = h.Projects[projectName]
-	h.SystrayHandler.SetTracking(projectName, project.StartTime)
+	// Напоминания о перерыве приходят по времени работы от начала сессии
+	h.TrackingService.SyncReminders(h.Projects)
 
-	go func() {
-		time.Sleep(time.Duration(h.Config.NotificationTime) * time.Second)
-		h.Logger.Infof("Отправка уведомления о перерыве для проекта: %s", projectName)
-		h.TrackingService.Notify(projectName)
-	}()
+	for _, projectName := range h.TrackingService.ActiveProjects(h.Projects) {
+		project := h.Projects[projectName]
+		h.Logger.Infof("Восстановлена запущенная сессия проекта %s (с %s)", projectName, project.StartTime.Format(time.RFC3339))
+		fmt.Printf("Продолжается отслеживание проекта %s: %s\n", projectName, h.FormatDuration(project.Elapsed(now)))
+
+		h.SystrayHandler.SetTracking(projectName, project.StartTime)
+		h.SystrayHandler.SetPaused(projectName, project.PausedAt, domain.BreaksDuration(project.Breaks))
+
+		// Прерванный помидор начинается заново
+		if project.PausedAt == nil {
+			h.startPomodoro(projectName)
+		}
+	}
+}
+
+// resolveLongSession - выбор действия для сессии, оставленной запущенной слишком долго
+func (h *Handlers) resolveLongSession(projectName string, now time.Time) {
+	h.mu.Lock()
+	project, ok := h.Projects[projectName]
+	if !ok || project.StartTime == nil {
+		h.mu.Unlock()
+		return
+	}
+	elapsed, start := project.Elapsed(now), *project.StartTime
+	h.mu.Unlock()
+
+	capLabel := fmt.Sprintf("Ограничить до %s и остановить", h.FormatDuration(h.Config.MaxSession))
+	prompt := promptui.Select{
+		Label: fmt.Sprintf("Отслеживание проекта %s идет %s (с %s)",
+			projectName, h.FormatDuration(elapsed), start.Local().Format("2006-01-02 15:04")),
+		Items: []string{
+			"Оставить как есть",
+			capLabel,
+			"Отменить сессию",
+		},
+	}
+
+	_, choice, err := prompt.Run()
+	if err != nil {
+		return
+	}
+
+	switch choice {
+	case capLabel:
+		descPrompt := promptui.Prompt{Label: "Что сделано"}
+		description, _ := descPrompt.Run()
+
+		var spent time.Duration
+		err := h.WithProjects(func(projects map[string]*domain.Project) error {
+			var err error
+			spent, err = h.TrackingService.CapTracking(projects, projectName, description, h.Config.MaxSession)
+			return err
+		})
+		if err != nil {
+			h.Logger.Errorf("Ошибка остановки сессии: %v", err)
+			fmt.Printf("Ошибка: %v\n", err)
+			return
+		}
+		fmt.Printf("Отслеживание остановлено для проекта %s. Время: %s\n", projectName, h.FormatDuration(spent))
+
+	case "Отменить сессию":
+		err := h.WithProjects(func(projects map[string]*domain.Project) error {
+			return h.TrackingService.DiscardTracking(projects, projectName)
+		})
+		if err != nil {
+			h.Logger.Errorf("Ошибка отмены сессии: %v", err)
+			fmt.Printf("Ошибка: %v\n", err)
+			return
+		}
+		fmt.Printf("Сессия проекта %s отменена\n", projectName)
+	}
 }
 
 // StopTrackingForProject - остановка отслеживания времени для конкретного проекта
 func (h *Handlers) StopTrackingForProject(projectName string) {
-	project := h.Projects[projectName]
-
 	// Проверяем, запущено ли отслеживание для этого проекта
-	if project.StartTime == nil {
+	info, err := h.projectInfo(projectName)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if !info.Running {
 		fmt.Printf("Отслеживание для проекта '%s' не запущено\n", projectName)
 		return
 	}
 
+	// Время отсутствия нужно учесть до создания записи
+	h.ResolveIdleSpans()
+
 	prompt := promptui.Prompt{
 		Label: "Что сделано",
 	}
 	description, _ := prompt.Run()
 
 	h.Logger.Infof("Попытка остановить отслеживание для проекта: %s", projectName)
-	elapsed, err := h.TrackingService.StopTracking(h.Projects, projectName, description)
+	elapsed, err := h.backend().Stop(projectName, description)
 	if err != nil {
-		h.Logger.Errorf("Ошибка остановки отслеживания: %v", err)
 		fmt.Println(err)
 		return
 	}
 
-	h.Logger.Infof("Отслеживание остановлено для проекта %s. Время: %v", projectName, elapsed)
 	fmt.Printf("Отслеживание остановлено для проекта %s. Время: %s\n", projectName, h.FormatDuration(elapsed))
-	h.SystrayHandler.SetTracking("", nil)
+}
+
+// PauseTrackingForProject - приостановка отслеживания для конкретного проекта
+func (h *Handlers) PauseTrackingForProject(projectName string) {
+	if err := h.backend().Pause(projectName); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	sessions, err := h.backend().Status()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, session := range sessions {
+		if session.Project == projectName {
+			fmt.Printf("Отслеживание приостановлено для проекта %s. Отработано: %s\n", projectName, h.FormatDuration(session.Elapsed))
+		}
+	}
+}
+
+// ResumeTrackingForProject - продолжение отслеживания для конкретного проекта
+func (h *Handlers) ResumeTrackingForProject(projectName string) {
+	pause, err := h.backend().Resume(projectName)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Printf("Отслеживание продолжено для проекта %s. Перерыв: %s\n", projectName, h.FormatDuration(pause))
 }
 
 // FormatTimeSpent - форматирует время в виде "Xh Ym Zs"
@@ -101,25 +239,40 @@ func (h *Handlers) FormatDuration(duration time.Duration) string {
 	return h.FormatTimeSpent(seconds)
 }
 
+// FormatEntryPeriod - форматирует время начала и окончания записи в локальном часовом поясе
+func (h *Handlers) FormatEntryPeriod(entry domain.TimeEntry) string {
+	start := entry.Start.Local()
+	end := entry.End.Local()
+
+	if start.Format("2006-01-02") == end.Format("2006-01-02") {
+		return fmt.Sprintf("%s–%s", start.Format("2006-01-02 15:04"), end.Format("15:04"))
+	}
+
+	return fmt.Sprintf("%s – %s", start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04"))
+}
+
 // ShowSummary - вывод сводки по проектам
 func (h *Handlers) ShowSummary() {
 	h.Logger.Debug("Отображение сводки по проектам")
 
-	if len(h.Projects) == 0 {
+	projects, err := h.backend().List(true)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+	if len(projects) == 0 {
 		h.Logger.Debug("Нет данных для отображения")
 		fmt.Println("Нет данных для отображения.")
 		return
 	}
 
 	// Разделяем проекты на активные и архивные
-	activeProjects := make(map[string]*domain.Project)
-	archivedProjects := make(map[string]*domain.Project)
-
-	for name, project := range h.Projects {
+	var activeProjects, archivedProjects []string
+	for _, project := range projects {
 		if project.Archived {
-			archivedProjects[name] = project
+			archivedProjects = append(archivedProjects, project.Name)
 		} else {
-			activeProjects[name] = project
+			activeProjects = append(activeProjects, project.Name)
 		}
 	}
 
@@ -129,42 +282,22 @@ func (h *Handlers) ShowSummary() {
 	// Выводим активные проекты
 	if len(activeProjects) > 0 {
 		fmt.Println("\nАктивные проекты:")
-		for name, project := range activeProjects {
-			fmt.Printf("\nПроект \"%s\":\n", name)
-
-			// Общее время по проекту
-			var totalProject int
-			for _, entry := range project.Entries {
-				totalProject += entry.TimeSpent
+		for _, name := range activeProjects {
+			entries, err := h.projectEntries(name)
+			if err != nil {
+				fmt.Printf("Ошибка: %v\n", err)
+				continue
 			}
 
-			fmt.Printf("  Общее время: %s\n", h.FormatTimeSpent(totalProject))
-
-			// Если есть спринты, показываем статистику по ним
-			if project.Sprints != nil && len(project.Sprints) > 0 {
-				fmt.Println("  Спринты:")
-
-				sprints, _ := h.ProjectService.GetProjectSprints(h.Projects, name)
-				for _, sprint := range sprints {
-					var totalSprint int
-					for _, entry := range sprint.Entries {
-						totalSprint += entry.TimeSpent
-					}
-
-					status := ""
-					if sprint.IsActive {
-						status = " (Активный)"
-					}
-
-					fmt.Printf("    %s%s: %s\n", sprint.Name, status, h.FormatTimeSpent(totalSprint))
-				}
-			}
+			fmt.Printf("\nПроект \"%s\":\n", name)
+			fmt.Printf("  Общее время: %s\n", h.FormatDuration(entriesDuration(entries)))
+			h.printSprintsSpent(name)
 
 			// Показываем записи проекта
-			if len(project.Entries) > 0 {
+			if len(entries) > 0 {
 				fmt.Println("  Записи:")
-				for _, entry := range project.Entries {
-					fmt.Printf("    %s - %s: %s\n", entry.Date, h.FormatTimeSpent(entry.TimeSpent), entry.Description)
+				for _, entry := range entries {
+					fmt.Printf("    %s - %s: %s\n", h.FormatEntryPeriod(entry), h.FormatDuration(entry.Duration()), entry.Description)
 				}
 			}
 		}
@@ -174,13 +307,18 @@ func (h *Handlers) ShowSummary() {
 	if len(archivedProjects) > 0 {
 		h.Logger.Debug("Отображение архивных проектов")
 		fmt.Println("\nАрхивные проекты:")
-		for name, project := range archivedProjects {
-			var totalProject int
-			for _, entry := range project.Entries {
-				totalProject += entry.TimeSpent
+		for _, name := range archivedProjects {
+			entries, err := h.projectEntries(name)
+			if err != nil {
+				fmt.Printf("Ошибка: %v\n", err)
+				continue
 			}
-
-			fmt.Printf("  %s: %s\n", name, h.FormatTimeSpent(totalProject))
+			fmt.Printf("  %s: %s\n", name, h.FormatDuration(entriesDuration(entries)))
 		}
 	}
+
+	// Циклы помидоров при запущенном демоне считает он
+	if h.Pomodoro != nil && h.Remote == nil {
+		fmt.Printf("\nПомидоров за сегодня: %d\n", h.Pomodoro.CyclesToday())
+	}
 }
@@ -2,32 +2,80 @@ package systray
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/MWT-proger/time-tracking/pkg/logger"
+	"github.com/MWT-proger/time-tracking/pkg/pomodoro"
 	"github.com/getlantern/systray"
 )
 
+// Timer - запущенное отслеживание проекта, отображаемое в трее
+type Timer struct {
+	Start          time.Time
+	PausedAt       *time.Time
+	BreaksDuration time.Duration
+
+	// Этап помидора и время его окончания для обратного отсчета
+	PomodoroPhase pomodoro.Phase
+	PomodoroEnd   *time.Time
+}
+
+// Elapsed - время работы без учета перерывов
+func (t *Timer) Elapsed(now time.Time) time.Duration {
+	if t.PausedAt != nil {
+		now = *t.PausedAt
+	}
+
+	return now.Sub(t.Start) - t.BreaksDuration
+}
+
 // SystrayHandler - обработчик системного трея
 type SystrayHandler struct {
 	mu               sync.RWMutex
-	TrackedProject   string
-	TrackingStart    *time.Time
+	Timers           map[string]*Timer
+	MultiTimer       bool
 	UpdateTrayTicker *time.Ticker
 	Logger           logger.Logger
-	StartTracking    func()
+	StartProject     func(project string)
 	StopTracking     func()
+	StopProject      func(project string)
+	PauseTracking    func()
+	ResumeTracking   func()
 	OnExit           func()
+
+	mPause  *systray.MenuItem
+	mResume *systray.MenuItem
+
+	// Пункты остановки отдельных таймеров в режиме нескольких таймеров.
+	// Пункты трея нельзя удалить, поэтому лишние скрываются и переиспользуются.
+	mTimers    *systray.MenuItem
+	timerItems []*timerItem
+
+	// Проекты для запуска отслеживания и их пункты в подменю «Начать отслеживание»
+	projects     []string
+	mStart       *systray.MenuItem
+	projectItems []*timerItem
+}
+
+// timerItem - пункт подменю, относящийся к проекту
+type timerItem struct {
+	item    *systray.MenuItem
+	project string
 }
 
-// NewSystrayHandler - создание нового обработчика системного трея
+// NewSystrayHandler - создание нового обработчика системного трея.
+// multiTimer - отображать все запущенные таймеры с отдельными пунктами остановки.
 func NewSystrayHandler(
 	log logger.Logger,
-
+	multiTimer bool,
 ) *SystrayHandler {
 	return &SystrayHandler{
-		Logger: log,
+		Logger:     log,
+		MultiTimer: multiTimer,
+		Timers:     make(map[string]*Timer),
 	}
 }
 
@@ -62,21 +110,67 @@ func (h *SystrayHandler) StartTrayTicker() {
 					}
 				}()
 
-				h.mu.RLock()
-				trackingStart := h.TrackingStart
-				trackedProject := h.TrackedProject
-				h.mu.RUnlock()
-
-				if trackingStart != nil {
-					elapsed := time.Since(*trackingStart)
-					title := fmt.Sprintf("%s: %v", trackedProject, elapsed.Round(time.Second))
-					systray.SetTitle(title)
-				}
+				h.updateTitles(time.Now())
 			}()
 		}
 	}()
 }
 
+// timerTitle - надпись таймера проекта
+func timerTitle(project string, timer *Timer, now time.Time) string {
+	title := fmt.Sprintf("%s: %v", project, timer.Elapsed(now).Round(time.Second))
+	if timer.PausedAt != nil && !timer.PomodoroPhase.IsBreak() {
+		title += " (пауза)"
+	}
+
+	if timer.PomodoroEnd != nil {
+		icon := "🍅"
+		if timer.PomodoroPhase.IsBreak() {
+			icon = "☕"
+		}
+		left := timer.PomodoroEnd.Sub(now).Round(time.Second)
+		if left < 0 {
+			left = 0
+		}
+		title += fmt.Sprintf(" %s %02d:%02d", icon, int(left.Minutes()), int(left.Seconds())%60)
+	}
+
+	return title
+}
+
+// updateTitles - обновление заголовка трея и пунктов таймеров
+func (h *SystrayHandler) updateTitles(now time.Time) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.Timers) == 0 {
+		return
+	}
+
+	var titles []string
+	for _, project := range h.sortedProjects() {
+		titles = append(titles, timerTitle(project, h.Timers[project], now))
+	}
+	systray.SetTitle(strings.Join(titles, " | "))
+
+	for _, ti := range h.timerItems {
+		if timer, ok := h.Timers[ti.project]; ok {
+			ti.item.SetTitle(timerTitle(ti.project, timer, now))
+		}
+	}
+}
+
+// sortedProjects - проекты с запущенными таймерами по алфавиту, вызывается под h.mu
+func (h *SystrayHandler) sortedProjects() []string {
+	projects := make([]string, 0, len(h.Timers))
+	for project := range h.Timers {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+
+	return projects
+}
+
 // StopTrayTicker - остановка тикера обновления системного трея
 func (h *SystrayHandler) StopTrayTicker() {
 	h.mu.Lock()
@@ -95,22 +189,198 @@ func (h *SystrayHandler) StopTrayTicker() {
 	systray.SetTitle("Таймер")
 }
 
-// SetTracking - установка отслеживаемого проекта
+// SetTracking - установка таймера проекта, start == nil убирает таймер из трея
 func (h *SystrayHandler) SetTracking(project string, start *time.Time) {
 	h.mu.Lock()
-	wasTracking := h.TrackingStart != nil
-	h.TrackedProject = project
-	h.TrackingStart = start
+	if start != nil {
+		h.Timers[project] = &Timer{Start: *start}
+	} else {
+		delete(h.Timers, project)
+	}
+	tracking := len(h.Timers) > 0
 	tickerExists := h.UpdateTrayTicker != nil
 	h.mu.Unlock()
 
-	if start != nil {
+	h.updatePauseItems()
+	h.updateTimerItems()
+	h.updateProjectItems()
+
+	if tracking {
 		if !tickerExists {
 			h.StartTrayTicker()
 		}
+	} else if tickerExists {
+		h.StopTrayTicker()
+	}
+}
+
+// SetPaused - установка состояния паузы таймера проекта.
+// breaks - суммарная длительность завершенных перерывов сессии.
+func (h *SystrayHandler) SetPaused(project string, pausedAt *time.Time, breaks time.Duration) {
+	h.mu.Lock()
+	if timer, ok := h.Timers[project]; ok {
+		timer.PausedAt = pausedAt
+		timer.BreaksDuration = breaks
+	}
+	h.mu.Unlock()
+
+	h.updatePauseItems()
+}
+
+// SetPomodoro - установка этапа помидора проекта для обратного отсчета,
+// end == nil убирает отсчет
+func (h *SystrayHandler) SetPomodoro(project string, phase pomodoro.Phase, end *time.Time) {
+	h.mu.Lock()
+	if timer, ok := h.Timers[project]; ok {
+		timer.PomodoroPhase = phase
+		timer.PomodoroEnd = end
+	}
+	h.mu.Unlock()
+}
+
+// updatePauseItems - доступность пунктов паузы в зависимости от состояния
+func (h *SystrayHandler) updatePauseItems() {
+	h.mu.RLock()
+	running, paused := false, false
+	for _, timer := range h.Timers {
+		if timer.PausedAt != nil {
+			paused = true
+		} else {
+			running = true
+		}
+	}
+	mPause, mResume := h.mPause, h.mResume
+	h.mu.RUnlock()
+
+	if mPause == nil || mResume == nil {
+		return
+	}
+
+	if running {
+		mPause.Enable()
+	} else {
+		mPause.Disable()
+	}
+
+	if paused {
+		mResume.Enable()
 	} else {
-		if wasTracking {
-			h.StopTrayTicker()
+		mResume.Disable()
+	}
+}
+
+// updateTimerItems - пункты остановки для каждого запущенного таймера
+func (h *SystrayHandler) updateTimerItems() {
+	h.mu.Lock()
+	if h.mTimers == nil {
+		h.mu.Unlock()
+		return
+	}
+
+	projects := h.sortedProjects()
+	var created []*timerItem
+	for i, project := range projects {
+		if i == len(h.timerItems) {
+			ti := &timerItem{item: h.mTimers.AddSubMenuItem(project, "Остановить отслеживание проекта")}
+			h.timerItems = append(h.timerItems, ti)
+			created = append(created, ti)
+		}
+		ti := h.timerItems[i]
+		ti.project = project
+		ti.item.SetTitle(timerTitle(project, h.Timers[project], time.Now()))
+		ti.item.Show()
+	}
+	for _, ti := range h.timerItems[len(projects):] {
+		ti.project = ""
+		ti.item.Hide()
+	}
+
+	if len(projects) > 0 {
+		h.mTimers.Enable()
+	} else {
+		h.mTimers.Disable()
+	}
+	h.mu.Unlock()
+
+	for _, ti := range created {
+		go h.handleTimerItem(ti)
+	}
+}
+
+// SetProjects - проекты, отслеживание которых можно начать из трея
+func (h *SystrayHandler) SetProjects(projects []string) {
+	h.mu.Lock()
+	h.projects = append([]string(nil), projects...)
+	h.mu.Unlock()
+
+	h.updateProjectItems()
+}
+
+// updateProjectItems - пункты запуска проектов; проекты с запущенным
+// таймером недоступны
+func (h *SystrayHandler) updateProjectItems() {
+	h.mu.Lock()
+	if h.mStart == nil {
+		h.mu.Unlock()
+		return
+	}
+
+	var created []*timerItem
+	for i, project := range h.projects {
+		if i == len(h.projectItems) {
+			ti := &timerItem{item: h.mStart.AddSubMenuItem(project, "Начать отслеживание проекта")}
+			h.projectItems = append(h.projectItems, ti)
+			created = append(created, ti)
+		}
+		ti := h.projectItems[i]
+		ti.project = project
+		ti.item.SetTitle(project)
+		if _, running := h.Timers[project]; running {
+			ti.item.Disable()
+		} else {
+			ti.item.Enable()
+		}
+		ti.item.Show()
+	}
+	for _, ti := range h.projectItems[len(h.projects):] {
+		ti.project = ""
+		ti.item.Hide()
+	}
+
+	if len(h.projects) > 0 {
+		h.mStart.Enable()
+	} else {
+		h.mStart.Disable()
+	}
+	h.mu.Unlock()
+
+	for _, ti := range created {
+		go h.handleProjectItem(ti)
+	}
+}
+
+// handleProjectItem - начало отслеживания по нажатию на пункт проекта
+func (h *SystrayHandler) handleProjectItem(ti *timerItem) {
+	for range ti.item.ClickedCh {
+		h.mu.RLock()
+		project := ti.project
+		h.mu.RUnlock()
+
+		if project != "" && h.StartProject != nil {
+			h.StartProject(project)
+		}
+	}
+}
+
+// handleTimerItem - остановка проекта по нажатию на пункт его таймера
+func (h *SystrayHandler) handleTimerItem(ti *timerItem) {
+	for range ti.item.ClickedCh {
+		h.mu.RLock()
+		project := ti.project
+		h.mu.RUnlock()
+
+		if project != "" && h.StopProject != nil {
+			h.StopProject(project)
 		}
 	}
 }
@@ -141,23 +411,42 @@ func (h *SystrayHandler) onReady() {
 	systray.SetTooltip("Учет времени")
 
 	// Создаем пункты меню
-	mStart := systray.AddMenuItem("Начать отслеживание", "Начать отслеживание времени")
+	mStart := systray.AddMenuItem("Начать отслеживание", "Выбрать проект для отслеживания времени")
 	mStop := systray.AddMenuItem("Остановить отслеживание", "Остановить отслеживание времени")
+	mPause := systray.AddMenuItem("Пауза", "Приостановить отслеживание времени")
+	mResume := systray.AddMenuItem("Продолжить", "Продолжить отслеживание времени")
+	var mTimers *systray.MenuItem
+	if h.MultiTimer {
+		mTimers = systray.AddMenuItem("Запущенные таймеры", "Остановить отслеживание отдельного проекта")
+	}
 	systray.AddSeparator()
 	mQuit := systray.AddMenuItem("Выход", "Выход из приложения")
 
+	h.mu.Lock()
+	h.mPause, h.mResume = mPause, mResume
+	h.mTimers = mTimers
+	h.mStart = mStart
+	h.mu.Unlock()
+	h.updatePauseItems()
+	h.updateTimerItems()
+	h.updateProjectItems()
+
 	// Обработка событий меню
 	go func() {
 		for {
 			select {
-			case <-mStart.ClickedCh:
-				if h.StartTracking != nil {
-					h.StartTracking()
-				}
 			case <-mStop.ClickedCh:
 				if h.StopTracking != nil {
 					h.StopTracking()
 				}
+			case <-mPause.ClickedCh:
+				if h.PauseTracking != nil {
+					h.PauseTracking()
+				}
+			case <-mResume.ClickedCh:
+				if h.ResumeTracking != nil {
+					h.ResumeTracking()
+				}
 			case <-mQuit.ClickedCh:
 				h.Logger.Info("Выход из приложения через системный трей")
 				systray.Quit()
@@ -0,0 +1,640 @@
+// Package tui - полноэкранный интерфейс терминала: список проектов,
+// запущенные таймеры и записи выбранного проекта с действиями по клавишам
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
+	"github.com/MWT-proger/time-tracking/internal/app/handlers"
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// refreshInterval - период перечитывания состояния, которое могли изменить
+// трей, демон или другие программы
+const refreshInterval = 5 * time.Second
+
+// Data - данные приложения в процессе интерфейса
+type Data interface {
+	ReloadChanged() (bool, error)
+	UpdateTrayProjects()
+}
+
+// pane - панель, к которой относятся клавиши перемещения
+type pane int
+
+const (
+	paneProjects pane = iota
+	paneEntries
+)
+
+// mode - текущий ввод: клавиши действий, строка ввода или подтверждение
+type mode int
+
+const (
+	modeNormal mode = iota
+	modeStop
+	modeCreate
+	modeEdit
+	modeDelete
+)
+
+// Поля формы изменения записи
+const (
+	fieldDescription = iota
+	fieldStart
+	fieldDuration
+)
+
+// tickMsg - ежесекундное обновление таймеров
+type tickMsg time.Time
+
+// Model - состояние интерфейса: отслеживание, проекты и записи
+// читаются и изменяются через Backend
+type Model struct {
+	Backend daemon.Backend
+	Data    Data
+
+	projects     []daemon.ProjectInfo
+	sessions     []daemon.Session
+	loadedAt     time.Time
+	showArchived bool
+	cursor       int
+	focus        pane
+
+	// Записи выбранного проекта, новые сверху, и названия его спринтов
+	entriesOf string
+	entries   []domain.TimeEntry
+	sprints   map[string]string
+	table     table.Model
+
+	mode    mode
+	inputs  []textinput.Model
+	field   int
+	editing domain.TimeEntry
+
+	message string
+	failed  bool
+
+	width  int
+	height int
+}
+
+// New - создание интерфейса с загрузкой данных
+func New(backend daemon.Backend, data Data) *Model {
+	m := &Model{
+		Backend: backend,
+		Data:    data,
+		table:   table.New(table.WithStyles(tableStyles())),
+	}
+
+	// Строки таблицы отображаются по колонкам, поэтому колонки задаются до загрузки
+	m.resize()
+	m.refresh()
+	return m
+}
+
+// Init - запуск ежесекундного обновления
+func (m *Model) Init() tea.Cmd {
+	return tick()
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// Update - обработка клавиш, размера окна и таймера
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.resize()
+		return m, nil
+
+	case tickMsg:
+		// Во время ввода данные не перечитываются, чтобы не сдвинуть выбранную запись
+		if m.mode == modeNormal && time.Since(m.loadedAt) >= refreshInterval {
+			m.refresh()
+		}
+		return m, tick()
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+
+		switch m.mode {
+		case modeNormal:
+			return m.updateNormal(msg)
+		case modeDelete:
+			return m.updateDelete(msg)
+		default:
+			return m.updateInput(msg)
+		}
+	}
+
+	return m, nil
+}
+
+// updateNormal - клавиши действий и перемещения по панелям
+func (m *Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "tab":
+		m.switchFocus()
+		return m, nil
+	case "r":
+		m.clearMessage()
+		m.refresh()
+		return m, nil
+	case "a":
+		m.showArchived = !m.showArchived
+		m.refresh()
+		return m, nil
+	case "s":
+		m.start()
+		return m, nil
+	case "x":
+		return m, m.beginStop()
+	case "p":
+		m.togglePause()
+		return m, nil
+	case "n":
+		return m, m.beginCreate()
+	case "e":
+		return m, m.beginEdit()
+	case "d":
+		m.beginDelete()
+		return m, nil
+	}
+
+	if m.focus == paneEntries {
+		var cmd tea.Cmd
+		m.table, cmd = m.table.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		m.moveCursor(-1)
+	case "down", "j":
+		m.moveCursor(1)
+	case "home", "g":
+		m.moveCursor(-len(m.projects))
+	case "end", "G":
+		m.moveCursor(len(m.projects))
+	case "enter", "right", "l":
+		m.switchFocus()
+	}
+
+	return m, nil
+}
+
+// updateInput - строка ввода или форма: enter - применить, esc - отмена
+func (m *Model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.closeInput()
+		return m, nil
+	case "enter":
+		m.submit()
+		return m, nil
+	case "tab", "down":
+		return m, m.focusField(m.field + 1)
+	case "shift+tab", "up":
+		return m, m.focusField(m.field - 1)
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.field], cmd = m.inputs[m.field].Update(msg)
+	return m, cmd
+}
+
+// updateDelete - подтверждение удаления записи
+func (m *Model) updateDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "enter":
+		m.mode = modeNormal
+		m.deleteEntry()
+	case "n", "esc":
+		m.mode = modeNormal
+		m.clearMessage()
+	}
+
+	return m, nil
+}
+
+// --- Данные ---
+
+// refresh - перечитывание проектов, таймеров и записей выбранного проекта
+func (m *Model) refresh() {
+	reloaded, err := m.Data.ReloadChanged()
+	if err != nil {
+		m.fail(fmt.Errorf("данные изменены другой программой, но не могут быть перезагружены: %w", err))
+	} else if reloaded {
+		m.Data.UpdateTrayProjects()
+	}
+
+	selected := ""
+	if project, ok := m.selected(); ok {
+		selected = project.Name
+	}
+
+	projects, err := m.Backend.List(m.showArchived)
+	if err != nil {
+		m.fail(err)
+		return
+	}
+	sessions, err := m.Backend.Status()
+	if err != nil {
+		m.fail(err)
+		return
+	}
+
+	m.projects, m.sessions = projects, sessions
+	m.loadedAt = time.Now()
+	m.selectProject(selected)
+	m.loadEntries()
+}
+
+// selectProject - курсор на проекте с именем name, если он есть в списке
+func (m *Model) selectProject(name string) {
+	for i, project := range m.projects {
+		if project.Name == name {
+			m.cursor = i
+			return
+		}
+	}
+
+	m.cursor = min(m.cursor, max(len(m.projects)-1, 0))
+}
+
+// loadEntries - записи выбранного проекта в таблицу
+func (m *Model) loadEntries() {
+	project, ok := m.selected()
+	if !ok {
+		m.entriesOf, m.entries, m.sprints = "", nil, nil
+		m.table.SetRows(nil)
+		return
+	}
+
+	var entries []domain.TimeEntry
+	sprints := make(map[string]string)
+	items, err := m.Backend.Entries(project.Name)
+	if err != nil {
+		m.fail(err)
+	}
+	for _, item := range items {
+		entries = append(entries, item.TimeEntry)
+		sprints[item.SprintID] = item.Sprint
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	rows := make([]table.Row, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, table.Row{
+			entry.Start.Local().Format("02.01.2006"),
+			entry.Start.Local().Format("15:04") + "–" + entry.End.Local().Format("15:04"),
+			formatDuration(entry.Duration()),
+			sprints[entry.SprintID],
+			entry.Description,
+		})
+	}
+
+	cursor := m.table.Cursor()
+	if m.entriesOf != project.Name {
+		cursor = 0
+	}
+
+	m.entriesOf, m.entries, m.sprints = project.Name, entries, sprints
+	m.table.SetRows(rows)
+	m.table.SetCursor(min(cursor, max(len(rows)-1, 0)))
+}
+
+// selected - выбранный проект
+func (m *Model) selected() (daemon.ProjectInfo, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.projects) {
+		return daemon.ProjectInfo{}, false
+	}
+
+	return m.projects[m.cursor], true
+}
+
+// selectedEntry - выбранная запись
+func (m *Model) selectedEntry() (domain.TimeEntry, bool) {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.entries) {
+		return domain.TimeEntry{}, false
+	}
+
+	return m.entries[cursor], true
+}
+
+// session - запущенное отслеживание проекта
+func (m *Model) session(projectName string) (daemon.Session, bool) {
+	for _, session := range m.sessions {
+		if session.Project == projectName {
+			return session, true
+		}
+	}
+
+	return daemon.Session{}, false
+}
+
+// elapsed - время отслеживания на текущий момент без запроса к Backend
+func (m *Model) elapsed(session daemon.Session) time.Duration {
+	if session.PausedAt != nil {
+		return session.Elapsed
+	}
+
+	return session.Elapsed + time.Since(m.loadedAt)
+}
+
+func (m *Model) moveCursor(delta int) {
+	if len(m.projects) == 0 {
+		return
+	}
+
+	m.cursor = min(max(m.cursor+delta, 0), len(m.projects)-1)
+	m.loadEntries()
+}
+
+func (m *Model) switchFocus() {
+	if m.focus == paneProjects {
+		m.focus = paneEntries
+		m.table.Focus()
+	} else {
+		m.focus = paneProjects
+		m.table.Blur()
+	}
+}
+
+// --- Действия ---
+
+// start - начало отслеживания выбранного проекта
+func (m *Model) start() {
+	project, ok := m.selected()
+	if !ok {
+		return
+	}
+	if project.Running {
+		m.fail(fmt.Errorf("отслеживание проекта '%s' уже запущено", project.Name))
+		return
+	}
+
+	stopped, err := m.Backend.Start(project.Name, "", "")
+	m.refresh()
+	if err != nil {
+		m.fail(err)
+		return
+	}
+
+	message := fmt.Sprintf("Начато отслеживание: %s", project.Name)
+	if len(stopped) > 0 {
+		message += fmt.Sprintf(" (остановлено: %s)", strings.Join(stopped, ", "))
+	}
+	m.inform(message)
+}
+
+// beginStop - запрос описания перед остановкой выбранного проекта
+func (m *Model) beginStop() tea.Cmd {
+	project, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	if !project.Running {
+		m.fail(fmt.Errorf("отслеживание проекта '%s' не запущено", project.Name))
+		return nil
+	}
+
+	return m.openInput(modeStop, newInput(""))
+}
+
+// togglePause - пауза или продолжение отслеживания выбранного проекта
+func (m *Model) togglePause() {
+	project, ok := m.selected()
+	if !ok {
+		return
+	}
+	if !project.Running {
+		m.fail(fmt.Errorf("отслеживание проекта '%s' не запущено", project.Name))
+		return
+	}
+
+	if project.Paused {
+		pause, err := m.Backend.Resume(project.Name)
+		m.refresh()
+		if err != nil {
+			m.fail(err)
+			return
+		}
+		m.inform(fmt.Sprintf("Отслеживание продолжено: %s (перерыв %s)", project.Name, formatDuration(pause)))
+		return
+	}
+
+	err := m.Backend.Pause(project.Name)
+	m.refresh()
+	if err != nil {
+		m.fail(err)
+		return
+	}
+	m.inform(fmt.Sprintf("Отслеживание приостановлено: %s", project.Name))
+}
+
+// beginCreate - запрос названия нового проекта
+func (m *Model) beginCreate() tea.Cmd {
+	return m.openInput(modeCreate, newInput(""))
+}
+
+// beginEdit - форма изменения выбранной записи
+func (m *Model) beginEdit() tea.Cmd {
+	entry, ok := m.selectedEntry()
+	if !ok {
+		m.fail(fmt.Errorf("нет выбранной записи"))
+		return nil
+	}
+
+	m.editing = entry
+	return m.openInput(modeEdit,
+		newInput(entry.Description),
+		newInput(entry.Start.Local().Format("2006-01-02 15:04")),
+		newInput(entry.Duration().Round(time.Second).String()),
+	)
+}
+
+// beginDelete - подтверждение удаления выбранной записи
+func (m *Model) beginDelete() {
+	entry, ok := m.selectedEntry()
+	if !ok {
+		m.fail(fmt.Errorf("нет выбранной записи"))
+		return
+	}
+
+	m.editing = entry
+	m.mode = modeDelete
+	m.clearMessage()
+}
+
+// submit - применение введенных значений
+func (m *Model) submit() {
+	switch m.mode {
+	case modeStop:
+		m.stop(m.inputs[0].Value())
+	case modeCreate:
+		m.createProject(strings.TrimSpace(m.inputs[0].Value()))
+	case modeEdit:
+		m.editEntry()
+	}
+}
+
+// stop - остановка отслеживания выбранного проекта с описанием
+func (m *Model) stop(description string) {
+	project, _ := m.selected()
+	m.closeInput()
+
+	elapsed, err := m.Backend.Stop(project.Name, description)
+	m.refresh()
+	if err != nil {
+		m.fail(err)
+		return
+	}
+
+	m.inform(fmt.Sprintf("Остановлено отслеживание %s: %s", project.Name, formatDuration(elapsed)))
+}
+
+// createProject - создание проекта с курсором на нем
+func (m *Model) createProject(name string) {
+	if name == "" {
+		m.fail(fmt.Errorf("название проекта не может быть пустым"))
+		return
+	}
+
+	if err := m.Backend.AddProject(name); err != nil {
+		m.fail(err)
+		return
+	}
+
+	m.closeInput()
+	m.Data.UpdateTrayProjects()
+	m.refresh()
+	m.selectProject(name)
+	m.loadEntries()
+	m.inform(fmt.Sprintf("Проект '%s' создан", name))
+}
+
+// editEntry - сохранение измененных полей формы; при ошибке форма остается открытой
+func (m *Model) editEntry() {
+	entry := m.editing
+	var edit daemon.EntryEdit
+
+	if value := m.inputs[fieldDescription].Value(); value != entry.Description {
+		edit.Description = &value
+	}
+
+	if value := m.inputs[fieldStart].Value(); value != entry.Start.Local().Format("2006-01-02 15:04") {
+		start, err := handlers.ParseUserTime(value, entry.Start)
+		if err != nil {
+			m.fail(err)
+			return
+		}
+		edit.Start = &start
+	}
+
+	if value := m.inputs[fieldDuration].Value(); value != entry.Duration().Round(time.Second).String() {
+		duration, err := handlers.ParseUserDuration(value)
+		if err != nil {
+			m.fail(err)
+			return
+		}
+		edit.Duration = &duration
+	}
+
+	if edit.Description == nil && edit.Start == nil && edit.Duration == nil {
+		m.closeInput()
+		return
+	}
+
+	if _, err := m.Backend.EditEntry(m.entriesOf, entry.ID, edit); err != nil {
+		m.fail(err)
+		return
+	}
+
+	m.closeInput()
+	m.loadEntries()
+	m.inform("Запись изменена")
+}
+
+// deleteEntry - удаление записи после подтверждения
+func (m *Model) deleteEntry() {
+	if err := m.Backend.DeleteEntry(m.entriesOf, m.editing.ID); err != nil {
+		m.fail(err)
+		return
+	}
+
+	m.loadEntries()
+	m.inform("Запись удалена")
+}
+
+// --- Ввод ---
+
+func newInput(value string) textinput.Model {
+	input := textinput.New()
+	input.Prompt = ""
+	input.SetValue(value)
+	return input
+}
+
+// openInput - переход к вводу в полях inputs с фокусом на первом
+func (m *Model) openInput(mode mode, inputs ...textinput.Model) tea.Cmd {
+	m.mode = mode
+	m.inputs = inputs
+	m.clearMessage()
+	m.resize()
+	return m.focusField(0)
+}
+
+func (m *Model) closeInput() {
+	m.mode = modeNormal
+	m.inputs = nil
+	m.field = 0
+	m.clearMessage()
+}
+
+// focusField - фокус на поле с номером field по кругу
+func (m *Model) focusField(field int) tea.Cmd {
+	m.field = (field + len(m.inputs)) % len(m.inputs)
+	for i := range m.inputs {
+		m.inputs[i].Blur()
+	}
+
+	return m.inputs[m.field].Focus()
+}
+
+func (m *Model) inform(message string) {
+	m.message, m.failed = message, false
+}
+
+func (m *Model) fail(err error) {
+	m.message, m.failed = err.Error(), true
+}
+
+func (m *Model) clearMessage() {
+	m.message, m.failed = "", false
+}
+
+// formatDuration - длительность в формате Ч:ММ:СС
+func formatDuration(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
@@ -0,0 +1,262 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MWT-proger/time-tracking/internal/app/daemon"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// projectsWidth - ширина списка проектов без рамки
+const projectsWidth = 28
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("230")).Background(lipgloss.Color("62")).Padding(0, 1)
+	paneStyle     = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240"))
+	focusedStyle  = paneStyle.BorderForeground(lipgloss.Color("62"))
+	selectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("230")).Background(lipgloss.Color("62"))
+	headerStyle   = lipgloss.NewStyle().Bold(true)
+	dimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+	runningStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	pausedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+	timerStyle    = lipgloss.NewStyle().Bold(true)
+)
+
+// help - подсказка по клавишам в обычном режиме
+const help = "↑↓ выбор  tab панель  s старт  x стоп  p пауза  e изменить  d удалить  n проект  a архив  r обновить  q выход"
+
+func tableStyles() table.Styles {
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true)
+	styles.Selected = selectedStyle
+	return styles
+}
+
+// bodyHeight - высота панелей без рамки: остальное занимают заголовок и две строки внизу
+func (m *Model) bodyHeight() int {
+	return max(m.height-5, 3)
+}
+
+// entriesWidth - ширина панели записей без рамки
+func (m *Model) entriesWidth() int {
+	return max(m.width-projectsWidth-4, 40)
+}
+
+// resize - размеры таблицы и полей ввода по размеру окна
+func (m *Model) resize() {
+	width := m.entriesWidth()
+
+	// У каждой ячейки отступы по одному символу слева и справа
+	fixed := []int{10, 11, 8, 12}
+	description := width - 2*(len(fixed)+1)
+	for _, w := range fixed {
+		description -= w
+	}
+
+	m.table.SetColumns([]table.Column{
+		{Title: "Дата", Width: fixed[0]},
+		{Title: "Время", Width: fixed[1]},
+		{Title: "Длит.", Width: fixed[2]},
+		{Title: "Спринт", Width: fixed[3]},
+		{Title: "Описание", Width: max(description, 10)},
+	})
+	m.table.SetWidth(width)
+
+	// Над таблицей строка таймера выбранного проекта и пустая строка
+	m.table.SetHeight(max(m.bodyHeight()-2, 3))
+
+	for i := range m.inputs {
+		m.inputs[i].Width = max(width-16, 10)
+	}
+}
+
+// View - заголовок с таймерами, панели проектов и записей, строка ввода и сообщение
+func (m *Model) View() string {
+	if m.width == 0 {
+		return "Загрузка..."
+	}
+
+	projects, entries := paneStyle, paneStyle
+	if m.focus == paneProjects {
+		projects = focusedStyle
+	} else {
+		entries = focusedStyle
+	}
+
+	height := m.bodyHeight()
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		projects.Width(projectsWidth).Height(height).MaxHeight(height+2).Render(m.projectsView(height)),
+		entries.Width(m.entriesWidth()).Height(height).MaxHeight(height+2).Render(m.entriesView()),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		m.headerView(),
+		body,
+		m.promptView(),
+		m.messageView(),
+	)
+}
+
+// headerView - название и все запущенные таймеры
+func (m *Model) headerView() string {
+	parts := []string{titleStyle.Render("Учет времени")}
+	for _, session := range m.sessions {
+		parts = append(parts, m.sessionView(session, session.Project+" "))
+	}
+	if len(m.sessions) == 0 {
+		parts = append(parts, dimStyle.Render("нет запущенных отслеживаний"))
+	}
+
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(strings.Join(parts, "  "))
+}
+
+// sessionView - состояние и время отслеживания
+func (m *Model) sessionView(session daemon.Session, prefix string) string {
+	text := prefix + formatDuration(m.elapsed(session))
+	if session.PausedAt != nil {
+		return pausedStyle.Render("⏸ " + text)
+	}
+
+	return runningStyle.Render("▶ " + text)
+}
+
+// projectsView - список проектов с прокруткой к выбранному
+func (m *Model) projectsView(height int) string {
+	if len(m.projects) == 0 {
+		return dimStyle.Render("Нет проектов (n - создать)")
+	}
+
+	offset := max(m.cursor-height+1, 0)
+	lines := make([]string, 0, height)
+	for i := offset; i < len(m.projects) && len(lines) < height; i++ {
+		project := m.projects[i]
+
+		marker := "  "
+		switch {
+		case project.Paused:
+			marker = "⏸ "
+		case project.Running:
+			marker = "▶ "
+		}
+
+		name := project.Name
+		if project.Archived {
+			name += " (архив)"
+		}
+		line := truncate(marker+name, projectsWidth)
+
+		switch {
+		case i == m.cursor && m.focus == paneProjects:
+			line = selectedStyle.Width(projectsWidth).Render(line)
+		case i == m.cursor:
+			line = headerStyle.Render(line)
+		case project.Archived:
+			line = dimStyle.Render(line)
+		case project.Paused:
+			line = pausedStyle.Render(line)
+		case project.Running:
+			line = runningStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// entriesView - таймер выбранного проекта и таблица его записей или форма изменения записи
+func (m *Model) entriesView() string {
+	project, ok := m.selected()
+	if !ok {
+		return ""
+	}
+
+	if m.mode == modeEdit {
+		return m.editView()
+	}
+
+	status := dimStyle.Render("не отслеживается")
+	if session, ok := m.session(project.Name); ok {
+		status = timerStyle.Render(m.sessionView(session, ""))
+		if session.Sprint != "" {
+			status += dimStyle.Render(" · спринт " + session.Sprint)
+		}
+		if session.Long {
+			status += errorStyle.Render(" · слишком долгая сессия")
+		}
+	}
+
+	header := headerStyle.Render(truncate(project.Name, m.entriesWidth()/2)) + "  " + status
+	if len(m.entries) == 0 {
+		return header + "\n\n" + dimStyle.Render("Нет записей")
+	}
+
+	return header + "\n\n" + m.table.View()
+}
+
+// editView - форма изменения записи
+func (m *Model) editView() string {
+	labels := []string{"Описание", "Начало", "Длительность"}
+	hints := []string{"", "ГГГГ-ММ-ДД ЧЧ:ММ или ЧЧ:ММ", "например, 1h30m или 90"}
+
+	lines := []string{
+		headerStyle.Render(fmt.Sprintf("Изменение записи от %s", m.editing.Start.Local().Format("02.01.2006 15:04"))),
+		"",
+	}
+	for i, input := range m.inputs {
+		lines = append(lines, fmt.Sprintf("%-14s %s", labels[i], input.View()))
+		if hints[i] != "" {
+			lines = append(lines, strings.Repeat(" ", 15)+dimStyle.Render(hints[i]))
+		}
+	}
+	lines = append(lines, "", dimStyle.Render("enter - сохранить, tab - следующее поле, esc - отмена"))
+
+	return strings.Join(lines, "\n")
+}
+
+// promptView - строка ввода, подтверждение или подсказка по клавишам
+func (m *Model) promptView() string {
+	switch m.mode {
+	case modeStop:
+		project, _ := m.selected()
+		return fmt.Sprintf("Что сделано в проекте %s: %s", project.Name, m.inputs[0].View())
+	case modeCreate:
+		return "Название нового проекта: " + m.inputs[0].View()
+	case modeDelete:
+		return fmt.Sprintf("Удалить запись от %s (%s)? y - да, n - нет",
+			m.editing.Start.Local().Format("02.01.2006 15:04"), formatDuration(m.editing.Duration()))
+	case modeEdit:
+		return ""
+	default:
+		return dimStyle.Render(truncate(help, m.width))
+	}
+}
+
+// messageView - результат последнего действия или ошибка
+func (m *Model) messageView() string {
+	if m.failed {
+		return errorStyle.Render(truncate("Ошибка: "+m.message, m.width))
+	}
+
+	return truncate(m.message, m.width)
+}
+
+// truncate - обрезка строки до ширины width с многоточием
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "…"
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/MWT-proger/time-tracking/internal/app/handlers"
+)
+
+// defaultWebAddr - адрес веб-интерфейса, если не задан флаг -http
+const defaultWebAddr = "127.0.0.1:8765"
+
+// RunWeb - работа демона с веб-интерфейсом: панель отдается вместе
+// с HTTP API и работает с данными через него
+func (a *App) RunWeb(args []string) int {
+	addr := a.Config.HTTPAddr
+	if addr == "" {
+		addr = defaultWebAddr
+	}
+
+	fs := flag.NewFlagSet("web", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.StringVar(&addr, "addr", addr, "Адрес веб-интерфейса и HTTP API")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Использование: web [--addr адрес]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return handlers.ExitUsage
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return handlers.ExitUsage
+	}
+
+	a.Config.HTTPAddr = addr
+	return a.serve(true)
+}
@@ -0,0 +1,558 @@
+'use strict';
+
+// Панель учета времени: все данные читаются и изменяются через HTTP API.
+// Токен API передается в адресе страницы (#token=...) и хранится в браузере.
+
+const tokenKey = 'time-tracking-token';
+const statusInterval = 15000;
+
+const state = {
+  token: '',
+  timers: [],
+  timersLoaded: 0,
+  projects: [],
+  sprints: [],
+  entries: [],
+};
+
+const $ = (id) => document.getElementById(id);
+
+// api - запрос к HTTP API, ответ с ошибкой превращается в исключение
+async function api(method, path, body) {
+  const options = { method, headers: { Authorization: 'Bearer ' + state.token } };
+  if (body !== undefined) {
+    options.headers['Content-Type'] = 'application/json';
+    options.body = JSON.stringify(body);
+  }
+
+  const resp = await fetch('/api' + path, options);
+  if (resp.status === 401) {
+    logout();
+    throw new Error('неверный токен');
+  }
+  if (resp.status === 204) {
+    return null;
+  }
+
+  const data = await resp.json();
+  if (!resp.ok) {
+    throw new Error(data.error || resp.statusText);
+  }
+  return data;
+}
+
+// projectPath - адрес проекта, имя может содержать любые символы
+function projectPath(name) {
+  return '/projects/' + encodeURIComponent(name);
+}
+
+function showError(err) {
+  const el = $('error');
+  el.textContent = err ? 'Ошибка: ' + err.message : '';
+  el.hidden = !err;
+}
+
+// guard - выполнение действия с выводом ошибки
+async function guard(fn) {
+  try {
+    showError(null);
+    await fn();
+  } catch (err) {
+    showError(err);
+  }
+}
+
+// --- Форматирование ---
+
+function pad(n) {
+  return String(n).padStart(2, '0');
+}
+
+// formatDuration - длительность в формате Ч:ММ:СС
+function formatDuration(total) {
+  total = Math.max(0, Math.floor(total));
+  return Math.floor(total / 3600) + ':' + pad(Math.floor(total / 60) % 60) + ':' + pad(total % 60);
+}
+
+// formatHours - длительность в формате Ч:ММ
+function formatHours(total) {
+  total = Math.max(0, Math.round(total / 60));
+  return Math.floor(total / 60) + ':' + pad(total % 60);
+}
+
+// parseHours - разбор длительности Ч:ММ в секунды
+function parseHours(value) {
+  const match = /^(\d+):([0-5]\d)$/.exec(value.trim());
+  if (!match) {
+    throw new Error('длительность нужно указать в формате Ч:ММ');
+  }
+  return Number(match[1]) * 3600 + Number(match[2]) * 60;
+}
+
+// dateKey - локальная дата в формате ГГГГ-ММ-ДД
+function dateKey(date) {
+  return date.getFullYear() + '-' + pad(date.getMonth() + 1) + '-' + pad(date.getDate());
+}
+
+// localInput - время для поля datetime-local
+function localInput(date) {
+  return dateKey(date) + 'T' + pad(date.getHours()) + ':' + pad(date.getMinutes());
+}
+
+function formatDateTime(date) {
+  return date.toLocaleString('ru-RU', { dateStyle: 'short', timeStyle: 'short' });
+}
+
+function startOfDay(date) {
+  return new Date(date.getFullYear(), date.getMonth(), date.getDate());
+}
+
+function addDays(date, days) {
+  return new Date(date.getFullYear(), date.getMonth(), date.getDate() + days);
+}
+
+// periodRange - границы периода графиков
+function periodRange(period) {
+  const today = startOfDay(new Date());
+  switch (period) {
+    case 'today':
+      return { from: today, to: today };
+    case 'month':
+      return { from: new Date(today.getFullYear(), today.getMonth(), 1), to: today };
+    case '30d':
+      return { from: addDays(today, -29), to: today };
+    case 'year':
+      return { from: addDays(today, -364), to: today };
+    default:
+      return { from: addDays(today, -((today.getDay() + 6) % 7)), to: today };
+  }
+}
+
+function report(from, to, groupBy) {
+  const query = new URLSearchParams({ from: dateKey(from), to: dateKey(to), group_by: groupBy });
+  return api('GET', '/report?' + query);
+}
+
+function option(value, label) {
+  const el = document.createElement('option');
+  el.value = value;
+  el.textContent = label;
+  return el;
+}
+
+function button(label, onClick, className) {
+  const el = document.createElement('button');
+  el.type = 'button';
+  el.textContent = label;
+  if (className) {
+    el.className = className;
+  }
+  el.addEventListener('click', () => guard(onClick));
+  return el;
+}
+
+// --- Отслеживание ---
+
+async function loadTimers() {
+  state.timers = await api('GET', '/status');
+  state.timersLoaded = Date.now();
+  renderTimers();
+}
+
+// elapsed - время отслеживания на текущий момент без запроса к серверу
+function elapsed(timer) {
+  if (timer.paused_at) {
+    return timer.elapsed_seconds;
+  }
+  return timer.elapsed_seconds + (Date.now() - state.timersLoaded) / 1000;
+}
+
+function renderTimers() {
+  const box = $('timers');
+  box.replaceChildren();
+
+  if (state.timers.length === 0) {
+    const empty = document.createElement('p');
+    empty.className = 'empty';
+    empty.textContent = 'Нет запущенных отслеживаний';
+    box.append(empty);
+  }
+
+  for (const timer of state.timers) {
+    const row = document.createElement('div');
+    row.className = 'timer' + (timer.paused_at ? ' paused' : '');
+
+    const name = document.createElement('span');
+    name.className = 'name';
+    name.textContent = timer.project;
+    if (timer.sprint) {
+      const sprint = document.createElement('span');
+      sprint.className = 'sprint';
+      sprint.textContent = ' · ' + timer.sprint;
+      name.append(sprint);
+    }
+
+    const time = document.createElement('span');
+    time.className = 'elapsed';
+    time.dataset.project = timer.project;
+    time.textContent = formatDuration(elapsed(timer));
+
+    const project = { project: timer.project };
+    const toggle = timer.paused_at
+      ? button('Продолжить', () => trackingAction('/resume', project))
+      : button('Пауза', () => trackingAction('/pause', project));
+    const stop = button('Стоп', () => {
+      const description = prompt('Описание выполненной работы', '');
+      if (description === null) {
+        return;
+      }
+      return trackingAction('/stop', { project: timer.project, description });
+    }, 'primary');
+
+    row.append(name, time, toggle, stop);
+    box.append(row);
+  }
+
+  renderStartForm();
+}
+
+function renderStartForm() {
+  const running = new Set(state.timers.map((timer) => timer.project));
+  const select = $('start-project');
+  const selected = select.value;
+  select.replaceChildren();
+
+  for (const project of state.projects) {
+    if (!project.archived && !running.has(project.name)) {
+      select.append(option(project.name, project.name));
+    }
+  }
+  if ([...select.options].some((el) => el.value === selected)) {
+    select.value = selected;
+  }
+
+  $('start-form').hidden = select.options.length === 0;
+}
+
+function tickTimers() {
+  for (const el of document.querySelectorAll('#timers .elapsed')) {
+    const timer = state.timers.find((t) => t.project === el.dataset.project);
+    if (timer) {
+      el.textContent = formatDuration(elapsed(timer));
+    }
+  }
+}
+
+async function trackingAction(path, body) {
+  await api('POST', path, body);
+  await loadTimers();
+  if (path === '/stop') {
+    await Promise.all([loadCharts(), loadHeatmap(), loadEntries()]);
+  }
+}
+
+// --- Графики ---
+
+// renderBars - горизонтальная диаграмма строк отчета
+function renderBars(el, rows, label) {
+  el.replaceChildren();
+
+  if (rows.length === 0) {
+    const empty = document.createElement('p');
+    empty.className = 'empty';
+    empty.textContent = 'Нет записей за период';
+    el.append(empty);
+    return;
+  }
+
+  rows = [...rows].sort((a, b) => b.seconds - a.seconds);
+  const max = rows[0].seconds || 1;
+
+  for (const row of rows) {
+    const line = document.createElement('div');
+    line.className = 'bar-row';
+
+    const name = document.createElement('span');
+    name.className = 'label';
+    name.textContent = name.title = label(row);
+
+    const bar = document.createElement('div');
+    bar.className = 'bar';
+    const fill = document.createElement('div');
+    fill.className = 'fill';
+    fill.style.width = (row.seconds / max) * 100 + '%';
+    bar.append(fill);
+
+    const value = document.createElement('span');
+    value.className = 'value';
+    value.textContent = formatHours(row.seconds);
+
+    line.append(name, bar, value);
+    el.append(line);
+  }
+}
+
+async function loadCharts() {
+  const { from, to } = periodRange($('period').value);
+  const [byProject, bySprint] = await Promise.all([
+    report(from, to, 'project'),
+    report(from, to, 'sprint'),
+  ]);
+
+  renderBars($('project-chart'), byProject.rows, (row) => row.project);
+  renderBars($('sprint-chart'), bySprint.rows, (row) => row.project + ' / ' + (row.sprint || 'без спринта'));
+  $('period-total').textContent = formatHours(byProject.total_seconds);
+}
+
+// --- Календарь ---
+
+const heatColors = ['#ebedf0', '#c6e48b', '#7bc96f', '#239a3b', '#196127'];
+const weekdays = ['Пн', '', 'Ср', '', 'Пт', '', ''];
+
+// loadHeatmap - календарь за последний год: неделя - столбец, день - клетка,
+// цвет - доля от самого загруженного дня
+async function loadHeatmap() {
+  const today = startOfDay(new Date());
+  const from = addDays(today, -364 - ((today.getDay() + 6) % 7));
+  const data = await report(from, today, 'day');
+
+  const days = new Map();
+  for (const row of data.rows) {
+    days.set(row.period, (days.get(row.period) || 0) + row.seconds);
+  }
+  const max = Math.max(0, ...days.values());
+
+  const cell = 12;
+  const gap = 3;
+  const left = 24;
+  const top = 16;
+  const weeks = Math.ceil((Math.round((today - from) / 86400000) + 1) / 7);
+
+  const ns = 'http://www.w3.org/2000/svg';
+  const svg = document.createElementNS(ns, 'svg');
+  svg.setAttribute('width', left + weeks * (cell + gap));
+  svg.setAttribute('height', top + 7 * (cell + gap));
+
+  weekdays.forEach((label, i) => {
+    if (!label) {
+      return;
+    }
+    const text = document.createElementNS(ns, 'text');
+    text.setAttribute('x', 0);
+    text.setAttribute('y', top + i * (cell + gap) + cell - 2);
+    text.textContent = label;
+    svg.append(text);
+  });
+
+  let month = -1;
+  for (let day = from, i = 0; day <= today; day = addDays(day, 1), i++) {
+    const week = Math.floor(i / 7);
+    const x = left + week * (cell + gap);
+
+    if (i % 7 === 0 && day.getMonth() !== month) {
+      month = day.getMonth();
+      const text = document.createElementNS(ns, 'text');
+      text.setAttribute('x', x);
+      text.setAttribute('y', top - 4);
+      text.textContent = day.toLocaleString('ru-RU', { month: 'short' });
+      svg.append(text);
+    }
+
+    const seconds = days.get(dateKey(day)) || 0;
+    const level = seconds === 0 ? 0 : Math.min(4, Math.ceil((seconds / max) * 4));
+
+    const rect = document.createElementNS(ns, 'rect');
+    rect.setAttribute('x', x);
+    rect.setAttribute('y', top + (i % 7) * (cell + gap));
+    rect.setAttribute('width', cell);
+    rect.setAttribute('height', cell);
+    rect.setAttribute('fill', heatColors[level]);
+
+    const title = document.createElementNS(ns, 'title');
+    title.textContent = day.toLocaleDateString('ru-RU') + ': ' + formatHours(seconds);
+    rect.append(title);
+    svg.append(rect);
+  }
+
+  $('heatmap').replaceChildren(svg);
+}
+
+// --- Записи ---
+
+async function loadProjects() {
+  state.projects = await api('GET', '/projects?all=true');
+
+  const select = $('entries-project');
+  const selected = select.value;
+  select.replaceChildren();
+  for (const project of state.projects) {
+    select.append(option(project.name, project.name + (project.archived ? ' (архив)' : '')));
+  }
+  if ([...select.options].some((el) => el.value === selected)) {
+    select.value = selected;
+  }
+
+  renderStartForm();
+}
+
+async function loadEntries() {
+  const projectName = $('entries-project').value;
+  if (!projectName) {
+    state.entries = [];
+    state.sprints = [];
+  } else {
+    [state.entries, state.sprints] = await Promise.all([
+      api('GET', projectPath(projectName) + '/entries'),
+      api('GET', projectPath(projectName) + '/sprints'),
+    ]);
+  }
+  renderEntries();
+}
+
+function sprintSelect(entry) {
+  const select = document.createElement('select');
+  select.append(option('', 'без спринта'));
+  for (const sprint of state.sprints) {
+    select.append(option(sprint.id, sprint.name));
+  }
+  select.value = entry.sprint_id || '';
+  return select;
+}
+
+// renderEntries - таблица записей проекта, новые сверху; каждая строка
+// редактируется на месте и сохраняется только с измененными полями
+function renderEntries() {
+  const body = $('entries').querySelector('tbody');
+  body.replaceChildren();
+
+  if (state.entries.length === 0) {
+    const row = body.insertRow();
+    const cell = row.insertCell();
+    cell.colSpan = 4;
+    cell.className = 'empty';
+    cell.textContent = 'Нет записей';
+    return;
+  }
+
+  const projectName = $('entries-project').value;
+  const entries = [...state.entries].sort((a, b) => new Date(b.start) - new Date(a.start));
+
+  for (const entry of entries) {
+    const row = body.insertRow();
+    const start = new Date(entry.start);
+
+    const startInput = document.createElement('input');
+    startInput.type = 'datetime-local';
+    startInput.value = localInput(start);
+    startInput.title = formatDateTime(start) + ' – ' + formatDateTime(new Date(entry.end));
+
+    const durationInput = document.createElement('input');
+    durationInput.value = formatHours(entry.duration_seconds);
+    durationInput.size = 6;
+
+    const descriptionInput = document.createElement('input');
+    descriptionInput.value = entry.description;
+
+    const sprint = sprintSelect(entry);
+    const description = document.createElement('div');
+    description.className = 'row';
+    description.append(descriptionInput, sprint);
+
+    const path = projectPath(projectName) + '/entries/' + encodeURIComponent(entry.id);
+    const save = button('Сохранить', async () => {
+      const changes = {};
+      if (descriptionInput.value !== entry.description) {
+        changes.description = descriptionInput.value;
+      }
+      if (startInput.value !== localInput(start)) {
+        changes.start = new Date(startInput.value).toISOString();
+      }
+      if (durationInput.value !== formatHours(entry.duration_seconds)) {
+        changes.duration_seconds = parseHours(durationInput.value);
+      }
+      if (sprint.value !== (entry.sprint_id || '')) {
+        changes.sprint_id = sprint.value;
+      }
+      if (Object.keys(changes).length === 0) {
+        return;
+      }
+
+      await api('PATCH', path, changes);
+      await refreshData();
+    }, 'primary');
+    const remove = button('Удалить', async () => {
+      if (!confirm('Удалить запись от ' + formatDateTime(start) + '?')) {
+        return;
+      }
+      await api('DELETE', path);
+      await refreshData();
+    }, 'danger');
+
+    const actions = document.createElement('td');
+    actions.className = 'actions';
+    actions.append(save, ' ', remove);
+
+    row.insertCell().append(startInput);
+    row.insertCell().append(durationInput);
+    row.insertCell().append(description);
+    row.append(actions);
+  }
+}
+
+// --- Запуск ---
+
+async function refreshData() {
+  await Promise.all([loadEntries(), loadCharts(), loadHeatmap()]);
+}
+
+function logout() {
+  state.token = '';
+  localStorage.removeItem(tokenKey);
+  $('dashboard').hidden = true;
+  $('login').hidden = false;
+}
+
+async function showDashboard() {
+  $('login').hidden = true;
+  $('dashboard').hidden = false;
+
+  await loadProjects();
+  await Promise.all([loadTimers(), refreshData()]);
+}
+
+function init() {
+  const hash = new URLSearchParams(location.hash.slice(1));
+  if (hash.has('token')) {
+    localStorage.setItem(tokenKey, hash.get('token'));
+    history.replaceState(null, '', location.pathname);
+  }
+  state.token = localStorage.getItem(tokenKey) || '';
+
+  $('login-form').addEventListener('submit', (event) => {
+    event.preventDefault();
+    state.token = $('token').value.trim();
+    localStorage.setItem(tokenKey, state.token);
+    guard(showDashboard);
+  });
+  $('start-form').addEventListener('submit', (event) => {
+    event.preventDefault();
+    guard(() => trackingAction('/start', { project: $('start-project').value }));
+  });
+  $('period').addEventListener('change', () => guard(loadCharts));
+  $('entries-project').addEventListener('change', () => guard(loadEntries));
+
+  setInterval(tickTimers, 1000);
+  setInterval(() => {
+    if (state.token && !document.hidden) {
+      guard(loadTimers);
+    }
+  }, statusInterval);
+
+  if (state.token) {
+    guard(showDashboard);
+  } else {
+    logout();
+  }
+}
+
+init();
@@ -0,0 +1,22 @@
+// Package web - встроенный веб-интерфейс трекера: одностраничное приложение,
+// которое работает с данными через HTTP API
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler - отдача файлов веб-интерфейса
+func Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+
+	return http.FileServer(http.FS(files))
+}
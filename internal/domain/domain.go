@@ -1,23 +1,130 @@
 package domain
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
-// TimeEntry - запись о затраченном времени
+// TimeEntry - запись о затраченном времени.
+// Каждая запись хранится один раз в проекте и может ссылаться на спринт.
+// Время начала и окончания хранится в RFC3339 с часовым поясом, в котором велась запись.
 type TimeEntry struct {
-	Date        string `json:"date"`
-	TimeSpent   int    `json:"time_spent"`
-	Description string `json:"description"`
+	ID          string    `json:"id"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Description string    `json:"description"`
+	SprintID    string    `json:"sprint_id,omitempty"`
+	Breaks      []Break   `json:"breaks,omitempty"`
+}
+
+// Break - перерыв внутри сессии, не учитывается в затраченном времени
+type Break struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Duration - длительность записи без учета перерывов
+func (e TimeEntry) Duration() time.Duration {
+	return e.End.Sub(e.Start) - BreaksDuration(e.Breaks)
+}
+
+// WorkIntervals - интервалы работы между перерывами
+func (e TimeEntry) WorkIntervals() []Break {
+	var intervals []Break
+
+	cursor := e.Start
+	for _, b := range e.Breaks {
+		if b.Start.After(cursor) {
+			intervals = append(intervals, Break{Start: cursor, End: b.Start})
+		}
+		if b.End.After(cursor) {
+			cursor = b.End
+		}
+	}
+	if e.End.After(cursor) {
+		intervals = append(intervals, Break{Start: cursor, End: e.End})
+	}
+
+	return intervals
+}
+
+// BreaksDuration - суммарная длительность перерывов
+func BreaksDuration(breaks []Break) time.Duration {
+	var total time.Duration
+	for _, b := range breaks {
+		total += b.End.Sub(b.Start)
+	}
+
+	return total
+}
+
+// MergeBreaks - сортировка перерывов с объединением пересекающихся
+func MergeBreaks(breaks []Break) []Break {
+	sorted := append([]Break(nil), breaks...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	var merged []Break
+	for _, b := range sorted {
+		if n := len(merged); n > 0 && !b.Start.After(merged[n-1].End) {
+			if b.End.After(merged[n-1].End) {
+				merged[n-1].End = b.End
+			}
+			continue
+		}
+		merged = append(merged, b)
+	}
+
+	return merged
+}
+
+// DayPart - часть записи, приходящаяся на один календарный день
+type DayPart struct {
+	Day      string
+	Duration time.Duration
+}
+
+// SplitByDay - разбиение записи по календарным дням в часовом поясе loc.
+// Сессия, пересекающая полночь, делится между днями, перерывы не учитываются.
+func (e TimeEntry) SplitByDay(loc *time.Location) []DayPart {
+	var parts []DayPart
+
+	for _, interval := range e.WorkIntervals() {
+		start := interval.Start.In(loc)
+		end := interval.End.In(loc)
+
+		for start.Before(end) {
+			year, month, day := start.Date()
+			// Полночь считается через time.Date, чтобы учесть переход на летнее время
+			midnight := time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+
+			partEnd := end
+			if midnight.Before(end) {
+				partEnd = midnight
+			}
+
+			dayName := start.Format("2006-01-02")
+			if n := len(parts); n > 0 && parts[n-1].Day == dayName {
+				parts[n-1].Duration += partEnd.Sub(start)
+			} else {
+				parts = append(parts, DayPart{Day: dayName, Duration: partEnd.Sub(start)})
+			}
+			start = partEnd
+		}
+	}
+
+	return parts
 }
 
 // Sprint - этап проекта
 type Sprint struct {
-	ID          string               `json:"id"`
-	Name        string               `json:"name"`
-	Description string               `json:"description"`
-	StartDate   string               `json:"start_date,omitempty"`
-	EndDate     string               `json:"end_date,omitempty"`
-	Entries     map[string]TimeEntry `json:"entries,omitempty"`
-	IsActive    bool                 `json:"is_active"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	StartDate   string `json:"start_date,omitempty"`
+	EndDate     string `json:"end_date,omitempty"`
+	IsActive    bool   `json:"is_active"`
 }
 
 // Project - проект
@@ -27,11 +134,22 @@ type Project struct {
 	Sprints      map[string]*Sprint `json:"sprints,omitempty"`
 	ActiveSprint string             `json:"active_sprint,omitempty"`
 	Archived     bool               `json:"archived,omitempty"`
+
+	// Пауза текущей сессии и завершенные перерывы этой сессии
+	PausedAt *time.Time `json:"paused_at,omitempty"`
+	Breaks   []Break    `json:"breaks,omitempty"`
 }
 
-// Entry - структура записи времени
-type Entry struct {
-	TimeSpent   int    `json:"time_spent"`
-	Description string `json:"description"`
-	Date        string `json:"date"`
+// Elapsed - время работы в текущей сессии без учета перерывов
+func (p *Project) Elapsed(now time.Time) time.Duration {
+	if p.StartTime == nil {
+		return 0
+	}
+
+	end := now
+	if p.PausedAt != nil {
+		end = *p.PausedAt
+	}
+
+	return end.Sub(*p.StartTime) - BreaksDuration(p.Breaks)
 }
@@ -0,0 +1,297 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mapping - названия столбцов CSV для полей записи, пусто - столбца нет.
+// Дата может быть в отдельном столбце или вместе со временем.
+type Mapping struct {
+	Project     string
+	Sprint      string
+	Description string
+	Tags        string
+	StartDate   string
+	Start       string
+	EndDate     string
+	End         string
+	Duration    string
+}
+
+// DefaultMapping - столбцы выгрузки команды export
+func DefaultMapping() Mapping {
+	return Mapping{
+		Project:     "project",
+		Sprint:      "sprint",
+		Description: "description",
+		Start:       "start",
+		End:         "end",
+		Duration:    "duration",
+	}
+}
+
+// TogglMapping - столбцы подробного отчета Toggl Track
+func TogglMapping() Mapping {
+	return Mapping{
+		Project:     "Project",
+		Description: "Description",
+		Tags:        "Tags",
+		StartDate:   "Start date",
+		Start:       "Start time",
+		EndDate:     "End date",
+		End:         "End time",
+		Duration:    "Duration",
+	}
+}
+
+// ClockifyMapping - столбцы подробного отчета Clockify
+func ClockifyMapping() Mapping {
+	return Mapping{
+		Project:     "Project",
+		Description: "Description",
+		Tags:        "Tags",
+		StartDate:   "Start Date",
+		Start:       "Start Time",
+		EndDate:     "End Date",
+		End:         "End Time",
+		Duration:    "Duration (h)",
+	}
+}
+
+// ParseMapping - разбор сопоставления вида "project=Клиент,start=Начало".
+// Поля: project, sprint, description, tags, date, start, end_date, end, duration.
+// Не указанные поля берутся из DefaultMapping.
+func ParseMapping(spec string) (Mapping, error) {
+	mapping := DefaultMapping()
+	if strings.TrimSpace(spec) == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(spec, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		if !ok {
+			return Mapping{}, fmt.Errorf("неверное сопоставление '%s' (ожидается поле=столбец)", pair)
+		}
+		column = strings.TrimSpace(column)
+
+		switch strings.TrimSpace(field) {
+		case "project":
+			mapping.Project = column
+		case "sprint":
+			mapping.Sprint = column
+		case "description":
+			mapping.Description = column
+		case "tags":
+			mapping.Tags = column
+		case "date":
+			mapping.StartDate = column
+		case "start":
+			mapping.Start = column
+		case "end_date":
+			mapping.EndDate = column
+		case "end":
+			mapping.End = column
+		case "duration":
+			mapping.Duration = column
+		default:
+			return Mapping{}, fmt.Errorf("неизвестное поле '%s' (допустимо: project, sprint, description, tags, date, start, end_date, end, duration)", field)
+		}
+	}
+
+	return mapping, nil
+}
+
+// Форматы даты и времени, которые встречаются в выгрузках
+var (
+	dateLayouts = []string{"2006-01-02", "01/02/2006", "02.01.2006"}
+	timeLayouts = []string{"15:04:05", "15:04", "03:04:05 PM", "03:04 PM", "3:04:05 PM", "3:04 PM"}
+)
+
+// readCSV - чтение записей из CSV по сопоставлению столбцов
+func readCSV(r io.Reader, mapping Mapping, loc *time.Location) ([]Record, error) {
+	// BOM в начале файла мешает разбору заголовка в кавычках
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(3); err == nil && string(bom) == "\ufeff" {
+		buffered.Discard(3)
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения CSV: %w", err)
+	}
+
+	// Столбцы ищутся без учета регистра
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	// Отсутствующие необязательные столбцы пропускаются
+	index := func(name string) int {
+		if i, exists := columns[strings.ToLower(name)]; exists && name != "" {
+			return i
+		}
+		return -1
+	}
+
+	startDateIdx, startIdx := index(mapping.StartDate), index(mapping.Start)
+	endDateIdx, endIdx := index(mapping.EndDate), index(mapping.End)
+	durationIdx := index(mapping.Duration)
+	if startIdx == -1 {
+		return nil, fmt.Errorf("в CSV нет столбца начала записи '%s'", mapping.Start)
+	}
+	if endIdx == -1 && durationIdx == -1 {
+		return nil, fmt.Errorf("в CSV нет столбцов '%s' и '%s': нужно окончание или длительность", mapping.End, mapping.Duration)
+	}
+
+	var records []Record
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения CSV: %w", err)
+		}
+
+		cell := func(i int) string {
+			if i < 0 || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		record, err := csvRecord(cell, startDateIdx, startIdx, endDateIdx, endIdx, durationIdx, loc)
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		if record == nil {
+			continue
+		}
+
+		record.Project = cell(index(mapping.Project))
+		record.Sprint = cell(index(mapping.Sprint))
+		var tags []string
+		if value := cell(index(mapping.Tags)); value != "" {
+			tags = strings.Split(value, ",")
+		}
+		record.Description = describe(cell(index(mapping.Description)), tags)
+
+		records = append(records, *record)
+	}
+
+	return records, nil
+}
+
+// csvRecord - начало, окончание и длительность записи из ячеек строки, nil для пустой строки
+func csvRecord(cell func(int) string, startDateIdx, startIdx, endDateIdx, endIdx, durationIdx int, loc *time.Location) (*Record, error) {
+	startDate, startValue := cell(startDateIdx), cell(startIdx)
+	if startDate == "" && startValue == "" {
+		return nil, nil
+	}
+
+	start, err := parseTime(startDate, startValue, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var end time.Time
+	if endValue := cell(endIdx); endValue != "" {
+		endDate := cell(endDateIdx)
+		if endDate == "" {
+			endDate = startDate
+		}
+		if end, err = parseTime(endDate, endValue, loc); err != nil {
+			return nil, err
+		}
+
+		// Окончание без даты раньше начала - следующий день
+		if cell(endDateIdx) == "" && end.Before(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+
+		// Длительность меньше интервала означает перерывы внутри записи
+		if durationValue := cell(durationIdx); durationValue != "" {
+			duration, err := parseDuration(durationValue)
+			if err != nil {
+				return nil, err
+			}
+			if duration < end.Sub(start) {
+				return &Record{Start: start, End: end, Duration: duration}, nil
+			}
+		}
+	} else {
+		duration, err := parseDuration(cell(durationIdx))
+		if err != nil {
+			return nil, err
+		}
+		end = start.Add(duration)
+	}
+
+	return &Record{Start: start, End: end}, nil
+}
+
+// parseTime - разбор времени из отдельных даты и времени или из одной ячейки
+func parseTime(date, value string, loc *time.Location) (time.Time, error) {
+	if date != "" {
+		value = date + " " + value
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	for _, dateLayout := range dateLayouts {
+		for _, sep := range []string{" ", "T"} {
+			for _, timeLayout := range timeLayouts {
+				if t, err := time.ParseInLocation(dateLayout+sep+timeLayout, value, loc); err == nil {
+					return t, nil
+				}
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("неверный формат времени '%s'", value)
+}
+
+// parseDuration - разбор длительности: Ч:ММ:СС, Ч:ММ, 1h30m или часы дробным числом
+func parseDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, fmt.Errorf("не указаны окончание и длительность записи")
+	}
+
+	if parts := strings.Split(value, ":"); len(parts) == 2 || len(parts) == 3 {
+		var total time.Duration
+		units := []time.Duration{time.Hour, time.Minute, time.Second}
+		for i, part := range parts {
+			n, err := strconv.Atoi(part)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("неверный формат длительности '%s'", value)
+			}
+			total += time.Duration(n) * units[i]
+		}
+		return total, nil
+	}
+
+	if hours, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64); err == nil && hours >= 0 {
+		return time.Duration(hours * float64(time.Hour)).Round(time.Second), nil
+	}
+
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return duration, nil
+	}
+
+	return 0, fmt.Errorf("неверный формат длительности '%s'", value)
+}
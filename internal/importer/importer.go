@@ -0,0 +1,86 @@
+// Package importer - чтение записей времени из файлов других трекеров
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format - формат импортируемого файла
+type Format string
+
+const (
+	FormatToggl       Format = "toggl"       // CSV-выгрузка подробного отчета Toggl Track
+	FormatClockify    Format = "clockify"    // CSV-выгрузка подробного отчета Clockify
+	FormatWatson      Format = "watson"      // Файл frames Watson
+	FormatTimewarrior Format = "timewarrior" // Файлы data Timewarrior или вывод timew export
+	FormatCSV         Format = "csv"         // CSV с произвольными столбцами
+)
+
+// ParseFormat - разбор формата импорта
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(value); format {
+	case FormatToggl, FormatClockify, FormatWatson, FormatTimewarrior, FormatCSV:
+		return format, nil
+	default:
+		return "", fmt.Errorf("неизвестный формат '%s' (допустимо: toggl, clockify, watson, timewarrior, csv)", value)
+	}
+}
+
+// Record - запись времени, прочитанная из файла. Пустой проект означает
+// запись без проекта, пустой спринт - запись без спринта.
+type Record struct {
+	Project     string
+	Sprint      string
+	Start       time.Time
+	End         time.Time
+	Duration    time.Duration // Отработанное время, если оно меньше интервала; 0 - весь интервал
+	Description string
+}
+
+// Read - чтение записей в формате format. Mapping используется только для
+// формата csv, время без часового пояса читается в loc.
+func Read(format Format, r io.Reader, mapping Mapping, loc *time.Location) ([]Record, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	switch format {
+	case FormatToggl:
+		return readCSV(r, TogglMapping(), loc)
+	case FormatClockify:
+		return readCSV(r, ClockifyMapping(), loc)
+	case FormatWatson:
+		return readWatson(r)
+	case FormatTimewarrior:
+		return readTimewarrior(r)
+	case FormatCSV:
+		return readCSV(r, mapping, loc)
+	default:
+		return nil, fmt.Errorf("неизвестный формат '%s'", format)
+	}
+}
+
+// describe - описание записи с метками в квадратных скобках
+func describe(description string, tags []string) string {
+	var nonEmpty []string
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			nonEmpty = append(nonEmpty, tag)
+		}
+	}
+
+	description = strings.TrimSpace(description)
+	if len(nonEmpty) == 0 {
+		return description
+	}
+
+	labels := "[" + strings.Join(nonEmpty, ", ") + "]"
+	if description == "" {
+		return labels
+	}
+
+	return description + " " + labels
+}
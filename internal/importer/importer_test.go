@@ -0,0 +1,182 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRead(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2024, 3, day, hour, min, 0, 0, loc)
+	}
+	utc := func(day, hour, min int) time.Time {
+		return time.Date(2024, 3, day, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name    string
+		format  Format
+		mapping Mapping
+		input   string
+		want    []Record
+	}{
+		{
+			name:   "toggl",
+			format: FormatToggl,
+			input: "\ufeff\"Project\",\"Description\",\"Start date\",\"Start time\",\"End date\",\"End time\",\"Duration\",\"Tags\"\n" +
+				"alpha,анализ,2024-03-04,09:00:00,2024-03-04,10:30:00,01:30:00,\"api, ревью\"\n",
+			want: []Record{{Project: "alpha", Start: at(4, 9, 0), End: at(4, 10, 30), Description: "анализ [api, ревью]"}},
+		},
+		{
+			name:   "clockify",
+			format: FormatClockify,
+			input: "Project,Description,Tags,Start Date,Start Time,End Date,End Time,Duration (h)\n" +
+				"beta,,,03/04/2024,11:00 PM,03/05/2024,01:00 AM,\"2,00\"\n",
+			want: []Record{{Project: "beta", Start: at(4, 23, 0), End: at(5, 1, 0)}},
+		},
+		{
+			name:    "выгрузка export",
+			format:  FormatCSV,
+			mapping: DefaultMapping(),
+			input: "project,sprint,start,end,duration,description\n" +
+				"alpha,S1,2024-03-04 09:00:00,2024-03-04 11:00:00,1:30:00,анализ\n" +
+				"alpha,,2024-03-04T12:00:00Z,2024-03-04T13:00:00Z,1:00:00,\n",
+			want: []Record{
+				{Project: "alpha", Sprint: "S1", Start: at(4, 9, 0), End: at(4, 11, 0), Duration: 90 * time.Minute, Description: "анализ"},
+				{Project: "alpha", Start: utc(4, 12, 0), End: utc(4, 13, 0)},
+			},
+		},
+		{
+			name:    "длительность вместо окончания",
+			format:  FormatCSV,
+			mapping: Mapping{Project: "Клиент", StartDate: "Дата", Start: "Начало", Duration: "Часы"},
+			input: "Клиент,Дата,Начало,Часы\n" +
+				"alpha,04.03.2024,09:00,1.5\n" +
+				",,,\n" +
+				"beta,04.03.2024,12:00,1h15m\n",
+			want: []Record{
+				{Project: "alpha", Start: at(4, 9, 0), End: at(4, 10, 30)},
+				{Project: "beta", Start: at(4, 12, 0), End: at(4, 13, 15)},
+			},
+		},
+		{
+			name:    "окончание без даты после полуночи",
+			format:  FormatCSV,
+			mapping: Mapping{StartDate: "date", Start: "start", End: "end"},
+			input:   "date,start,end\n2024-03-04,23:30,00:30\n",
+			want:    []Record{{Start: at(4, 23, 30), End: at(5, 0, 30)}},
+		},
+		{
+			name:   "watson",
+			format: FormatWatson,
+			input:  `[[1709542800, 1709546400, "alpha", "id", ["api", "ревью"], 1709546400], [1709546400, 1709550000, "beta"]]`,
+			want: []Record{
+				{Project: "alpha", Start: utc(4, 9, 0), End: utc(4, 10, 0), Description: "[api, ревью]"},
+				{Project: "beta", Start: utc(4, 10, 0), End: utc(4, 11, 0)},
+			},
+		},
+		{
+			name:   "timewarrior data",
+			format: FormatTimewarrior,
+			input: "inc 20240304T090000Z - 20240304T100000Z # alpha api \"код ревью\" # \"анализ \\\"api\\\"\"\n" +
+				"\n" +
+				"inc 20240304T110000Z\n",
+			want: []Record{{Project: "alpha", Start: utc(4, 9, 0), End: utc(4, 10, 0), Description: `анализ "api" [api, код ревью]`}},
+		},
+		{
+			name:   "timew export",
+			format: FormatTimewarrior,
+			input: `[{"start": "20240304T090000Z", "end": "20240304T100000Z", "tags": ["alpha"], "annotation": "анализ"},
+				{"start": "20240304T110000Z", "tags": ["beta"]}]`,
+			want: []Record{{Project: "alpha", Start: utc(4, 9, 0), End: utc(4, 10, 0), Description: "анализ"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := Read(tt.format, strings.NewReader(tt.input), tt.mapping, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != len(tt.want) {
+				t.Fatalf("прочитано записей %d, ожидалось %d: %+v", len(records), len(tt.want), records)
+			}
+			for i, want := range tt.want {
+				got := records[i]
+				if got.Project != want.Project || got.Sprint != want.Sprint || got.Description != want.Description ||
+					!got.Start.Equal(want.Start) || !got.End.Equal(want.End) || got.Duration != want.Duration {
+					t.Errorf("запись %d: %+v, ожидалась %+v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		mapping Mapping
+		input   string
+	}{
+		{"нет столбца начала", FormatCSV, DefaultMapping(), "project,end\nalpha,2024-03-04 10:00\n"},
+		{"нет окончания и длительности", FormatCSV, DefaultMapping(), "project,start\nalpha,2024-03-04 09:00\n"},
+		{"неверное время", FormatCSV, DefaultMapping(), "start,end\nвчера,2024-03-04 10:00\n"},
+		{"неверная длительность", FormatCSV, DefaultMapping(), "start,duration\n2024-03-04 09:00,долго\n"},
+		{"пустая длительность", FormatCSV, DefaultMapping(), "start,duration\n2024-03-04 09:00,\n"},
+		{"неверная длительность при окончании", FormatCSV, DefaultMapping(), "start,end,duration\n2024-03-04 09:00,2024-03-04 10:00,-1\n"},
+		{"кадр watson без проекта", FormatWatson, Mapping{}, `[[1709542800, 1709546400]]`},
+		{"неверный json watson", FormatWatson, Mapping{}, `{}`},
+		{"строка timewarrior без inc", FormatTimewarrior, Mapping{}, "exc 20240304T090000Z - 20240304T100000Z\n"},
+		{"неверное время timewarrior", FormatTimewarrior, Mapping{}, "inc 2024-03-04 - 20240304T100000Z\n"},
+		{"неизвестный формат", Format("excel"), Mapping{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if records, err := Read(tt.format, strings.NewReader(tt.input), tt.mapping, time.UTC); err == nil {
+				t.Errorf("чтение без ошибки: %+v", records)
+			}
+		})
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Mapping
+		wantErr bool
+	}{
+		{spec: "", want: DefaultMapping()},
+		{
+			spec: "project=Клиент, date=Дата,start=Начало,duration=Часы",
+			want: Mapping{Project: "Клиент", Sprint: "sprint", Description: "description", StartDate: "Дата",
+				Start: "Начало", End: "end", Duration: "Часы"},
+		},
+		{spec: "project", wantErr: true},
+		{spec: "client=Клиент", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			mapping, err := ParseMapping(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+			if mapping != tt.want {
+				t.Errorf("сопоставление %+v, ожидалось %+v", mapping, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if format, err := ParseFormat("watson"); err != nil || format != FormatWatson {
+		t.Errorf("формат %s, ошибка %v", format, err)
+	}
+	if _, err := ParseFormat("excel"); err == nil {
+		t.Error("неизвестный формат без ошибки")
+	}
+}
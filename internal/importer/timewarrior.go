@@ -0,0 +1,160 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// timewarriorLayout - формат времени Timewarrior (UTC)
+const timewarriorLayout = "20060102T150405Z"
+
+// readTimewarrior - чтение файла data Timewarrior или вывода timew export.
+// Проектом считается первая метка, остальные метки и аннотация попадают в описание.
+// Незавершенные интервалы пропускаются.
+func readTimewarrior(r io.Reader) ([]Record, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения данных Timewarrior: %w", err)
+	}
+
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		return readTimewarriorExport(trimmed)
+	}
+
+	var records []Record
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		record, ok, err := parseTimewarriorLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		if ok {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения данных Timewarrior: %w", err)
+	}
+
+	return records, nil
+}
+
+// parseTimewarriorLine - разбор строки вида
+// inc 20240115T090000Z - 20240115T103000Z # метка "метка 2" # "аннотация"
+func parseTimewarriorLine(line string) (Record, bool, error) {
+	body, rest, _ := strings.Cut(line, " # ")
+	tagsPart, annotation, _ := strings.Cut(rest, " # ")
+
+	fields := strings.Fields(body)
+	if len(fields) == 0 || fields[0] != "inc" {
+		return Record{}, false, fmt.Errorf("ожидается строка интервала 'inc'")
+	}
+	// Интервал без окончания еще идет
+	if len(fields) < 4 || fields[2] != "-" {
+		return Record{}, false, nil
+	}
+
+	start, err := time.Parse(timewarriorLayout, fields[1])
+	if err != nil {
+		return Record{}, false, fmt.Errorf("неверное время начала '%s'", fields[1])
+	}
+	end, err := time.Parse(timewarriorLayout, fields[3])
+	if err != nil {
+		return Record{}, false, fmt.Errorf("неверное время окончания '%s'", fields[3])
+	}
+
+	return timewarriorRecord(start, end, splitTags(tagsPart), unquote(annotation)), true, nil
+}
+
+// readTimewarriorExport - чтение JSON-вывода timew export
+func readTimewarriorExport(raw []byte) ([]Record, error) {
+	var intervals []struct {
+		Start      string   `json:"start"`
+		End        string   `json:"end"`
+		Tags       []string `json:"tags"`
+		Annotation string   `json:"annotation"`
+	}
+	if err := json.Unmarshal(raw, &intervals); err != nil {
+		return nil, fmt.Errorf("ошибка чтения timew export: %w", err)
+	}
+
+	var records []Record
+	for i, interval := range intervals {
+		if interval.End == "" {
+			continue
+		}
+
+		start, err := time.Parse(timewarriorLayout, interval.Start)
+		if err != nil {
+			return nil, fmt.Errorf("интервал %d: неверное время начала '%s'", i+1, interval.Start)
+		}
+		end, err := time.Parse(timewarriorLayout, interval.End)
+		if err != nil {
+			return nil, fmt.Errorf("интервал %d: неверное время окончания '%s'", i+1, interval.End)
+		}
+
+		records = append(records, timewarriorRecord(start, end, interval.Tags, interval.Annotation))
+	}
+
+	return records, nil
+}
+
+// timewarriorRecord - запись из интервала: первая метка становится проектом
+func timewarriorRecord(start, end time.Time, tags []string, annotation string) Record {
+	record := Record{Start: start, End: end}
+	if len(tags) > 0 {
+		record.Project = tags[0]
+		tags = tags[1:]
+	}
+	record.Description = describe(annotation, tags)
+
+	return record
+}
+
+// splitTags - разбор меток, разделенных пробелами, с учетом кавычек
+func splitTags(value string) []string {
+	var tags []string
+	var current strings.Builder
+	quoted := false
+
+	flush := func() {
+		if current.Len() > 0 {
+			tags = append(tags, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range value {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ' ' && !quoted:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tags
+}
+
+// unquote - аннотация без кавычек
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		value = strings.ReplaceAll(value[1:len(value)-1], `\"`, `"`)
+	}
+
+	return value
+}
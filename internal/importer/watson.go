@@ -0,0 +1,51 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// readWatson - чтение файла frames Watson: массив кадров
+// [начало, окончание, проект, id, [метки], время изменения]
+func readWatson(r io.Reader) ([]Record, error) {
+	var frames [][]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&frames); err != nil {
+		return nil, fmt.Errorf("ошибка чтения кадров Watson: %w", err)
+	}
+
+	records := make([]Record, 0, len(frames))
+	for i, frame := range frames {
+		if len(frame) < 3 {
+			return nil, fmt.Errorf("кадр %d: ожидается не менее 3 полей", i+1)
+		}
+
+		var start, end float64
+		var project string
+		var tags []string
+		if err := json.Unmarshal(frame[0], &start); err != nil {
+			return nil, fmt.Errorf("кадр %d: неверное время начала: %w", i+1, err)
+		}
+		if err := json.Unmarshal(frame[1], &end); err != nil {
+			return nil, fmt.Errorf("кадр %d: неверное время окончания: %w", i+1, err)
+		}
+		if err := json.Unmarshal(frame[2], &project); err != nil {
+			return nil, fmt.Errorf("кадр %d: неверный проект: %w", i+1, err)
+		}
+		if len(frame) > 4 {
+			if err := json.Unmarshal(frame[4], &tags); err != nil {
+				return nil, fmt.Errorf("кадр %d: неверные метки: %w", i+1, err)
+			}
+		}
+
+		records = append(records, Record{
+			Project:     project,
+			Start:       time.Unix(int64(start), 0),
+			End:         time.Unix(int64(end), 0),
+			Description: describe("", tags),
+		})
+	}
+
+	return records, nil
+}
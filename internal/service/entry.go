@@ -0,0 +1,403 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrEntryOverlap - запись пересекается с другой записью проекта
+var ErrEntryOverlap = errors.New("запись пересекается с другой записью")
+
+// EntryService - сервис для изменения записей времени
+type EntryService struct {
+	ProjectService *ProjectService
+	Logger         logger.Logger
+}
+
+// NewEntryService - создание нового сервиса записей времени
+func NewEntryService(projectService *ProjectService, log logger.Logger) *EntryService {
+	return &EntryService{
+		ProjectService: projectService,
+		Logger:         log,
+	}
+}
+
+// EntryEdit - изменения записи, nil означает «не изменять».
+// При изменении начала без длительности запись сдвигается целиком.
+type EntryEdit struct {
+	Description *string
+	Start       *time.Time
+	Duration    *time.Duration
+	SprintID    *string
+}
+
+// GetEntries - получение записей проекта, отсортированных по времени начала
+func (s *EntryService) GetEntries(data map[string]*domain.Project, projectName string) ([]domain.TimeEntry, error) {
+	project, exists := data[projectName]
+	if !exists {
+		return nil, fmt.Errorf("проект '%s' не существует", projectName)
+	}
+
+	entries := make([]domain.TimeEntry, len(project.Entries))
+	copy(entries, project.Entries)
+	sortEntries(entries)
+
+	return entries, nil
+}
+
+// FindEntry - поиск записи по ID или его уникальному началу
+func (s *EntryService) FindEntry(data map[string]*domain.Project, projectName, id string) (domain.TimeEntry, error) {
+	project, exists := data[projectName]
+	if !exists {
+		return domain.TimeEntry{}, fmt.Errorf("проект '%s' не существует", projectName)
+	}
+
+	idx, err := findEntryIndex(project, id)
+	if err != nil {
+		return domain.TimeEntry{}, err
+	}
+
+	return project.Entries[idx], nil
+}
+
+// AddEntry - ручное добавление записи о работе в прошлом.
+// Пустой sprintID означает активный спринт проекта, noSprint - запись без спринта.
+func (s *EntryService) AddEntry(data map[string]*domain.Project, projectName string, start, end time.Time, description, sprintID string, noSprint bool) (domain.TimeEntry, error) {
+	s.Logger.Infof("Добавление записи в проект '%s': %s - %s", projectName, start.Format(time.RFC3339), end.Format(time.RFC3339))
+
+	project, exists := data[projectName]
+	if !exists {
+		return domain.TimeEntry{}, fmt.Errorf("проект '%s' не существует", projectName)
+	}
+
+	if project.Archived {
+		return domain.TimeEntry{}, fmt.Errorf("проект '%s' находится в архиве", projectName)
+	}
+
+	switch {
+	case noSprint:
+		sprintID = ""
+	case sprintID == "":
+		if _, exists := project.Sprints[project.ActiveSprint]; exists {
+			sprintID = project.ActiveSprint
+		}
+	default:
+		if _, exists := project.Sprints[sprintID]; !exists {
+			return domain.TimeEntry{}, fmt.Errorf("спринт с ID '%s' не существует в проекте '%s'", sprintID, projectName)
+		}
+	}
+
+	entry := domain.TimeEntry{
+		ID:          uuid.New().String(),
+		Start:       start,
+		End:         end,
+		Description: description,
+		SprintID:    sprintID,
+	}
+
+	if err := validateEntry(project, entry); err != nil {
+		return domain.TimeEntry{}, err
+	}
+
+	project.Entries = append(project.Entries, entry)
+	sortEntries(project.Entries)
+
+	return entry, s.ProjectService.SaveProject(data, projectName)
+}
+
+// EditEntry - изменение описания, начала, длительности или спринта записи
+func (s *EntryService) EditEntry(data map[string]*domain.Project, projectName, id string, edit EntryEdit) (domain.TimeEntry, error) {
+	s.Logger.Infof("Изменение записи %s проекта '%s'", id, projectName)
+
+	project, exists := data[projectName]
+	if !exists {
+		return domain.TimeEntry{}, fmt.Errorf("проект '%s' не существует", projectName)
+	}
+
+	idx, err := findEntryIndex(project, id)
+	if err != nil {
+		return domain.TimeEntry{}, err
+	}
+
+	entry := project.Entries[idx]
+
+	if edit.Description != nil {
+		entry.Description = *edit.Description
+	}
+
+	duration := entry.Duration()
+	if edit.Duration != nil {
+		duration = *edit.Duration
+	}
+	if edit.Start != nil {
+		// Перерывы сдвигаются вместе с записью
+		shift := edit.Start.Sub(entry.Start)
+		breaks := make([]domain.Break, len(entry.Breaks))
+		for i, b := range entry.Breaks {
+			breaks[i] = domain.Break{Start: b.Start.Add(shift), End: b.End.Add(shift)}
+		}
+		entry.Start = *edit.Start
+		entry.Breaks = breaks
+	}
+	entry = withDuration(entry, duration)
+
+	if edit.SprintID != nil {
+		if *edit.SprintID != "" {
+			if _, exists := project.Sprints[*edit.SprintID]; !exists {
+				return domain.TimeEntry{}, fmt.Errorf("спринт с ID '%s' не существует в проекте '%s'", *edit.SprintID, projectName)
+			}
+		}
+		entry.SprintID = *edit.SprintID
+	}
+
+	if err := validateEntry(project, entry); err != nil {
+		return domain.TimeEntry{}, err
+	}
+
+	project.Entries[idx] = entry
+	sortEntries(project.Entries)
+
+	return entry, s.ProjectService.SaveProject(data, projectName)
+}
+
+// DeleteEntry - удаление записи
+func (s *EntryService) DeleteEntry(data map[string]*domain.Project, projectName, id string) error {
+	s.Logger.Infof("Удаление записи %s проекта '%s'", id, projectName)
+
+	project, exists := data[projectName]
+	if !exists {
+		return fmt.Errorf("проект '%s' не существует", projectName)
+	}
+
+	idx, err := findEntryIndex(project, id)
+	if err != nil {
+		return err
+	}
+
+	project.Entries = append(project.Entries[:idx], project.Entries[idx+1:]...)
+
+	return s.ProjectService.SaveProject(data, projectName)
+}
+
+// SplitEntry - разделение записи на две в момент at.
+// Вторая запись получает новый ID, описание и спринт сохраняются.
+func (s *EntryService) SplitEntry(data map[string]*domain.Project, projectName, id string, at time.Time) (domain.TimeEntry, domain.TimeEntry, error) {
+	s.Logger.Infof("Разделение записи %s проекта '%s' в %s", id, projectName, at.Format(time.RFC3339))
+
+	project, exists := data[projectName]
+	if !exists {
+		return domain.TimeEntry{}, domain.TimeEntry{}, fmt.Errorf("проект '%s' не существует", projectName)
+	}
+
+	idx, err := findEntryIndex(project, id)
+	if err != nil {
+		return domain.TimeEntry{}, domain.TimeEntry{}, err
+	}
+
+	first := project.Entries[idx]
+	if !at.After(first.Start) || !at.Before(first.End) {
+		return domain.TimeEntry{}, domain.TimeEntry{}, fmt.Errorf("момент разделения должен быть внутри записи (%s - %s)",
+			first.Start.Format("2006-01-02 15:04:05"), first.End.Format("2006-01-02 15:04:05"))
+	}
+
+	second := first
+	second.ID = uuid.New().String()
+	// Сохраняем часовой пояс записи
+	at = at.In(first.Start.Location())
+	first.End, second.Start = at, at
+	first.Breaks, second.Breaks = nil, nil
+
+	for _, b := range project.Entries[idx].Breaks {
+		switch {
+		case !b.Start.Before(at):
+			second.Breaks = append(second.Breaks, b)
+		case !b.End.After(at):
+			first.Breaks = append(first.Breaks, b)
+		default:
+			// Разделение внутри перерыва: перерыв остается между записями
+			first.End, second.Start = b.Start, b.End
+		}
+	}
+
+	// Обе части проверяются до сохранения: разделение внутри перерыва
+	// на краю записи или между перерывами оставляет часть без рабочего времени
+	entries := append([]domain.TimeEntry(nil), project.Entries...)
+	entries[idx] = first
+	planned := *project
+	planned.Entries = entries
+	for _, part := range []domain.TimeEntry{first, second} {
+		if err := validateEntry(&planned, part); err != nil {
+			return domain.TimeEntry{}, domain.TimeEntry{}, err
+		}
+		if part.Duration() <= 0 {
+			return domain.TimeEntry{}, domain.TimeEntry{}, fmt.Errorf("после разделения в %s остается запись без рабочего времени (%s - %s)",
+				at.Format("2006-01-02 15:04:05"), part.Start.Format("2006-01-02 15:04:05"), part.End.Format("2006-01-02 15:04:05"))
+		}
+	}
+
+	project.Entries = append(entries, second)
+	sortEntries(project.Entries)
+
+	return first, second, s.ProjectService.SaveProject(data, projectName)
+}
+
+// MergeEntries - объединение двух соседних записей в одну.
+// Объединенная запись длится от начала первой до окончания второй,
+// а промежуток между ними становится перерывом.
+func (s *EntryService) MergeEntries(data map[string]*domain.Project, projectName, firstID, secondID string) (domain.TimeEntry, error) {
+	s.Logger.Infof("Объединение записей %s и %s проекта '%s'", firstID, secondID, projectName)
+
+	project, exists := data[projectName]
+	if !exists {
+		return domain.TimeEntry{}, fmt.Errorf("проект '%s' не существует", projectName)
+	}
+
+	sortEntries(project.Entries)
+
+	i, err := findEntryIndex(project, firstID)
+	if err != nil {
+		return domain.TimeEntry{}, err
+	}
+	j, err := findEntryIndex(project, secondID)
+	if err != nil {
+		return domain.TimeEntry{}, err
+	}
+	if i > j {
+		i, j = j, i
+	}
+
+	if i == j {
+		return domain.TimeEntry{}, fmt.Errorf("нельзя объединить запись саму с собой")
+	}
+	if j != i+1 {
+		return domain.TimeEntry{}, fmt.Errorf("объединять можно только соседние записи")
+	}
+
+	first, second := project.Entries[i], project.Entries[j]
+	if first.SprintID != second.SprintID {
+		return domain.TimeEntry{}, fmt.Errorf("записи относятся к разным спринтам")
+	}
+
+	merged := first
+	merged.End = second.End
+	merged.Description = mergeDescriptions(first.Description, second.Description)
+	merged.Breaks = append([]domain.Break(nil), first.Breaks...)
+	if second.Start.After(first.End) {
+		merged.Breaks = append(merged.Breaks, domain.Break{Start: first.End, End: second.Start})
+	}
+	merged.Breaks = append(merged.Breaks, second.Breaks...)
+
+	project.Entries[i] = merged
+	project.Entries = append(project.Entries[:j], project.Entries[j+1:]...)
+
+	return merged, s.ProjectService.SaveProject(data, projectName)
+}
+
+// withDuration - установка длительности записи без учета перерывов.
+// Перерывы, не поместившиеся в новую длительность, отбрасываются.
+func withDuration(entry domain.TimeEntry, duration time.Duration) domain.TimeEntry {
+	var breaks []domain.Break
+
+	cursor := entry.Start
+	remaining := duration
+	for _, b := range entry.Breaks {
+		work := b.Start.Sub(cursor)
+		if remaining <= work {
+			break
+		}
+		remaining -= work
+		breaks = append(breaks, b)
+		cursor = b.End
+	}
+
+	entry.Breaks = breaks
+	entry.End = cursor.Add(remaining)
+
+	return entry
+}
+
+// findEntryIndex - поиск индекса записи по ID или его уникальному началу
+func findEntryIndex(project *domain.Project, id string) (int, error) {
+	if id == "" {
+		return -1, fmt.Errorf("не указан ID записи")
+	}
+
+	// Полный ID имеет приоритет, даже если он является началом другого ID
+	for i, entry := range project.Entries {
+		if entry.ID == id {
+			return i, nil
+		}
+	}
+
+	found := -1
+	for i, entry := range project.Entries {
+		if strings.HasPrefix(entry.ID, id) {
+			if found != -1 {
+				return -1, fmt.Errorf("ID записи '%s' неоднозначен", id)
+			}
+			found = i
+		}
+	}
+
+	if found == -1 {
+		return -1, fmt.Errorf("запись '%s' не найдена", id)
+	}
+
+	return found, nil
+}
+
+// validateEntry - проверка длительности записи и пересечений
+// с другими записями и с запущенным отслеживанием проекта
+func validateEntry(project *domain.Project, entry domain.TimeEntry) error {
+	if !entry.End.After(entry.Start) {
+		return fmt.Errorf("длительность записи должна быть больше нуля")
+	}
+
+	if entry.End.After(time.Now()) {
+		return fmt.Errorf("запись не может заканчиваться в будущем")
+	}
+
+	for _, other := range project.Entries {
+		if other.ID == entry.ID {
+			continue
+		}
+
+		if entry.Start.Before(other.End) && other.Start.Before(entry.End) {
+			return fmt.Errorf("%w: %s - %s", ErrEntryOverlap,
+				other.Start.Local().Format("2006-01-02 15:04:05"), other.End.Local().Format("2006-01-02 15:04:05"))
+		}
+	}
+
+	if project.StartTime != nil && entry.End.After(*project.StartTime) {
+		return fmt.Errorf("%w: запущенное отслеживание с %s", ErrEntryOverlap,
+			project.StartTime.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	return nil
+}
+
+// sortEntries - сортировка записей по времени начала
+func sortEntries(entries []domain.TimeEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+}
+
+// mergeDescriptions - объединение описаний без повторов
+func mergeDescriptions(first, second string) string {
+	switch {
+	case first == "" || first == second:
+		return second
+	case second == "":
+		return first
+	default:
+		return first + "; " + second
+	}
+}
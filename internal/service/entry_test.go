@@ -0,0 +1,308 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+)
+
+// testDay - начало рабочего дня в прошлом для записей тестов
+var testDay = time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+
+// addEntry - добавление записи с проверкой ошибки
+func (s *testServices) addEntry(t *testing.T, project string, start time.Time, d time.Duration, description string) domain.TimeEntry {
+	t.Helper()
+
+	entry, err := s.entries.AddEntry(s.data, project, start, start.Add(d), description, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return entry
+}
+
+func TestAddEntry(t *testing.T) {
+	s := newTestServices(t, nil, "alpha", "archived")
+	if err := s.projects.CreateSprint(s.data, "alpha", "S1", ""); err != nil {
+		t.Fatal(err)
+	}
+	s.data["archived"].Archived = true
+	sprintID := s.data["alpha"].ActiveSprint
+
+	s.addEntry(t, "alpha", testDay, time.Hour, "анализ")
+
+	tests := []struct {
+		name       string
+		project    string
+		start, end time.Time
+		sprintID   string
+		noSprint   bool
+		wantSprint string
+		wantErr    bool
+	}{
+		{name: "активный спринт по умолчанию", project: "alpha", start: testDay.Add(time.Hour), end: testDay.Add(2 * time.Hour), wantSprint: sprintID},
+		{name: "без спринта", project: "alpha", start: testDay.Add(2 * time.Hour), end: testDay.Add(3 * time.Hour), noSprint: true},
+		{name: "пересечение", project: "alpha", start: testDay.Add(30 * time.Minute), end: testDay.Add(90 * time.Minute), wantErr: true},
+		{name: "нулевая длительность", project: "alpha", start: testDay.Add(5 * time.Hour), end: testDay.Add(5 * time.Hour), wantErr: true},
+		{name: "окончание в будущем", project: "alpha", start: time.Now(), end: time.Now().Add(time.Hour), wantErr: true},
+		{name: "несуществующий спринт", project: "alpha", start: testDay.Add(6 * time.Hour), end: testDay.Add(7 * time.Hour), sprintID: "x", wantErr: true},
+		{name: "архивный проект", project: "archived", start: testDay, end: testDay.Add(time.Hour), wantErr: true},
+		{name: "несуществующий проект", project: "beta", start: testDay, end: testDay.Add(time.Hour), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := s.entries.AddEntry(s.data, tt.project, tt.start, tt.end, "", tt.sprintID, tt.noSprint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+			if err == nil && entry.SprintID != tt.wantSprint {
+				t.Errorf("спринт записи '%s', ожидался '%s'", entry.SprintID, tt.wantSprint)
+			}
+		})
+	}
+
+	if _, err := s.entries.AddEntry(s.data, "alpha", testDay.Add(30*time.Minute), testDay.Add(90*time.Minute), "", "", false); !errors.Is(err, ErrEntryOverlap) {
+		t.Errorf("пересечение: %v", err)
+	}
+
+	if saved := s.reload(t)["alpha"]; len(saved.Entries) != 3 {
+		t.Errorf("сохранено записей %d, ожидалось 3", len(saved.Entries))
+	}
+}
+
+func TestEditEntry(t *testing.T) {
+	s := newTestServices(t, nil, "alpha")
+	if err := s.projects.CreateSprint(s.data, "alpha", "S1", ""); err != nil {
+		t.Fatal(err)
+	}
+	sprintID := s.data["alpha"].ActiveSprint
+
+	entry := s.addEntry(t, "alpha", testDay, 2*time.Hour, "анализ")
+	s.addEntry(t, "alpha", testDay.Add(3*time.Hour), time.Hour, "ревью")
+
+	// Перерыв внутри первой записи
+	s.data["alpha"].Entries[0].Breaks = []domain.Break{{Start: testDay.Add(time.Hour), End: testDay.Add(90 * time.Minute)}}
+
+	newStart := testDay.Add(-time.Hour)
+	shortened := time.Hour
+	overlapping := 5 * time.Hour
+	description := "разработка"
+	noSprint := ""
+	unknownSprint := "x"
+
+	tests := []struct {
+		name    string
+		edit    EntryEdit
+		check   func(domain.TimeEntry) bool
+		wantErr bool
+	}{
+		{
+			name:  "описание",
+			edit:  EntryEdit{Description: &description},
+			check: func(e domain.TimeEntry) bool { return e.Description == description },
+		},
+		{
+			name: "начало сдвигается вместе с перерывами",
+			edit: EntryEdit{Start: &newStart},
+			check: func(e domain.TimeEntry) bool {
+				return e.Start.Equal(newStart) && e.Duration() == 90*time.Minute &&
+					len(e.Breaks) == 1 && e.Breaks[0].Start.Equal(testDay)
+			},
+		},
+		{
+			name: "длительность отбрасывает лишние перерывы",
+			edit: EntryEdit{Duration: &shortened},
+			check: func(e domain.TimeEntry) bool {
+				return e.Duration() == time.Hour && len(e.Breaks) == 0
+			},
+		},
+		{
+			name:  "без спринта",
+			edit:  EntryEdit{SprintID: &noSprint},
+			check: func(e domain.TimeEntry) bool { return e.SprintID == "" },
+		},
+		{
+			name:  "спринт",
+			edit:  EntryEdit{SprintID: &sprintID},
+			check: func(e domain.TimeEntry) bool { return e.SprintID == sprintID },
+		},
+		{name: "пересечение со следующей записью", edit: EntryEdit{Duration: &overlapping}, wantErr: true},
+		{name: "несуществующий спринт", edit: EntryEdit{SprintID: &unknownSprint}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edited, err := s.entries.EditEntry(s.data, "alpha", entry.ID, tt.edit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+			if err == nil && !tt.check(edited) {
+				t.Errorf("измененная запись: %+v", edited)
+			}
+		})
+	}
+
+	saved, err := s.entries.FindEntry(s.reload(t), "alpha", entry.ID)
+	if err != nil || saved.Description != description || saved.Duration() != time.Hour {
+		t.Errorf("сохраненная запись: %+v, %v", saved, err)
+	}
+}
+
+func TestSplitMergeEntries(t *testing.T) {
+	s := newTestServices(t, nil, "alpha")
+
+	entry := s.addEntry(t, "alpha", testDay, 2*time.Hour, "анализ")
+	s.data["alpha"].Entries[0].Breaks = []domain.Break{{Start: testDay.Add(90 * time.Minute), End: testDay.Add(100 * time.Minute)}}
+
+	if _, _, err := s.entries.SplitEntry(s.data, "alpha", entry.ID, testDay.Add(3*time.Hour)); err == nil {
+		t.Error("разделение вне записи без ошибки")
+	}
+
+	first, second, err := s.entries.SplitEntry(s.data, "alpha", entry.ID, testDay.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != entry.ID || second.ID == entry.ID || second.Description != "анализ" {
+		t.Errorf("разделенные записи: %+v, %+v", first, second)
+	}
+	if first.Duration() != time.Hour || second.Duration() != 50*time.Minute || len(second.Breaks) != 1 {
+		t.Errorf("длительности %v и %v", first.Duration(), second.Duration())
+	}
+
+	// Разделение внутри перерыва оставляет перерыв между записями
+	_, third, err := s.entries.SplitEntry(s.data, "alpha", second.ID, testDay.Add(95*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !third.Start.Equal(testDay.Add(100*time.Minute)) || len(third.Breaks) != 0 {
+		t.Errorf("запись после перерыва: %+v", third)
+	}
+
+	if _, err := s.entries.MergeEntries(s.data, "alpha", first.ID, third.ID); err == nil {
+		t.Error("объединение несоседних записей без ошибки")
+	}
+	if _, err := s.entries.MergeEntries(s.data, "alpha", first.ID, first.ID); err == nil {
+		t.Error("объединение записи с собой без ошибки")
+	}
+
+	merged, err := s.entries.MergeEntries(s.data, "alpha", third.ID, second.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged, err = s.entries.MergeEntries(s.data, "alpha", first.ID, merged.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.ID != entry.ID || merged.Duration() != 110*time.Minute || merged.Description != "анализ" {
+		t.Errorf("объединенная запись: %+v, длительность %v", merged, merged.Duration())
+	}
+
+	if saved := s.reload(t)["alpha"]; len(saved.Entries) != 1 {
+		t.Errorf("сохранено записей %d, ожидалась 1", len(saved.Entries))
+	}
+}
+
+func TestSplitEntryEmptyPart(t *testing.T) {
+	s := newTestServices(t, nil, "alpha")
+
+	entry := s.addEntry(t, "alpha", testDay, 2*time.Hour, "")
+	s.data["alpha"].Entries[0].Breaks = []domain.Break{
+		{Start: testDay, End: testDay.Add(30 * time.Minute)},
+		{Start: testDay.Add(time.Hour), End: testDay.Add(90 * time.Minute)},
+		{Start: testDay.Add(90 * time.Minute), End: testDay.Add(2 * time.Hour)},
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+	}{
+		{name: "внутри перерыва в начале записи", at: testDay.Add(10 * time.Minute)},
+		{name: "внутри перерыва в конце записи", at: testDay.Add(100 * time.Minute)},
+		{name: "между перерывами", at: testDay.Add(90 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if first, second, err := s.entries.SplitEntry(s.data, "alpha", entry.ID, tt.at); err == nil {
+				t.Errorf("разделение без ошибки: %+v, %+v", first, second)
+			}
+		})
+	}
+
+	if entries := s.data["alpha"].Entries; len(entries) != 1 || len(entries[0].Breaks) != 3 {
+		t.Errorf("записи после неудачного разделения: %+v", entries)
+	}
+}
+
+func TestMergeEntriesSprints(t *testing.T) {
+	s := newTestServices(t, nil, "alpha")
+
+	first := s.addEntry(t, "alpha", testDay, time.Hour, "")
+	if err := s.projects.CreateSprint(s.data, "alpha", "S1", ""); err != nil {
+		t.Fatal(err)
+	}
+	second := s.addEntry(t, "alpha", testDay.Add(time.Hour), time.Hour, "")
+
+	if _, err := s.entries.MergeEntries(s.data, "alpha", first.ID, second.ID); err == nil {
+		t.Error("объединение записей разных спринтов без ошибки")
+	}
+}
+
+func TestDeleteEntry(t *testing.T) {
+	s := newTestServices(t, nil, "alpha")
+
+	entry := s.addEntry(t, "alpha", testDay, time.Hour, "")
+	s.addEntry(t, "alpha", testDay.Add(time.Hour), time.Hour, "")
+
+	if err := s.entries.DeleteEntry(s.data, "alpha", ""); err == nil {
+		t.Error("удаление без ID без ошибки")
+	}
+	if err := s.entries.DeleteEntry(s.data, "alpha", entry.ID[:8]); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.entries.DeleteEntry(s.data, "alpha", entry.ID); err == nil {
+		t.Error("повторное удаление без ошибки")
+	}
+
+	entries, err := s.entries.GetEntries(s.reload(t), "alpha")
+	if err != nil || len(entries) != 1 || entries[0].ID == entry.ID {
+		t.Errorf("записи после удаления: %+v, %v", entries, err)
+	}
+}
+
+func TestFindEntry(t *testing.T) {
+	s := newTestServices(t, nil, "alpha")
+	s.data["alpha"].Entries = []domain.TimeEntry{
+		{ID: "abc1", Start: testDay, End: testDay.Add(time.Hour)},
+		{ID: "abc2", Start: testDay.Add(time.Hour), End: testDay.Add(2 * time.Hour)},
+		{ID: "ab", Start: testDay.Add(2 * time.Hour), End: testDay.Add(3 * time.Hour)},
+	}
+
+	tests := []struct {
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{id: "abc1", want: "abc1"},
+		{id: "abc2", want: "abc2"},
+		{id: "ab", want: "ab"},
+		{id: "abc", wantErr: true},
+		{id: "x", wantErr: true},
+		{id: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			entry, err := s.entries.FindEntry(s.data, "alpha", tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+			if entry.ID != tt.want {
+				t.Errorf("найдена запись '%s', ожидалась '%s'", entry.ID, tt.want)
+			}
+		})
+	}
+}
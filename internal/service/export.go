@@ -0,0 +1,266 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+)
+
+// ExportFormat - формат выгрузки записей
+type ExportFormat string
+
+const (
+	ExportCSV      ExportFormat = "csv"
+	ExportJSON     ExportFormat = "json"
+	ExportMarkdown ExportFormat = "md"
+	ExportHTML     ExportFormat = "html"
+)
+
+// ParseExportFormat - разбор формата выгрузки
+func ParseExportFormat(value string) (ExportFormat, error) {
+	switch format := ExportFormat(value); format {
+	case ExportCSV, ExportJSON, ExportMarkdown, ExportHTML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("неизвестный формат '%s' (допустимо: csv, json, md, html)", value)
+	}
+}
+
+// ExportRow - запись времени для выгрузки
+type ExportRow struct {
+	Project     string        `json:"project"`
+	Sprint      string        `json:"sprint,omitempty"`
+	Start       time.Time     `json:"start"`
+	End         time.Time     `json:"end"`
+	Duration    time.Duration `json:"-"`
+	Description string        `json:"description"`
+}
+
+// MarshalJSON - длительность в JSON выгружается в секундах
+func (r ExportRow) MarshalJSON() ([]byte, error) {
+	type row ExportRow
+	return json.Marshal(struct {
+		row
+		DurationSeconds int `json:"duration_seconds"`
+	}{row(r), int(r.Duration.Seconds())})
+}
+
+// UnmarshalJSON - чтение строки выгрузки с длительностью в секундах
+func (r *ExportRow) UnmarshalJSON(data []byte) error {
+	type row ExportRow
+	var decoded struct {
+		row
+		DurationSeconds int `json:"duration_seconds"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*r = ExportRow(decoded.row)
+	r.Duration = time.Duration(decoded.DurationSeconds) * time.Second
+	return nil
+}
+
+// ExportEntries - записи за период для выгрузки, отсортированные по началу.
+// Запись попадает в период по дню начала, GroupBy фильтра не учитывается.
+func (s *ReportService) ExportEntries(data map[string]*domain.Project, filter ReportFilter) ([]ExportRow, error) {
+	s.Logger.Debugf("Выгрузка записей: %+v", filter)
+
+	if filter.Location == nil {
+		filter.Location = time.Local
+	}
+	if filter.Project != "" {
+		if _, exists := data[filter.Project]; !exists {
+			return nil, fmt.Errorf("проект '%s' не существует", filter.Project)
+		}
+	}
+
+	from := filter.From.Format("2006-01-02")
+	to := filter.To.Format("2006-01-02")
+	if to < from {
+		return nil, fmt.Errorf("начало периода %s позже окончания %s", from, to)
+	}
+
+	var rows []ExportRow
+	for name, project := range data {
+		if filter.Project != "" && name != filter.Project {
+			continue
+		}
+
+		for _, entry := range project.Entries {
+			day := entry.Start.In(filter.Location).Format("2006-01-02")
+			if day < from || day > to {
+				continue
+			}
+
+			row := ExportRow{
+				Project:     name,
+				Start:       entry.Start.In(filter.Location),
+				End:         entry.End.In(filter.Location),
+				Duration:    entry.Duration(),
+				Description: entry.Description,
+			}
+			if sprint, exists := project.Sprints[entry.SprintID]; exists {
+				row.Sprint = sprint.Name
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].Start.Equal(rows[j].Start) {
+			return rows[i].Start.Before(rows[j].Start)
+		}
+		return rows[i].Project < rows[j].Project
+	})
+
+	return rows, nil
+}
+
+// WriteExport - запись выгрузки в указанном формате
+func WriteExport(w io.Writer, format ExportFormat, rows []ExportRow) error {
+	switch format {
+	case ExportCSV:
+		return writeCSV(w, rows)
+	case ExportJSON:
+		return writeJSON(w, rows)
+	case ExportMarkdown:
+		return writeMarkdown(w, rows)
+	case ExportHTML:
+		return writeHTML(w, rows)
+	default:
+		return fmt.Errorf("неизвестный формат '%s'", format)
+	}
+}
+
+// exportTableTime - время в таблицах Markdown и HTML со смещением зоны
+const exportTableTime = "2006-01-02 15:04 -07:00"
+
+// exportHeader - заголовки столбцов выгрузки
+var exportHeader = []string{"project", "sprint", "start", "end", "duration", "description"}
+
+// writeCSV - выгрузка в CSV, время в RFC3339, длительность в формате Ч:ММ:СС
+func writeCSV(w io.Writer, rows []ExportRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportHeader); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := []string{
+			row.Project,
+			row.Sprint,
+			row.Start.Format(time.RFC3339),
+			row.End.Format(time.RFC3339),
+			formatClock(row.Duration),
+			row.Description,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSON - выгрузка в JSON-массив, время в RFC3339
+func writeJSON(w io.Writer, rows []ExportRow) error {
+	if rows == nil {
+		rows = []ExportRow{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rows)
+}
+
+// writeMarkdown - выгрузка таблицей Markdown с итоговой строкой
+func writeMarkdown(w io.Writer, rows []ExportRow) error {
+	var b strings.Builder
+	b.WriteString("| Проект | Спринт | Начало | Окончание | Длительность | Описание |\n")
+	b.WriteString("|---|---|---|---|---:|---|\n")
+
+	var total time.Duration
+	for _, row := range rows {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			escapeMarkdown(row.Project),
+			escapeMarkdown(row.Sprint),
+			row.Start.Format(exportTableTime),
+			row.End.Format(exportTableTime),
+			formatClock(row.Duration),
+			escapeMarkdown(row.Description),
+		)
+		total += row.Duration
+	}
+	fmt.Fprintf(&b, "| **Итого** | | | | **%s** | |\n", formatClock(total))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeMarkdown - экранирование текста для ячейки таблицы Markdown
+func escapeMarkdown(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// exportHTMLTemplate - отдельная HTML-страница с таблицей записей
+var exportHTMLTemplate = template.Must(template.New("export").Funcs(template.FuncMap{
+	"clock": formatClock,
+	"time":  func(t time.Time) string { return t.Format(exportTableTime) },
+}).Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Учет времени</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+td.duration { text-align: right; }
+</style>
+</head>
+<body>
+<table>
+<thead>
+<tr><th>Проект</th><th>Спринт</th><th>Начало</th><th>Окончание</th><th>Длительность</th><th>Описание</th></tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr><td>{{.Project}}</td><td>{{.Sprint}}</td><td>{{time .Start}}</td><td>{{time .End}}</td><td class="duration">{{clock .Duration}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</tbody>
+<tfoot>
+<tr><th colspan="4">Итого</th><th>{{clock .Total}}</th><th></th></tr>
+</tfoot>
+</table>
+</body>
+</html>
+`))
+
+// writeHTML - выгрузка HTML-страницей с итоговой строкой
+func writeHTML(w io.Writer, rows []ExportRow) error {
+	var total time.Duration
+	for _, row := range rows {
+		total += row.Duration
+	}
+
+	return exportHTMLTemplate.Execute(w, struct {
+		Rows  []ExportRow
+		Total time.Duration
+	}{rows, total})
+}
+
+// formatClock - длительность в формате Ч:ММ:СС
+func formatClock(duration time.Duration) string {
+	seconds := int(duration.Seconds())
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+}
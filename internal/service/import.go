@@ -0,0 +1,210 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/importer"
+	"github.com/google/uuid"
+)
+
+// ImportSkip - запись, которая не была импортирована, с причиной
+type ImportSkip struct {
+	Record importer.Record
+	Reason string
+}
+
+// ImportResult - итог импорта
+type ImportResult struct {
+	DryRun     bool
+	Projects   []string // Созданные проекты
+	Sprints    []string // Созданные спринты в виде "проект / спринт"
+	Added      int
+	Duplicates int
+	Skipped    []ImportSkip
+}
+
+// Import - импорт записей из другого трекера. Недостающие проекты и спринты
+// создаются, записи без проекта попадают в defaultProject. Запись с тем же
+// началом и окончанием, что и у существующей, считается дубликатом и
+// пропускается, как и записи, пересекающиеся с другими записями проекта.
+// При dryRun данные не изменяются, а результат показывает, что будет сделано.
+func (s *EntryService) Import(data map[string]*domain.Project, records []importer.Record, defaultProject string, dryRun bool) (ImportResult, error) {
+	s.Logger.Infof("Импорт записей: %d (проверка без изменений: %v)", len(records), dryRun)
+
+	result := ImportResult{DryRun: dryRun}
+
+	sorted := append([]importer.Record(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	// Изменения собираются в копиях проектов и применяются в конце
+	planned := make(map[string]*domain.Project)
+	changed := make(map[string]bool)
+	for _, record := range sorted {
+		name := record.Project
+		if name == "" {
+			name = defaultProject
+		}
+		if name == "" {
+			result.Skipped = append(result.Skipped, ImportSkip{Record: record, Reason: "не указан проект"})
+			continue
+		}
+		record.Project = name
+
+		project, exists := planned[name]
+		if !exists {
+			project = copyForImport(data[name])
+			if data[name] == nil {
+				result.Projects = append(result.Projects, name)
+			}
+			planned[name] = project
+		}
+
+		if project.Archived {
+			result.Skipped = append(result.Skipped, ImportSkip{Record: record, Reason: "проект находится в архиве"})
+			continue
+		}
+
+		if isDuplicateEntry(project, record) {
+			result.Duplicates++
+			continue
+		}
+
+		entry := domain.TimeEntry{
+			ID:          uuid.New().String(),
+			Start:       record.Start,
+			End:         record.End,
+			Description: record.Description,
+			Breaks:      importBreaks(record),
+		}
+		if err := validateEntry(project, entry); err != nil {
+			result.Skipped = append(result.Skipped, ImportSkip{Record: record, Reason: err.Error()})
+			continue
+		}
+
+		if record.Sprint != "" {
+			entry.SprintID = importSprint(project, record)
+			if entry.SprintID == "" {
+				result.Sprints = append(result.Sprints, name+" / "+record.Sprint)
+				entry.SprintID = addImportedSprint(project, record)
+			}
+		}
+
+		project.Entries = append(project.Entries, entry)
+		changed[name] = true
+		result.Added++
+	}
+
+	// Проекты, в которые не попало ни одной записи, не создаются
+	var created []string
+	for _, name := range result.Projects {
+		if changed[name] {
+			created = append(created, name)
+		}
+	}
+	sort.Strings(created)
+	result.Projects = created
+
+	if dryRun {
+		return result, nil
+	}
+
+	names := make([]string, 0, len(changed))
+	for name := range changed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, exists := data[name]; !exists {
+			if err := s.ProjectService.CreateProject(data, name); err != nil {
+				return result, err
+			}
+		}
+
+		project := data[name]
+		project.Entries = planned[name].Entries
+		project.Sprints = planned[name].Sprints
+		sortEntries(project.Entries)
+
+		if err := s.ProjectService.SaveProject(data, name); err != nil {
+			return result, err
+		}
+	}
+
+	s.Logger.Infof("Импортировано записей: %d, дубликатов: %d, пропущено: %d", result.Added, result.Duplicates, len(result.Skipped))
+	return result, nil
+}
+
+// copyForImport - копия записей и спринтов проекта для планирования импорта
+func copyForImport(project *domain.Project) *domain.Project {
+	if project == nil {
+		return &domain.Project{}
+	}
+
+	planned := *project
+	planned.Entries = append([]domain.TimeEntry(nil), project.Entries...)
+	planned.Sprints = make(map[string]*domain.Sprint, len(project.Sprints))
+	for id, sprint := range project.Sprints {
+		planned.Sprints[id] = sprint
+	}
+
+	return &planned
+}
+
+// isDuplicateEntry - есть ли в проекте запись с тем же началом и окончанием
+// с точностью до секунды
+func isDuplicateEntry(project *domain.Project, record importer.Record) bool {
+	start := record.Start.Truncate(time.Second)
+	end := record.End.Truncate(time.Second)
+
+	for _, entry := range project.Entries {
+		if entry.Start.Truncate(time.Second).Equal(start) && entry.End.Truncate(time.Second).Equal(end) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// importBreaks - перерыв для записи, отработанное время которой меньше интервала.
+// Положение перерывов в файле не указано, поэтому разница становится одним
+// перерывом в конце записи.
+func importBreaks(record importer.Record) []domain.Break {
+	if record.Duration <= 0 || record.Duration >= record.End.Sub(record.Start) {
+		return nil
+	}
+
+	return []domain.Break{{Start: record.Start.Add(record.Duration), End: record.End}}
+}
+
+// importSprint - ID спринта проекта с именем из записи, пусто если его нет
+func importSprint(project *domain.Project, record importer.Record) string {
+	for id, sprint := range project.Sprints {
+		if sprint.Name == record.Sprint {
+			return id
+		}
+	}
+
+	return ""
+}
+
+// addImportedSprint - добавление завершенного спринта из импорта,
+// активный спринт проекта не меняется
+func addImportedSprint(project *domain.Project, record importer.Record) string {
+	if project.Sprints == nil {
+		project.Sprints = make(map[string]*domain.Sprint)
+	}
+
+	id := uuid.New().String()
+	project.Sprints[id] = &domain.Sprint{
+		ID:        id,
+		Name:      record.Sprint,
+		StartDate: record.Start.Local().Format("2006-01-02"),
+	}
+
+	return id
+}
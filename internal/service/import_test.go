@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/importer"
+)
+
+func TestImport(t *testing.T) {
+	s := newTestServices(t, nil, "alpha", "archived")
+	s.data["archived"].Archived = true
+	s.addEntry(t, "alpha", testDay, time.Hour, "анализ")
+
+	records := []importer.Record{
+		{Project: "alpha", Start: testDay, End: testDay.Add(time.Hour)},
+		{Project: "alpha", Start: testDay.Add(30 * time.Minute), End: testDay.Add(2 * time.Hour)},
+		{Project: "alpha", Sprint: "S1", Start: testDay.Add(2 * time.Hour), End: testDay.Add(3 * time.Hour), Duration: 45 * time.Minute},
+		{Project: "beta", Start: testDay, End: testDay.Add(time.Hour)},
+		{Start: testDay.Add(time.Hour), End: testDay.Add(2 * time.Hour), Description: "без проекта"},
+		{Project: "archived", Start: testDay, End: testDay.Add(time.Hour)},
+		{Project: "gamma", Start: testDay, End: testDay},
+	}
+
+	dryRun, err := s.entries.Import(s.data, records, "beta", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.data["alpha"].Entries) != 1 || s.data["beta"] != nil {
+		t.Errorf("проверка без изменений изменила данные: %+v", s.data)
+	}
+
+	result, err := s.entries.Import(s.data, records, "beta", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dryRun.DryRun || dryRun.Added != result.Added || dryRun.Duplicates != result.Duplicates || len(dryRun.Skipped) != len(result.Skipped) {
+		t.Errorf("проверка %+v отличается от импорта %+v", dryRun, result)
+	}
+
+	if result.Added != 3 || result.Duplicates != 1 || len(result.Skipped) != 3 {
+		t.Errorf("добавлено %d, дубликатов %d, пропущено %+v", result.Added, result.Duplicates, result.Skipped)
+	}
+	if len(result.Projects) != 1 || result.Projects[0] != "beta" {
+		t.Errorf("созданные проекты %v, ожидался beta", result.Projects)
+	}
+	if len(result.Sprints) != 1 || result.Sprints[0] != "alpha / S1" {
+		t.Errorf("созданные спринты %v", result.Sprints)
+	}
+
+	saved := s.reload(t)
+	if _, exists := saved["gamma"]; exists {
+		t.Error("создан проект без импортированных записей")
+	}
+	if len(saved["beta"].Entries) != 2 {
+		t.Errorf("записи beta: %+v", saved["beta"].Entries)
+	}
+
+	entry := saved["alpha"].Entries[1]
+	if entry.Duration() != 45*time.Minute || !entry.End.Equal(testDay.Add(3*time.Hour)) || saved["alpha"].Sprints[entry.SprintID] == nil {
+		t.Errorf("запись с перерывом: %+v", entry)
+	}
+	if saved["alpha"].ActiveSprint != "" {
+		t.Error("импортированный спринт стал активным")
+	}
+
+	// Повторный импорт того же файла ничего не добавляет
+	again, err := s.entries.Import(s.data, records, "beta", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Added != 0 || again.Duplicates != 4 {
+		t.Errorf("повторный импорт: %+v", again)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	s := newTestServices(t, nil, "alpha")
+	if err := s.projects.CreateSprint(s.data, "alpha", "S1", ""); err != nil {
+		t.Fatal(err)
+	}
+	s.addEntry(t, "alpha", testDay, 2*time.Hour, "анализ, ревью")
+	s.addEntry(t, "alpha", testDay.Add(3*time.Hour), time.Hour, "")
+
+	// Перерыв внутри первой записи
+	s.data["alpha"].Entries[0].Breaks = []domain.Break{{Start: testDay.Add(time.Hour), End: testDay.Add(90 * time.Minute)}}
+	if err := s.projects.SaveProject(s.data, "alpha"); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := s.reports.ExportEntries(s.data, ReportFilter{From: testDay, To: testDay})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteExport(&buf, ExportCSV, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := importer.Read(importer.FormatCSV, &buf, importer.DefaultMapping(), time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported := newTestServices(t, nil)
+	result, err := imported.entries.Import(imported.data, records, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 2 || len(result.Skipped) != 0 {
+		t.Fatalf("импорт выгрузки: %+v", result)
+	}
+
+	original := s.data["alpha"].Entries
+	entries := imported.reload(t)["alpha"].Entries
+	for i, entry := range entries {
+		want := original[i]
+		if !entry.Start.Equal(want.Start) || !entry.End.Equal(want.End) || entry.Duration() != want.Duration() ||
+			entry.Description != want.Description {
+			t.Errorf("запись %d: %+v, ожидалась %+v", i+1, entry, want)
+		}
+	}
+	if sprint := imported.data["alpha"].Sprints[entries[0].SprintID]; sprint == nil || sprint.Name != "S1" {
+		t.Errorf("спринт записи: %+v", sprint)
+	}
+}
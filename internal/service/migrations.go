@@ -0,0 +1,233 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/storage"
+	"github.com/google/uuid"
+)
+
+// CurrentSchemaVersion - версия схемы данных, с которой работает приложение
+const CurrentSchemaVersion = 5
+
+// Migration - шаг обновления схемы данных до версии Version.
+// Проекты передаются в виде JSON-объектов, чтобы миграции не зависели
+// от текущих структур domain.
+type Migration struct {
+	Version     int
+	Description string
+	Apply       func(projects map[string]map[string]any) error
+}
+
+// migrations - шаги обновления схемы по возрастанию версии
+var migrations = []Migration{
+	{
+		Version:     2,
+		Description: "записи спринтов хранятся один раз в записях проекта со ссылкой на спринт",
+		Apply:       migrateSprintEntries,
+	},
+	{
+		Version:     3,
+		Description: "у записей появились ID и время начала и окончания",
+		Apply:       migrateEntryTimes,
+	},
+	{
+		Version:     4,
+		Description: "удалена строка времени остановки без часового пояса",
+		Apply:       migrateDropEntryDate,
+	},
+	{
+		Version:     5,
+		Description: "перерывы в записях и приостановленные сессии",
+		// Новые поля необязательны, версия повышается, чтобы старые версии
+		// приложения не считали время перерывов рабочим
+		Apply: func(projects map[string]map[string]any) error { return nil },
+	},
+}
+
+// Migrate - обновление данных до текущей версии схемы.
+// Возвращает true, если данные были изменены.
+func Migrate(snapshot *storage.Snapshot) (bool, error) {
+	return migrate(snapshot, migrations, CurrentSchemaVersion)
+}
+
+// migrate - последовательное применение шагов, версия которых выше версии данных
+func migrate(snapshot *storage.Snapshot, steps []Migration, target int) (bool, error) {
+	if snapshot.Version > target {
+		return false, fmt.Errorf("версия схемы данных %d новее поддерживаемой %d, обновите приложение", snapshot.Version, target)
+	}
+	if snapshot.Version == target {
+		return false, nil
+	}
+
+	projects := make(map[string]map[string]any, len(snapshot.Projects))
+	for name, raw := range snapshot.Projects {
+		var project map[string]any
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		// Числа сохраняются без потери точности
+		dec.UseNumber()
+		if err := dec.Decode(&project); err != nil {
+			return false, fmt.Errorf("%w: проект '%s': %v", storage.ErrCorrupt, name, err)
+		}
+		projects[name] = project
+	}
+
+	for _, step := range steps {
+		if step.Version <= snapshot.Version || step.Version > target {
+			continue
+		}
+		// Шаги не справляются только с данными неожиданного вида: без даты,
+		// с неверной длительностью или не объектом вместо записи
+		if err := step.Apply(projects); err != nil {
+			return false, fmt.Errorf("%w: ошибка миграции данных до версии %d (%s): %w",
+				storage.ErrCorrupt, step.Version, step.Description, err)
+		}
+	}
+
+	for name, project := range projects {
+		raw, err := json.Marshal(project)
+		if err != nil {
+			return false, err
+		}
+		snapshot.Projects[name] = raw
+	}
+	snapshot.Version = target
+
+	return true, nil
+}
+
+// migrateSprintEntries - миграция до версии 2.
+// Раньше запись времени дублировалась в проекте и в активном спринте.
+// Для записи спринта ищется такая же запись проекта и помечается ссылкой
+// на спринт, записи без пары переносятся в проект, а записи спринтов удаляются.
+func migrateSprintEntries(projects map[string]map[string]any) error {
+	for name, project := range projects {
+		sprints, _ := project["sprints"].(map[string]any)
+		if len(sprints) == 0 {
+			continue
+		}
+
+		entries, _ := project["entries"].([]any)
+		var orphans []any
+
+		for sprintID, value := range sprints {
+			sprint, ok := value.(map[string]any)
+			if !ok {
+				return fmt.Errorf("проект '%s': спринт '%s' не является объектом", name, sprintID)
+			}
+
+			sprintEntries, _ := sprint["entries"].(map[string]any)
+			for _, value := range sprintEntries {
+				sprintEntry, ok := value.(map[string]any)
+				if !ok {
+					return fmt.Errorf("проект '%s': запись спринта '%s' не является объектом", name, sprintID)
+				}
+
+				if entry := findSameEntry(entries, sprintEntry); entry != nil {
+					entry["sprint_id"] = sprintID
+					continue
+				}
+
+				sprintEntry["sprint_id"] = sprintID
+				orphans = append(orphans, sprintEntry)
+			}
+
+			delete(sprint, "entries")
+		}
+
+		// Записи спринтов хранились в карте, поэтому порядок восстанавливается по дате
+		sort.SliceStable(orphans, func(i, j int) bool {
+			return fmt.Sprint(orphans[i].(map[string]any)["date"]) < fmt.Sprint(orphans[j].(map[string]any)["date"])
+		})
+		entries = append(entries, orphans...)
+
+		if len(entries) > 0 {
+			project["entries"] = entries
+		}
+	}
+
+	return nil
+}
+
+// findSameEntry - поиск записи проекта без ссылки на спринт,
+// совпадающей с записью спринта по дате, времени и описанию
+func findSameEntry(entries []any, sprintEntry map[string]any) map[string]any {
+	for _, value := range entries {
+		entry, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if _, linked := entry["sprint_id"]; linked {
+			continue
+		}
+
+		if fmt.Sprint(entry["date"]) == fmt.Sprint(sprintEntry["date"]) &&
+			fmt.Sprint(entry["time_spent"]) == fmt.Sprint(sprintEntry["time_spent"]) &&
+			fmt.Sprint(entry["description"]) == fmt.Sprint(sprintEntry["description"]) {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// legacyDateFormat - формат времени остановки в записях до версии 3
+const legacyDateFormat = "2006-01-02 15:04:05"
+
+// migrateEntryTimes - миграция до версии 3.
+// Записи получают ID, а время начала и окончания вычисляется из времени
+// остановки (в локальном часовом поясе) и длительности в секундах.
+func migrateEntryTimes(projects map[string]map[string]any) error {
+	for name, project := range projects {
+		entries, _ := project["entries"].([]any)
+
+		for i, value := range entries {
+			entry, ok := value.(map[string]any)
+			if !ok {
+				return fmt.Errorf("проект '%s': запись %d не является объектом", name, i+1)
+			}
+
+			end, err := time.ParseInLocation(legacyDateFormat, fmt.Sprint(entry["date"]), time.Local)
+			if err != nil {
+				return fmt.Errorf("проект '%s': запись %d: неверная дата: %w", name, i+1, err)
+			}
+
+			var seconds int64
+			if number, ok := entry["time_spent"].(json.Number); ok {
+				if seconds, err = number.Int64(); err != nil {
+					return fmt.Errorf("проект '%s': запись %d: неверная длительность: %w", name, i+1, err)
+				}
+			}
+
+			if _, exists := entry["id"]; !exists {
+				entry["id"] = uuid.New().String()
+			}
+			entry["start"] = end.Add(-time.Duration(seconds) * time.Second).Format(time.RFC3339)
+			entry["end"] = end.Format(time.RFC3339)
+			delete(entry, "time_spent")
+		}
+	}
+
+	return nil
+}
+
+// migrateDropEntryDate - миграция до версии 4.
+// Время остановки полностью задается полем end, поэтому строка date удаляется.
+func migrateDropEntryDate(projects map[string]map[string]any) error {
+	for _, project := range projects {
+		entries, _ := project["entries"].([]any)
+
+		for _, value := range entries {
+			if entry, ok := value.(map[string]any); ok {
+				delete(entry, "date")
+			}
+		}
+	}
+
+	return nil
+}
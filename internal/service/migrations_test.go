@@ -0,0 +1,304 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/storage"
+)
+
+// decodeProjects - проекты в виде JSON-объектов, как их получают шаги миграции
+func decodeProjects(t *testing.T, content string) map[string]map[string]any {
+	t.Helper()
+
+	var projects map[string]map[string]any
+	dec := json.NewDecoder(bytes.NewReader([]byte(content)))
+	dec.UseNumber()
+	if err := dec.Decode(&projects); err != nil {
+		t.Fatalf("неверные данные теста: %v", err)
+	}
+
+	return projects
+}
+
+// assertProjects - сравнение проектов с ожидаемым JSON без учета порядка полей
+func assertProjects(t *testing.T, got map[string]map[string]any, want string) {
+	t.Helper()
+
+	if expected := decodeProjects(t, want); !reflect.DeepEqual(got, expected) {
+		content, _ := json.Marshal(got)
+		t.Errorf("получено %s\nожидалось %s", content, want)
+	}
+}
+
+func TestMigrateSprintEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "без спринтов",
+			input: `{"a": {"entries": [{"date": "2024-03-04 10:00:00", "time_spent": 60}]}}`,
+			want:  `{"a": {"entries": [{"date": "2024-03-04 10:00:00", "time_spent": 60}]}}`,
+		},
+		{
+			name: "запись спринта совпадает с записью проекта",
+			input: `{"a": {
+				"entries": [{"date": "2024-03-04 10:00:00", "time_spent": 60, "description": "x"}],
+				"sprints": {"s1": {"name": "S1", "entries": {"1": {"date": "2024-03-04 10:00:00", "time_spent": 60, "description": "x"}}}}
+			}}`,
+			want: `{"a": {
+				"entries": [{"date": "2024-03-04 10:00:00", "time_spent": 60, "description": "x", "sprint_id": "s1"}],
+				"sprints": {"s1": {"name": "S1"}}
+			}}`,
+		},
+		{
+			name: "записи спринта без пары переносятся в проект по дате",
+			input: `{"a": {
+				"entries": [{"date": "2024-03-01 10:00:00", "time_spent": 10, "description": "x"}],
+				"sprints": {"s1": {"name": "S1", "entries": {
+					"2": {"date": "2024-03-05 10:00:00", "time_spent": 30, "description": "z"},
+					"1": {"date": "2024-03-04 10:00:00", "time_spent": 20, "description": "y"}
+				}}}
+			}}`,
+			want: `{"a": {
+				"entries": [
+					{"date": "2024-03-01 10:00:00", "time_spent": 10, "description": "x"},
+					{"date": "2024-03-04 10:00:00", "time_spent": 20, "description": "y", "sprint_id": "s1"},
+					{"date": "2024-03-05 10:00:00", "time_spent": 30, "description": "z", "sprint_id": "s1"}
+				],
+				"sprints": {"s1": {"name": "S1"}}
+			}}`,
+		},
+		{
+			name:    "спринт не объект",
+			input:   `{"a": {"sprints": {"s1": "S1"}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "запись спринта не объект",
+			input:   `{"a": {"sprints": {"s1": {"entries": {"1": 60}}}}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects := decodeProjects(t, tt.input)
+
+			err := migrateSprintEntries(projects)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ожидалась ошибка")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertProjects(t, projects, tt.want)
+		})
+	}
+}
+
+func TestMigrateEntryTimes(t *testing.T) {
+	end := time.Date(2024, 3, 4, 10, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		input     string
+		wantStart time.Time
+		wantID    string
+		wantErr   bool
+	}{
+		{
+			name:      "начало по времени остановки и длительности",
+			input:     `{"a": {"entries": [{"date": "2024-03-04 10:30:00", "time_spent": 5400, "description": "x"}]}}`,
+			wantStart: end.Add(-90 * time.Minute),
+		},
+		{
+			name:      "без длительности",
+			input:     `{"a": {"entries": [{"date": "2024-03-04 10:30:00"}]}}`,
+			wantStart: end,
+		},
+		{
+			name:      "ID записи сохраняется",
+			input:     `{"a": {"entries": [{"id": "e1", "date": "2024-03-04 10:30:00", "time_spent": 60}]}}`,
+			wantStart: end.Add(-time.Minute),
+			wantID:    "e1",
+		},
+		{
+			name:    "нет даты",
+			input:   `{"a": {"entries": [{"time_spent": 60}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "неверная дата",
+			input:   `{"a": {"entries": [{"date": "04.03.2024", "time_spent": 60}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "дробная длительность",
+			input:   `{"a": {"entries": [{"date": "2024-03-04 10:30:00", "time_spent": 1.5}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "запись не объект",
+			input:   `{"a": {"entries": [60]}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects := decodeProjects(t, tt.input)
+
+			err := migrateEntryTimes(projects)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ожидалась ошибка")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			entry := projects["a"]["entries"].([]any)[0].(map[string]any)
+			if entry["start"] != tt.wantStart.Format(time.RFC3339) || entry["end"] != end.Format(time.RFC3339) {
+				t.Errorf("период записи %v - %v, ожидалось %v - %v", entry["start"], entry["end"], tt.wantStart, end)
+			}
+			if _, exists := entry["time_spent"]; exists {
+				t.Error("длительность не удалена")
+			}
+			if id, _ := entry["id"].(string); id == "" || (tt.wantID != "" && id != tt.wantID) {
+				t.Errorf("ID записи '%s'", id)
+			}
+		})
+	}
+}
+
+func TestMigrateDropEntryDate(t *testing.T) {
+	projects := decodeProjects(t, `{
+		"a": {"entries": [{"id": "e1", "date": "2024-03-04 10:30:00", "end": "2024-03-04T10:30:00Z"}, "не объект"]},
+		"b": {}
+	}`)
+
+	if err := migrateDropEntryDate(projects); err != nil {
+		t.Fatal(err)
+	}
+
+	assertProjects(t, projects, `{
+		"a": {"entries": [{"id": "e1", "end": "2024-03-04T10:30:00Z"}, "не объект"]},
+		"b": {}
+	}`)
+}
+
+func TestMigrateLegacyToCurrent(t *testing.T) {
+	snapshot := storage.NewSnapshot(1)
+	snapshot.Projects["a"] = json.RawMessage(`{
+		"entries": [
+			{"date": "2024-03-04 10:00:00", "time_spent": 3600, "description": "анализ"},
+			{"date": "2024-03-04 12:00:00", "time_spent": 1800, "description": "ревью"}
+		],
+		"sprints": {"s1": {"id": "s1", "name": "S1", "is_active": true, "entries": {
+			"1": {"date": "2024-03-04 12:00:00", "time_spent": 1800, "description": "ревью"},
+			"2": {"date": "2024-03-05 09:00:00", "time_spent": 600, "description": "планирование"}
+		}}},
+		"active_sprint": "s1"
+	}`)
+	snapshot.Projects["b"] = json.RawMessage(`{"archived": true}`)
+
+	changed, err := Migrate(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || snapshot.Version != CurrentSchemaVersion {
+		t.Fatalf("изменено %v, версия %d", changed, snapshot.Version)
+	}
+
+	var project domain.Project
+	if err := json.Unmarshal(snapshot.Projects["a"], &project); err != nil {
+		t.Fatal(err)
+	}
+
+	day := func(d, h, m int) time.Time { return time.Date(2024, 3, d, h, m, 0, 0, time.Local) }
+	want := []struct {
+		start, end  time.Time
+		description string
+		sprintID    string
+	}{
+		{day(4, 9, 0), day(4, 10, 0), "анализ", ""},
+		{day(4, 11, 30), day(4, 12, 0), "ревью", "s1"},
+		{day(5, 8, 50), day(5, 9, 0), "планирование", "s1"},
+	}
+
+	if len(project.Entries) != len(want) {
+		t.Fatalf("записей %d, ожидалось %d: %+v", len(project.Entries), len(want), project.Entries)
+	}
+	for i, entry := range project.Entries {
+		if entry.ID == "" ||
+			!entry.Start.Equal(want[i].start) || !entry.End.Equal(want[i].end) ||
+			entry.Description != want[i].description || entry.SprintID != want[i].sprintID {
+			t.Errorf("запись %d: %+v, ожидалось %+v", i+1, entry, want[i])
+		}
+	}
+
+	if project.ActiveSprint != "s1" || project.Sprints["s1"] == nil || !project.Sprints["s1"].IsActive {
+		t.Errorf("спринты: %+v", project.Sprints)
+	}
+
+	if bytes.Contains(snapshot.Projects["a"], []byte(`"date"`)) || bytes.Contains(snapshot.Projects["a"], []byte(`"time_spent"`)) {
+		t.Errorf("остались поля старой схемы: %s", snapshot.Projects["a"])
+	}
+
+	var archived domain.Project
+	if err := json.Unmarshal(snapshot.Projects["b"], &archived); err != nil || !archived.Archived {
+		t.Errorf("проект b: %+v, %v", archived, err)
+	}
+
+	// Повторный запуск ничего не меняет
+	if changed, err := Migrate(snapshot); err != nil || changed {
+		t.Errorf("повторная миграция: изменено %v, ошибка %v", changed, err)
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     int
+		project     string
+		wantCorrupt bool
+	}{
+		{"версия новее поддерживаемой", CurrentSchemaVersion + 1, `{}`, false},
+		{"проект не объект", 1, `[]`, true},
+		{"запись без даты", 2, `{"entries": [{"time_spent": 60}]}`, true},
+		{"неверная дата записи", 1, `{"entries": [{"date": "вчера", "time_spent": 60}]}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := storage.NewSnapshot(tt.version)
+			snapshot.Projects["a"] = json.RawMessage(tt.project)
+
+			_, err := Migrate(snapshot)
+			if err == nil {
+				t.Fatal("ожидалась ошибка")
+			}
+			if got := errors.Is(err, storage.ErrCorrupt); got != tt.wantCorrupt {
+				t.Errorf("ErrCorrupt %v, ожидалось %v: %v", got, tt.wantCorrupt, err)
+			}
+			if snapshot.Version != tt.version {
+				t.Errorf("версия изменилась на %d", snapshot.Version)
+			}
+		})
+	}
+}
@@ -3,77 +3,156 @@ package service
 import (
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sort"
 	"time"
 
 	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/storage"
 	"github.com/MWT-proger/time-tracking/pkg/logger"
 	"github.com/google/uuid"
 )
 
 // ProjectService - сервис для работы с проектами
 type ProjectService struct {
-	DataFile string
-	Logger   logger.Logger
+	Storage storage.Storage
+	Logger  logger.Logger
 }
 
 // NewProjectService - создание нового сервиса проектов
-func NewProjectService(log logger.Logger, dataFile string) *ProjectService {
+func NewProjectService(log logger.Logger, store storage.Storage) *ProjectService {
 	return &ProjectService{
-		DataFile: dataFile,
-		Logger:   log,
+		Storage: store,
+		Logger:  log,
 	}
 }
 
-// LoadData - загрузка данных из файла
+// LoadData - загрузка всех проектов из хранилища с обновлением схемы данных
 func (s *ProjectService) LoadData() (map[string]*domain.Project, error) {
-	s.Logger.Debug("Загрузка данных из файла:", s.DataFile)
-	data := make(map[string]*domain.Project)
-
-	// Создаем директорию для файла данных, если она не существует
-	dir := filepath.Dir(s.DataFile)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		s.Logger.Errorf("Ошибка создания директории для данных: %v", err)
+	snapshot, err := s.Storage.Load()
+	if err != nil {
 		return nil, err
 	}
 
-	file, err := os.Open(s.DataFile)
-	if err != nil && !os.IsNotExist(err) {
-		s.Logger.Errorf("Ошибка открытия файла данных: %v", err)
+	fromVersion := snapshot.Version
+	migrated, err := Migrate(snapshot)
+	if err != nil {
+		s.Logger.Errorf("Ошибка обновления схемы данных: %v", err)
 		return nil, err
 	}
-	if os.IsNotExist(err) {
-		s.Logger.Info("Файл данных не существует, будет создан новый")
-		return data, nil
+
+	data := make(map[string]*domain.Project, len(snapshot.Projects))
+	for name, raw := range snapshot.Projects {
+		project := &domain.Project{}
+		if err := json.Unmarshal(raw, project); err != nil {
+			s.Logger.Errorf("Ошибка разбора проекта '%s': %v", name, err)
+			return nil, fmt.Errorf("%w: проект '%s': %v", storage.ErrCorrupt, name, err)
+		}
+		data[name] = project
 	}
-	defer file.Close()
-	if err := json.NewDecoder(file).Decode(&data); err != nil {
-		s.Logger.Errorf("Ошибка декодирования данных: %v", err)
-		return data, err
+
+	if migrated {
+		s.Logger.Infof("Схема данных обновлена с версии %d до %d", fromVersion, snapshot.Version)
+
+		// Данные старой схемы сохраняются отдельно от резервных копий, которые
+		// ротация удалит через несколько сохранений
+		if backups, ok := s.Storage.(storage.BackupStorage); ok {
+			if path, err := backups.BackupBeforeMigration(fromVersion); err != nil {
+				s.Logger.Errorf("Ошибка создания копии перед обновлением схемы: %v", err)
+			} else if path != "" {
+				s.Logger.Infof("Данные версии %d сохранены в %s", fromVersion, path)
+			}
+		}
+
+		// Обновленные данные можно использовать и без сохранения,
+		// например в режиме только для чтения
+		if err := s.Storage.Save(snapshot); err != nil {
+			s.Logger.Warnf("Обновленная схема данных не сохранена: %v", err)
+		}
 	}
+
 	return data, nil
 }
 
-// SaveData - сохранение данных в файл
+// SaveData - сохранение всех проектов в хранилище
 func (s *ProjectService) SaveData(data map[string]*domain.Project) error {
-	s.Logger.Debug("Сохранение данных в файл:", s.DataFile)
+	snapshot := storage.NewSnapshot(CurrentSchemaVersion)
+	for name, project := range data {
+		raw, err := json.Marshal(project)
+		if err != nil {
+			return err
+		}
+		snapshot.Projects[name] = raw
+	}
+
+	return s.Storage.Save(snapshot)
+}
+
+// ChangedOnDisk - проверка, изменены ли данные другой программой после загрузки
+func (s *ProjectService) ChangedOnDisk() bool {
+	detector, ok := s.Storage.(storage.ChangeDetector)
+	if !ok {
+		return false
+	}
 
-	// Создаем директорию для файла данных, если она не существует
-	dir := filepath.Dir(s.DataFile)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		s.Logger.Errorf("Ошибка создания директории для данных: %v", err)
+	changed, err := detector.ChangedOnDisk()
+	if err != nil {
+		s.Logger.Warnf("Ошибка проверки изменений данных: %v", err)
+		return false
+	}
+
+	return changed
+}
+
+// RecoverData - восстановление поврежденных данных хранилища
+func (s *ProjectService) RecoverData(allowPartial bool) (*storage.RecoveryReport, error) {
+	recoverable, ok := s.Storage.(storage.RecoverableStorage)
+	if !ok {
+		return nil, fmt.Errorf("хранилище не поддерживает восстановление данных")
+	}
+
+	s.Logger.Warnf("Восстановление поврежденных данных (частичное восстановление: %v)", allowPartial)
+	return recoverable.Recover(allowPartial, validProject)
+}
+
+// validProject - проверка, что проект читается приложением: схема
+// обновляется до текущей версии, а результат разбирается в domain.Project
+func validProject(version int, name string, raw json.RawMessage) error {
+	snapshot := storage.NewSnapshot(version)
+	snapshot.Projects[name] = raw
+
+	if _, err := Migrate(snapshot); err != nil {
 		return err
 	}
 
-	file, err := os.Create(s.DataFile)
+	return json.Unmarshal(snapshot.Projects[name], &domain.Project{})
+}
+
+// SaveProject - сохранение в хранилище одного проекта после его изменения
+func (s *ProjectService) SaveProject(data map[string]*domain.Project, name string) error {
+	project, exists := data[name]
+	if !exists {
+		return fmt.Errorf("проект '%s' не существует", name)
+	}
+
+	raw, err := json.Marshal(project)
 	if err != nil {
-		s.Logger.Errorf("Ошибка создания файла данных: %v", err)
 		return err
 	}
-	defer file.Close()
-	return json.NewEncoder(file).Encode(data)
+
+	return s.Storage.SaveProject(name, raw)
+}
+
+// GetSprintEntries - получение записей проекта, относящихся к спринту
+func (s *ProjectService) GetSprintEntries(project *domain.Project, sprintID string) []domain.TimeEntry {
+	var entries []domain.TimeEntry
+
+	for _, entry := range project.Entries {
+		if entry.SprintID == sprintID {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
 }
 
 // CreateProject - создание нового проекта
@@ -93,7 +172,7 @@ func (s *ProjectService) CreateProject(data map[string]*domain.Project, name str
 	}
 
 	data[name] = &domain.Project{}
-	return s.SaveData(data)
+	return s.SaveProject(data, name)
 }
 
 // GetProjectNames - получение списка имен проектов
@@ -152,14 +231,13 @@ func (s *ProjectService) CreateSprint(data map[string]*domain.Project, projectNa
 		Name:        sprintName,
 		Description: description,
 		StartDate:   time.Now().Format("2006-01-02"),
-		Entries:     make(map[string]domain.TimeEntry),
 		IsActive:    true,
 	}
 
 	// Установка спринта как активного для проекта
 	project.ActiveSprint = sprintID
 
-	return s.SaveData(data)
+	return s.SaveProject(data, projectName)
 }
 
 // GetProjectSprints - получение списка спринтов проекта
@@ -189,6 +267,22 @@ func (s *ProjectService) GetProjectSprints(data map[string]*domain.Project, proj
 	return sprints, nil
 }
 
+// FindSprintByName - поиск спринта проекта по имени
+func (s *ProjectService) FindSprintByName(data map[string]*domain.Project, projectName, sprintName string) (*domain.Sprint, error) {
+	project, exists := data[projectName]
+	if !exists {
+		return nil, fmt.Errorf("проект '%s' не существует", projectName)
+	}
+
+	for _, sprint := range project.Sprints {
+		if sprint.Name == sprintName {
+			return sprint, nil
+		}
+	}
+
+	return nil, fmt.Errorf("спринт '%s' не найден в проекте '%s'", sprintName, projectName)
+}
+
 // SetActiveSprint - установка активного спринта для проекта
 func (s *ProjectService) SetActiveSprint(data map[string]*domain.Project, projectName, sprintID string) error {
 	project, exists := data[projectName]
@@ -213,7 +307,7 @@ func (s *ProjectService) SetActiveSprint(data map[string]*domain.Project, projec
 	project.Sprints[sprintID].IsActive = true
 	project.ActiveSprint = sprintID
 
-	return s.SaveData(data)
+	return s.SaveProject(data, projectName)
 }
 
 // ArchiveProject - архивирование проекта
@@ -234,7 +328,7 @@ func (s *ProjectService) ArchiveProject(data map[string]*domain.Project, name st
 
 	project.Archived = true
 
-	return s.SaveData(data)
+	return s.SaveProject(data, name)
 }
 
 // RestoreProject - восстановление проекта из архива
@@ -254,5 +348,26 @@ func (s *ProjectService) RestoreProject(data map[string]*domain.Project, name st
 
 	project.Archived = false
 
-	return s.SaveData(data)
+	return s.SaveProject(data, name)
+}
+
+// DeleteProject - удаление проекта вместе со всеми записями
+func (s *ProjectService) DeleteProject(data map[string]*domain.Project, name string) error {
+	s.Logger.Infof("Удаление проекта: %s", name)
+
+	project, exists := data[name]
+	if !exists {
+		return fmt.Errorf("проект '%s' не существует", name)
+	}
+
+	if project.StartTime != nil {
+		return fmt.Errorf("невозможно удалить проект с запущенным отслеживанием")
+	}
+
+	if err := s.Storage.DeleteProject(name); err != nil {
+		return err
+	}
+
+	delete(data, name)
+	return nil
 }
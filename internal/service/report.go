@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/pkg/logger"
+)
+
+// GroupBy - способ группировки отчета
+type GroupBy string
+
+const (
+	GroupByDay     GroupBy = "day"
+	GroupByWeek    GroupBy = "week"
+	GroupByMonth   GroupBy = "month"
+	GroupByProject GroupBy = "project"
+	GroupBySprint  GroupBy = "sprint"
+)
+
+// ParseGroupBy - разбор способа группировки
+func ParseGroupBy(value string) (GroupBy, error) {
+	switch group := GroupBy(value); group {
+	case GroupByDay, GroupByWeek, GroupByMonth, GroupByProject, GroupBySprint:
+		return group, nil
+	default:
+		return "", fmt.Errorf("неизвестная группировка '%s' (допустимо: day, week, month, project, sprint)", value)
+	}
+}
+
+// ReportFilter - параметры отчета
+type ReportFilter struct {
+	// Период по дням включительно, учитывается только дата
+	From time.Time
+	To   time.Time
+
+	// Проект, пусто - все проекты
+	Project string
+
+	GroupBy GroupBy
+
+	// Часовой пояс, в котором время делится по дням
+	Location *time.Location
+}
+
+// ReportRow - строка отчета: время проекта (и спринта) за период группировки
+type ReportRow struct {
+	// День (ГГГГ-ММ-ДД), неделя (ГГГГ-WНН) или месяц (ГГГГ-ММ),
+	// пусто при группировке по проектам и спринтам
+	Period   string
+	Project  string
+	Sprint   string
+	Duration time.Duration
+}
+
+// Report - отчет за период
+type Report struct {
+	Filter ReportFilter
+	Rows   []ReportRow
+	Total  time.Duration
+}
+
+// ReportService - сервис отчетов по записям времени
+type ReportService struct {
+	ProjectService *ProjectService
+	Logger         logger.Logger
+}
+
+// NewReportService - создание нового сервиса отчетов
+func NewReportService(projectService *ProjectService, log logger.Logger) *ReportService {
+	return &ReportService{
+		ProjectService: projectService,
+		Logger:         log,
+	}
+}
+
+// Report - время записей за период с группировкой. Записи делятся по
+// календарным дням, поэтому сессия через полночь попадает в оба дня.
+func (s *ReportService) Report(data map[string]*domain.Project, filter ReportFilter) (Report, error) {
+	s.Logger.Debugf("Построение отчета: %+v", filter)
+
+	if filter.Location == nil {
+		filter.Location = time.Local
+	}
+	if filter.Project != "" {
+		if _, exists := data[filter.Project]; !exists {
+			return Report{}, fmt.Errorf("проект '%s' не существует", filter.Project)
+		}
+	}
+
+	from := filter.From.Format("2006-01-02")
+	to := filter.To.Format("2006-01-02")
+	if to < from {
+		return Report{}, fmt.Errorf("начало периода %s позже окончания %s", from, to)
+	}
+
+	type rowKey struct {
+		period, project, sprint string
+	}
+	totals := make(map[rowKey]time.Duration)
+
+	report := Report{Filter: filter}
+	for name, project := range data {
+		if filter.Project != "" && name != filter.Project {
+			continue
+		}
+
+		for _, entry := range project.Entries {
+			for _, part := range entry.SplitByDay(filter.Location) {
+				if part.Day < from || part.Day > to {
+					continue
+				}
+
+				key := rowKey{project: name}
+				switch filter.GroupBy {
+				case GroupByDay:
+					key.period = part.Day
+				case GroupByWeek:
+					key.period = weekOf(part.Day, filter.Location)
+				case GroupByMonth:
+					key.period = part.Day[:7]
+				case GroupBySprint:
+					key.sprint = sprintName(project, entry.SprintID)
+				}
+
+				totals[key] += part.Duration
+				report.Total += part.Duration
+			}
+		}
+	}
+
+	for key, duration := range totals {
+		report.Rows = append(report.Rows, ReportRow{
+			Period:   key.period,
+			Project:  key.project,
+			Sprint:   key.sprint,
+			Duration: duration,
+		})
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		a, b := report.Rows[i], report.Rows[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		return a.Sprint < b.Sprint
+	})
+
+	return report, nil
+}
+
+// weekOf - ISO-неделя дня в формате ГГГГ-WНН
+func weekOf(day string, loc *time.Location) string {
+	date, err := time.ParseInLocation("2006-01-02", day, loc)
+	if err != nil {
+		return day
+	}
+
+	year, week := date.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// sprintName - название спринта записи
+func sprintName(project *domain.Project, sprintID string) string {
+	if sprint, exists := project.Sprints[sprintID]; exists {
+		return sprint.Name
+	}
+
+	return "Без спринта"
+}
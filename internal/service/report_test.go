@@ -0,0 +1,179 @@
+package service
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReport(t *testing.T) {
+	s := newTestServices(t, nil, "alpha", "beta")
+	if err := s.projects.CreateSprint(s.data, "alpha", "S1", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	// Сессия через полночь попадает в оба дня
+	s.addEntry(t, "alpha", monday.Add(22*time.Hour), 4*time.Hour, "")
+	if _, err := s.entries.AddEntry(s.data, "alpha", monday.AddDate(0, 0, 7).Add(10*time.Hour), monday.AddDate(0, 0, 7).Add(11*time.Hour), "", "", true); err != nil {
+		t.Fatal(err)
+	}
+	s.addEntry(t, "beta", monday.Add(10*time.Hour), 30*time.Minute, "")
+
+	tests := []struct {
+		name      string
+		filter    ReportFilter
+		want      []ReportRow
+		wantTotal time.Duration
+	}{
+		{
+			name:   "по дням",
+			filter: ReportFilter{GroupBy: GroupByDay},
+			want: []ReportRow{
+				{Period: "2024-03-04", Project: "alpha", Duration: 2 * time.Hour},
+				{Period: "2024-03-04", Project: "beta", Duration: 30 * time.Minute},
+				{Period: "2024-03-05", Project: "alpha", Duration: 2 * time.Hour},
+				{Period: "2024-03-11", Project: "alpha", Duration: time.Hour},
+			},
+			wantTotal: 5*time.Hour + 30*time.Minute,
+		},
+		{
+			name:   "по неделям",
+			filter: ReportFilter{GroupBy: GroupByWeek},
+			want: []ReportRow{
+				{Period: "2024-W10", Project: "alpha", Duration: 4 * time.Hour},
+				{Period: "2024-W10", Project: "beta", Duration: 30 * time.Minute},
+				{Period: "2024-W11", Project: "alpha", Duration: time.Hour},
+			},
+			wantTotal: 5*time.Hour + 30*time.Minute,
+		},
+		{
+			name:   "по месяцам",
+			filter: ReportFilter{GroupBy: GroupByMonth},
+			want: []ReportRow{
+				{Period: "2024-03", Project: "alpha", Duration: 5 * time.Hour},
+				{Period: "2024-03", Project: "beta", Duration: 30 * time.Minute},
+			},
+			wantTotal: 5*time.Hour + 30*time.Minute,
+		},
+		{
+			name:   "по проектам",
+			filter: ReportFilter{GroupBy: GroupByProject},
+			want: []ReportRow{
+				{Project: "alpha", Duration: 5 * time.Hour},
+				{Project: "beta", Duration: 30 * time.Minute},
+			},
+			wantTotal: 5*time.Hour + 30*time.Minute,
+		},
+		{
+			name:   "по спринтам",
+			filter: ReportFilter{GroupBy: GroupBySprint},
+			want: []ReportRow{
+				{Project: "alpha", Sprint: "S1", Duration: 4 * time.Hour},
+				{Project: "alpha", Sprint: "Без спринта", Duration: time.Hour},
+				{Project: "beta", Sprint: "Без спринта", Duration: 30 * time.Minute},
+			},
+			wantTotal: 5*time.Hour + 30*time.Minute,
+		},
+		{
+			name: "проект за один день",
+			filter: ReportFilter{
+				From:    monday.AddDate(0, 0, 1),
+				To:      monday.AddDate(0, 0, 1),
+				Project: "alpha",
+				GroupBy: GroupByDay,
+			},
+			want: []ReportRow{
+				{Period: "2024-03-05", Project: "alpha", Duration: 2 * time.Hour},
+			},
+			wantTotal: 2 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			filter.Location = time.UTC
+			if filter.From.IsZero() {
+				filter.From = monday
+				filter.To = monday.AddDate(0, 1, 0)
+			}
+
+			report, err := s.reports.Report(s.data, filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(report.Rows, tt.want) {
+				t.Errorf("строки отчета %+v, ожидалось %+v", report.Rows, tt.want)
+			}
+			if report.Total != tt.wantTotal {
+				t.Errorf("итого %v, ожидалось %v", report.Total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestReportErrors(t *testing.T) {
+	s := newTestServices(t, nil, "alpha")
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter ReportFilter
+	}{
+		{"несуществующий проект", ReportFilter{From: day, To: day, Project: "beta"}},
+		{"окончание раньше начала", ReportFilter{From: day, To: day.AddDate(0, 0, -1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.reports.Report(s.data, tt.filter); err == nil {
+				t.Error("ожидалась ошибка")
+			}
+		})
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	for _, value := range []string{"day", "week", "month", "project", "sprint"} {
+		if group, err := ParseGroupBy(value); err != nil || string(group) != value {
+			t.Errorf("%s: %v, %v", value, group, err)
+		}
+	}
+	if _, err := ParseGroupBy("year"); err == nil {
+		t.Error("неизвестная группировка без ошибки")
+	}
+}
+
+func TestWriteExport(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, msk)
+	rows := []ExportRow{{Project: "alpha", Start: start, End: start.Add(90 * time.Minute), Duration: 90 * time.Minute}}
+
+	tests := []struct {
+		format ExportFormat
+		want   []string
+	}{
+		{ExportCSV, []string{"2024-03-04T09:00:00+03:00", "2024-03-04T10:30:00+03:00", "1:30:00"}},
+		{ExportJSON, []string{`"2024-03-04T09:00:00+03:00"`, `"2024-03-04T10:30:00+03:00"`}},
+		{ExportMarkdown, []string{"2024-03-04 09:00 +03:00", "2024-03-04 10:30 +03:00", "**1:30:00**"}},
+		{ExportHTML, []string{"<td>2024-03-04 09:00 &#43;03:00</td>", "<td>2024-03-04 10:30 &#43;03:00</td>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteExport(&buf, tt.format, rows); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("в выгрузке нет '%s':\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/pkg/clock"
+)
+
+// ReminderKind - вид напоминания
+type ReminderKind string
+
+const (
+	// ReminderBreak - пора сделать перерыв
+	ReminderBreak ReminderKind = "break"
+	// ReminderForgotStart - в рабочее время не запущено отслеживание
+	ReminderForgotStart ReminderKind = "forgot_start"
+	// ReminderEndOfDay - отслеживание идет после окончания рабочего дня
+	ReminderEndOfDay ReminderKind = "end_of_day"
+)
+
+// Reminder - напоминание планировщика
+type Reminder struct {
+	Kind ReminderKind
+
+	// Проект сессии для напоминания о перерыве,
+	// первый из запущенных проектов для напоминания об окончании дня
+	Project string
+
+	// Время работы в сессии для напоминания о перерыве
+	Worked time.Duration
+}
+
+// ReminderSettings - расписание напоминаний
+type ReminderSettings struct {
+	// Интервал напоминаний о перерыве от начала работы в сессии (0 - отключить)
+	BreakEvery time.Duration
+
+	// Рабочие часы от полуночи, WorkEnd == 0 отключает напоминания
+	// о незапущенном отслеживании и об окончании рабочего дня
+	WorkStart time.Duration
+	WorkEnd   time.Duration
+
+	// Интервал повтора напоминаний о незапущенном отслеживании
+	// и об отслеживании после окончания рабочего дня
+	Interval time.Duration
+}
+
+// ParseWorkHours - разбор рабочих часов в формате "09:00-18:00", пустая строка - не заданы
+func ParseWorkHours(value string) (time.Duration, time.Duration, error) {
+	if value == "" {
+		return 0, 0, nil
+	}
+
+	var startH, startM, endH, endM int
+	if _, err := fmt.Sscanf(value, "%d:%d-%d:%d", &startH, &startM, &endH, &endM); err != nil {
+		return 0, 0, fmt.Errorf("неверный формат рабочих часов '%s' (ожидается ЧЧ:ММ-ЧЧ:ММ)", value)
+	}
+
+	start := time.Duration(startH)*time.Hour + time.Duration(startM)*time.Minute
+	end := time.Duration(endH)*time.Hour + time.Duration(endM)*time.Minute
+	if startM > 59 || endM > 59 || start >= end || end > 24*time.Hour {
+		return 0, 0, fmt.Errorf("неверные рабочие часы '%s'", value)
+	}
+
+	return start, end, nil
+}
+
+// Session - запущенное отслеживание для планировщика
+type Session struct {
+	Project string
+	Elapsed time.Duration
+	Paused  bool
+}
+
+// Scheduler - планировщик напоминаний. Таймеры напоминаний о перерыве
+// принадлежат сессиям и отменяются при их остановке или паузе.
+type Scheduler struct {
+	Settings ReminderSettings
+	Clock    clock.Clock
+	Notify   func(Reminder)
+
+	mu       sync.Mutex
+	sessions map[string]*sessionTimer
+	active   []string
+	forgot   *sessionTimer
+	endOfDay *sessionTimer
+	stopped  bool
+}
+
+// sessionTimer - таймер напоминания; сработавший таймер проверяет,
+// что его не заменили и не отменили
+type sessionTimer struct {
+	timer clock.Timer
+}
+
+// NewScheduler - создание планировщика напоминаний
+func NewScheduler(settings ReminderSettings, clk clock.Clock, notify func(Reminder)) *Scheduler {
+	return &Scheduler{
+		Settings: settings,
+		Clock:    clk,
+		Notify:   notify,
+		sessions: make(map[string]*sessionTimer),
+	}
+}
+
+// Sync - обновление таймеров по текущим сессиям: для новых запущенных сессий
+// назначаются напоминания о перерыве, для остановленных и приостановленных отменяются
+func (s *Scheduler) Sync(sessions []Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+
+	running := make(map[string]bool)
+	s.active = s.active[:0]
+	for _, session := range sessions {
+		s.active = append(s.active, session.Project)
+		if session.Paused {
+			continue
+		}
+
+		running[session.Project] = true
+		if _, ok := s.sessions[session.Project]; !ok && s.Settings.BreakEvery > 0 {
+			// Напоминание приходит каждые BreakEvery рабочего времени сессии
+			delay := s.Settings.BreakEvery - session.Elapsed%s.Settings.BreakEvery
+			s.armBreakLocked(session.Project, session.Elapsed+delay, delay)
+		}
+	}
+
+	for project, t := range s.sessions {
+		if !running[project] {
+			t.timer.Stop()
+			delete(s.sessions, project)
+		}
+	}
+
+	s.armDayLocked()
+}
+
+// Stop - отмена всех напоминаний
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	for project, t := range s.sessions {
+		t.timer.Stop()
+		delete(s.sessions, project)
+	}
+	s.cancelLocked(&s.forgot)
+	s.cancelLocked(&s.endOfDay)
+}
+
+// Snooze - повтор напоминания через d вместо очередного по расписанию
+func (s *Scheduler) Snooze(reminder Reminder, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+
+	switch reminder.Kind {
+	case ReminderBreak:
+		t, ok := s.sessions[reminder.Project]
+		if !ok {
+			return
+		}
+		t.timer.Stop()
+		s.armBreakLocked(reminder.Project, reminder.Worked+d, d)
+	case ReminderEndOfDay:
+		if s.endOfDay == nil {
+			return
+		}
+		s.cancelLocked(&s.endOfDay)
+		s.endOfDay = s.armDayTimerLocked(d, &s.endOfDay, ReminderEndOfDay)
+	}
+}
+
+// armBreakLocked - напоминание о перерыве через delay, когда в сессии
+// будет отработано worked; вызывается под s.mu
+func (s *Scheduler) armBreakLocked(project string, worked, delay time.Duration) {
+	t := &sessionTimer{}
+	t.timer = s.Clock.AfterFunc(delay, func() {
+		s.mu.Lock()
+		if s.sessions[project] != t {
+			s.mu.Unlock()
+			return
+		}
+		s.armBreakLocked(project, worked+s.Settings.BreakEvery, s.Settings.BreakEvery)
+		s.mu.Unlock()
+
+		s.send(Reminder{Kind: ReminderBreak, Project: project, Worked: worked})
+	})
+	s.sessions[project] = t
+}
+
+// armDayLocked - напоминание о незапущенном отслеживании в рабочее время
+// или о запущенном после его окончания; вызывается под s.mu
+func (s *Scheduler) armDayLocked() {
+	if s.Settings.WorkEnd == 0 || s.Settings.Interval <= 0 {
+		return
+	}
+
+	now := s.Clock.Now()
+	year, month, day := now.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	workStart := midnight.Add(s.Settings.WorkStart)
+	workEnd := midnight.Add(s.Settings.WorkEnd)
+
+	if len(s.active) == 0 {
+		s.cancelLocked(&s.endOfDay)
+		if s.forgot != nil {
+			return
+		}
+
+		at := now
+		if at.Before(workStart) {
+			at = workStart
+		}
+		at = at.Add(s.Settings.Interval)
+		if !at.Before(workEnd) {
+			at = time.Date(year, month, day+1, 0, 0, 0, 0, now.Location()).Add(s.Settings.WorkStart + s.Settings.Interval)
+		}
+		s.forgot = s.armDayTimerLocked(at.Sub(now), &s.forgot, ReminderForgotStart)
+		return
+	}
+
+	s.cancelLocked(&s.forgot)
+	if s.endOfDay != nil {
+		return
+	}
+
+	delay := workEnd.Sub(now)
+	if delay <= 0 {
+		delay = s.Settings.Interval
+	}
+	s.endOfDay = s.armDayTimerLocked(delay, &s.endOfDay, ReminderEndOfDay)
+}
+
+// armDayTimerLocked - таймер напоминания, после срабатывания которого
+// следующее назначается заново по расписанию; вызывается под s.mu
+func (s *Scheduler) armDayTimerLocked(delay time.Duration, slot **sessionTimer, kind ReminderKind) *sessionTimer {
+	t := &sessionTimer{}
+	t.timer = s.Clock.AfterFunc(delay, func() {
+		s.mu.Lock()
+		if *slot != t {
+			s.mu.Unlock()
+			return
+		}
+		*slot = nil
+
+		reminder := Reminder{Kind: kind}
+		if kind == ReminderEndOfDay && len(s.active) > 0 {
+			reminder.Project = s.active[0]
+		}
+
+		// Следующее напоминание, пока состояние не изменится
+		s.armDayLocked()
+		s.mu.Unlock()
+
+		s.send(reminder)
+	})
+
+	return t
+}
+
+// cancelLocked - отмена таймера в слоте, вызывается под s.mu
+func (s *Scheduler) cancelLocked(slot **sessionTimer) {
+	if *slot != nil {
+		(*slot).timer.Stop()
+		*slot = nil
+	}
+}
+
+// send - отправка напоминания
+func (s *Scheduler) send(reminder Reminder) {
+	if s.Notify != nil {
+		s.Notify(reminder)
+	}
+}
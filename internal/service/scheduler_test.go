@@ -0,0 +1,291 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/pkg/clock"
+	"github.com/MWT-proger/time-tracking/pkg/notify"
+)
+
+// sentReminder - напоминание и время его отправки
+type sentReminder struct {
+	At       time.Time
+	Reminder Reminder
+}
+
+// newTestScheduler - планировщик на поддельных часах, показывающих now,
+// и список отправленных им напоминаний
+func newTestScheduler(settings ReminderSettings, now time.Time) (*Scheduler, *clock.Fake, *[]sentReminder) {
+	clk := clock.NewFake(now)
+	var sent []sentReminder
+	scheduler := NewScheduler(settings, clk, func(reminder Reminder) {
+		sent = append(sent, sentReminder{At: clk.Now(), Reminder: reminder})
+	})
+
+	return scheduler, clk, &sent
+}
+
+// checkSent - сравнение отправленных напоминаний с ожидаемыми, сбрасывает список
+func checkSent(t *testing.T, sent *[]sentReminder, want ...sentReminder) {
+	t.Helper()
+
+	defer func() { *sent = nil }()
+	if len(*sent) != len(want) {
+		t.Errorf("отправлено %+v, ожидалось %+v", *sent, want)
+		return
+	}
+	for i := range want {
+		if !(*sent)[i].At.Equal(want[i].At) || (*sent)[i].Reminder != want[i].Reminder {
+			t.Errorf("напоминание %d: %+v, ожидалось %+v", i, (*sent)[i], want[i])
+		}
+	}
+}
+
+func TestSchedulerBreak(t *testing.T) {
+	start := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	scheduler, clk, sent := newTestScheduler(ReminderSettings{BreakEvery: 50 * time.Minute}, start)
+
+	// Сессия уже отработала 20 минут, первое напоминание через 30
+	scheduler.Sync([]Session{{Project: "alpha", Elapsed: 20 * time.Minute}})
+	clk.Advance(90 * time.Minute)
+	checkSent(t, sent,
+		sentReminder{start.Add(30 * time.Minute), Reminder{Kind: ReminderBreak, Project: "alpha", Worked: 50 * time.Minute}},
+		sentReminder{start.Add(80 * time.Minute), Reminder{Kind: ReminderBreak, Project: "alpha", Worked: 100 * time.Minute}},
+	)
+
+	// Повторная синхронизация не пересоздает таймер сессии
+	scheduler.Sync([]Session{{Project: "alpha", Elapsed: 110 * time.Minute}})
+	if pending := clk.Pending(); pending != 1 {
+		t.Errorf("ожидающих таймеров %d, ожидался 1", pending)
+	}
+
+	// Пауза отменяет напоминания, продолжение назначает их по рабочему времени
+	scheduler.Sync([]Session{{Project: "alpha", Elapsed: 110 * time.Minute, Paused: true}})
+	clk.Advance(time.Hour)
+	checkSent(t, sent)
+
+	resumed := clk.Now()
+	scheduler.Sync([]Session{{Project: "alpha", Elapsed: 110 * time.Minute}})
+	clk.Advance(40 * time.Minute)
+	checkSent(t, sent,
+		sentReminder{resumed.Add(40 * time.Minute), Reminder{Kind: ReminderBreak, Project: "alpha", Worked: 150 * time.Minute}},
+	)
+
+	// Остановка сессии отменяет напоминания
+	scheduler.Sync(nil)
+	clk.Advance(2 * time.Hour)
+	checkSent(t, sent)
+	if pending := clk.Pending(); pending != 0 {
+		t.Errorf("ожидающих таймеров %d после остановки сессии", pending)
+	}
+}
+
+func TestSchedulerForgotStart(t *testing.T) {
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	settings := ReminderSettings{WorkStart: 9 * time.Hour, WorkEnd: 18 * time.Hour, Interval: 30 * time.Minute}
+
+	tests := []struct {
+		name  string
+		now   time.Time
+		until time.Duration
+		want  []time.Time
+	}{
+		{
+			name:  "до начала рабочего дня",
+			now:   day.Add(8 * time.Hour),
+			until: 2 * time.Hour,
+			want:  []time.Time{day.Add(9*time.Hour + 30*time.Minute), day.Add(10 * time.Hour)},
+		},
+		{
+			name:  "в рабочее время",
+			now:   day.Add(12*time.Hour + 10*time.Minute),
+			until: time.Hour,
+			want:  []time.Time{day.Add(12*time.Hour + 40*time.Minute), day.Add(13*time.Hour + 10*time.Minute)},
+		},
+		{
+			name:  "конец дня переносит напоминание на следующий день",
+			now:   day.Add(17*time.Hour + 20*time.Minute),
+			until: 16*time.Hour + 30*time.Minute,
+			want:  []time.Time{day.Add(17*time.Hour + 50*time.Minute), day.Add(33*time.Hour + 30*time.Minute)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler, clk, sent := newTestScheduler(settings, tt.now)
+			scheduler.Sync(nil)
+			clk.Advance(tt.until)
+
+			var want []sentReminder
+			for _, at := range tt.want {
+				want = append(want, sentReminder{at, Reminder{Kind: ReminderForgotStart}})
+			}
+			checkSent(t, sent, want...)
+		})
+	}
+
+	// Запуск отслеживания отменяет напоминание
+	scheduler, clk, sent := newTestScheduler(settings, day.Add(9*time.Hour))
+	scheduler.Sync(nil)
+	clk.Advance(10 * time.Minute)
+	scheduler.Sync([]Session{{Project: "alpha"}})
+	clk.Advance(time.Hour)
+	checkSent(t, sent)
+}
+
+func TestSchedulerEndOfDay(t *testing.T) {
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	settings := ReminderSettings{WorkStart: 9 * time.Hour, WorkEnd: 18 * time.Hour, Interval: 30 * time.Minute}
+
+	scheduler, clk, sent := newTestScheduler(settings, day.Add(17*time.Hour))
+	scheduler.Sync([]Session{{Project: "alpha"}, {Project: "beta"}})
+	clk.Advance(time.Hour + 45*time.Minute)
+
+	endOfDay := Reminder{Kind: ReminderEndOfDay, Project: "alpha"}
+	checkSent(t, sent,
+		sentReminder{day.Add(18 * time.Hour), endOfDay},
+		sentReminder{day.Add(18*time.Hour + 30*time.Minute), endOfDay},
+	)
+
+	// Остановка последней сессии отменяет напоминание
+	scheduler.Sync(nil)
+	clk.Advance(time.Hour)
+	checkSent(t, sent)
+
+	// Запуск после окончания дня напоминает через интервал
+	started := clk.Now()
+	scheduler.Sync([]Session{{Project: "beta"}})
+	clk.Advance(40 * time.Minute)
+	checkSent(t, sent, sentReminder{started.Add(30 * time.Minute), Reminder{Kind: ReminderEndOfDay, Project: "beta"}})
+}
+
+func TestSchedulerSnooze(t *testing.T) {
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	settings := ReminderSettings{
+		BreakEvery: 50 * time.Minute,
+		WorkStart:  9 * time.Hour,
+		WorkEnd:    18 * time.Hour,
+		Interval:   30 * time.Minute,
+	}
+
+	scheduler, clk, sent := newTestScheduler(settings, day.Add(17*time.Hour))
+	scheduler.Sync([]Session{{Project: "alpha"}})
+
+	clk.Advance(50 * time.Minute)
+	breakReminder := Reminder{Kind: ReminderBreak, Project: "alpha", Worked: 50 * time.Minute}
+	checkSent(t, sent, sentReminder{day.Add(17*time.Hour + 50*time.Minute), breakReminder})
+
+	// Отложенное напоминание о перерыве приходит через 10 минут, следующее - через BreakEvery после него
+	scheduler.Snooze(breakReminder, 10*time.Minute)
+	clk.Advance(10 * time.Minute)
+	endOfDay := Reminder{Kind: ReminderEndOfDay, Project: "alpha"}
+	checkSent(t, sent,
+		sentReminder{day.Add(18 * time.Hour), endOfDay},
+		sentReminder{day.Add(18 * time.Hour), Reminder{Kind: ReminderBreak, Project: "alpha", Worked: time.Hour}},
+	)
+
+	// Отложенное напоминание об окончании дня заменяет очередное по расписанию
+	scheduler.Snooze(endOfDay, time.Hour)
+	clk.Advance(70 * time.Minute)
+	checkSent(t, sent,
+		sentReminder{day.Add(18*time.Hour + 50*time.Minute), Reminder{Kind: ReminderBreak, Project: "alpha", Worked: 110 * time.Minute}},
+		sentReminder{day.Add(19 * time.Hour), endOfDay},
+	)
+
+	// Отложить напоминание остановленной сессии нельзя
+	scheduler.Sync(nil)
+	scheduler.Snooze(breakReminder, 10*time.Minute)
+	scheduler.Snooze(endOfDay, 10*time.Minute)
+	clk.Advance(20 * time.Minute)
+	checkSent(t, sent)
+}
+
+func TestSchedulerStop(t *testing.T) {
+	settings := ReminderSettings{
+		BreakEvery: 50 * time.Minute,
+		WorkStart:  9 * time.Hour,
+		WorkEnd:    18 * time.Hour,
+		Interval:   30 * time.Minute,
+	}
+
+	scheduler, clk, sent := newTestScheduler(settings, time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC))
+	scheduler.Sync([]Session{{Project: "alpha"}})
+	scheduler.Stop()
+
+	if pending := clk.Pending(); pending != 0 {
+		t.Errorf("ожидающих таймеров %d после остановки", pending)
+	}
+
+	scheduler.Sync([]Session{{Project: "beta"}})
+	scheduler.Snooze(Reminder{Kind: ReminderBreak, Project: "alpha"}, time.Minute)
+	clk.Advance(24 * time.Hour)
+	checkSent(t, sent)
+}
+
+func TestParseWorkHours(t *testing.T) {
+	tests := []struct {
+		value      string
+		start, end time.Duration
+		wantErr    bool
+	}{
+		{value: ""},
+		{value: "09:00-18:00", start: 9 * time.Hour, end: 18 * time.Hour},
+		{value: "08:30-24:00", start: 8*time.Hour + 30*time.Minute, end: 24 * time.Hour},
+		{value: "18:00-09:00", wantErr: true},
+		{value: "09:60-18:00", wantErr: true},
+		{value: "9-18", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			start, end, err := ParseWorkHours(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+			if start != tt.start || end != tt.end {
+				t.Errorf("рабочие часы %v-%v, ожидалось %v-%v", start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestSendReminderActions(t *testing.T) {
+	s := newTestServices(t, nil)
+	recorder := &notify.Recorder{}
+	s.tracking.Notifier = recorder
+	s.tracking.SnoozeDuration = 10 * time.Minute
+
+	var stopped []string
+	s.tracking.OnStopAction = func(project string) { stopped = append(stopped, project) }
+
+	scheduler, clk, _ := newTestScheduler(ReminderSettings{BreakEvery: 50 * time.Minute}, time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC))
+	scheduler.Notify = s.tracking.SendReminder
+	s.tracking.Scheduler = scheduler
+	scheduler.Sync([]Session{{Project: "alpha"}})
+
+	clk.Advance(50 * time.Minute)
+	notifications := recorder.Notifications()
+	if len(notifications) != 1 || len(notifications[0].Actions) != 2 {
+		t.Fatalf("уведомления %+v", notifications)
+	}
+
+	// Отложенное напоминание приходит через SnoozeDuration
+	recorder.Invoke(notify.ActionSnooze)
+	clk.Advance(10 * time.Minute)
+	if notifications := recorder.Notifications(); len(notifications) != 2 {
+		t.Errorf("уведомлений после откладывания %d, ожидалось 2", len(notifications))
+	}
+
+	recorder.Invoke(notify.ActionStop)
+	if len(stopped) != 1 || stopped[0] != "alpha" {
+		t.Errorf("остановлены проекты %v", stopped)
+	}
+
+	// Напоминание без проекта отправляется без действий
+	s.tracking.SendReminder(Reminder{Kind: ReminderForgotStart})
+	notifications = recorder.Notifications()
+	if last := notifications[len(notifications)-1]; len(last.Actions) != 0 || last.OnAction != nil {
+		t.Errorf("напоминание без проекта с действиями: %+v", last)
+	}
+}
@@ -1,21 +1,38 @@
 package service
 
 import (
+	"errors"
 	"fmt"
-	"os/exec"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/MWT-proger/time-tracking/internal/domain"
 	"github.com/MWT-proger/time-tracking/pkg/config"
 	"github.com/MWT-proger/time-tracking/pkg/logger"
+	"github.com/MWT-proger/time-tracking/pkg/notify"
 	"github.com/google/uuid"
 )
 
+// ErrTimerRunning - в режиме одного таймера запущено отслеживание другого проекта
+var ErrTimerRunning = errors.New("уже запущено отслеживание другого проекта")
+
 // TrackingService - сервис для отслеживания времени
 type TrackingService struct {
 	ProjectService   *ProjectService
 	Logger           logger.Logger
 	NotificationTime int
+	TimerMode        string
+
+	// Планировщик напоминаний, nil в неинтерактивных командах
+	Scheduler *Scheduler
+
+	// Отправитель уведомлений и время, на которое откладывается напоминание
+	Notifier       notify.Notifier
+	SnoozeDuration time.Duration
+
+	// Остановка отслеживания из уведомления
+	OnStopAction func(project string)
 }
 
 // NewTrackingService - создание нового сервиса отслеживания
@@ -24,6 +41,9 @@ func NewTrackingService(projectService *ProjectService, log logger.Logger, cfg *
 		ProjectService:   projectService,
 		Logger:           log,
 		NotificationTime: cfg.NotificationTime,
+		TimerMode:        cfg.TimerMode,
+		Notifier:         notify.Noop{},
+		SnoozeDuration:   cfg.Snooze,
 	}
 }
 
@@ -35,10 +55,18 @@ func (s *TrackingService) StartTracking(data map[string]*domain.Project, name st
 		return fmt.Errorf("проект '%s' не существует", name)
 	}
 
+	if project.Archived {
+		return fmt.Errorf("проект '%s' находится в архиве", name)
+	}
+
 	if project.StartTime != nil {
 		return fmt.Errorf("отслеживание для проекта '%s' уже запущено", name)
 	}
 
+	if running := s.ConflictingSessions(data, name); len(running) > 0 {
+		return fmt.Errorf("%w: %s", ErrTimerRunning, strings.Join(running, ", "))
+	}
+
 	// Проверяем, есть ли у проекта активный этап
 	if project.Sprints != nil && len(project.Sprints) > 0 && project.ActiveSprint != "" {
 		if _, exists := project.Sprints[project.ActiveSprint]; !exists {
@@ -49,70 +77,379 @@ func (s *TrackingService) StartTracking(data map[string]*domain.Project, name st
 
 	now := time.Now()
 	project.StartTime = &now
+	project.PausedAt = nil
+	project.Breaks = nil
+
+	s.SyncReminders(data)
+
+	return s.ProjectService.SaveProject(data, name)
+}
+
+// ConflictingSessions - запущенные сессии, которые нужно остановить перед
+// запуском отслеживания проекта name. В режиме нескольких таймеров их нет.
+func (s *TrackingService) ConflictingSessions(data map[string]*domain.Project, name string) []string {
+	if s.TimerMode != config.TimerModeSingle {
+		return nil
+	}
+
+	var names []string
+	for _, active := range s.ActiveProjects(data) {
+		if active != name {
+			names = append(names, active)
+		}
+	}
+
+	return names
+}
 
-	return s.ProjectService.SaveData(data)
+// PauseTracking - приостановка запущенного отслеживания
+func (s *TrackingService) PauseTracking(data map[string]*domain.Project, name string) error {
+	s.Logger.Debugf("Попытка приостановить отслеживание для проекта: %s", name)
+	project, exists := data[name]
+	if !exists || project.StartTime == nil {
+		return fmt.Errorf("отслеживание для проекта '%s' не запущено", name)
+	}
+
+	if project.PausedAt != nil {
+		return fmt.Errorf("отслеживание для проекта '%s' уже приостановлено", name)
+	}
+
+	now := time.Now()
+	project.PausedAt = &now
+
+	s.SyncReminders(data)
+
+	return s.ProjectService.SaveProject(data, name)
+}
+
+// ResumeTracking - продолжение приостановленного отслеживания,
+// возвращает длительность перерыва
+func (s *TrackingService) ResumeTracking(data map[string]*domain.Project, name string) (time.Duration, error) {
+	s.Logger.Debugf("Попытка продолжить отслеживание для проекта: %s", name)
+	project, exists := data[name]
+	if !exists || project.StartTime == nil {
+		return 0, fmt.Errorf("отслеживание для проекта '%s' не запущено", name)
+	}
+
+	if project.PausedAt == nil {
+		return 0, fmt.Errorf("отслеживание для проекта '%s' не приостановлено", name)
+	}
+
+	pause := domain.Break{Start: *project.PausedAt, End: time.Now()}
+	project.Breaks = append(project.Breaks, pause)
+	project.PausedAt = nil
+
+	s.SyncReminders(data)
+
+	if err := s.ProjectService.SaveProject(data, name); err != nil {
+		return 0, err
+	}
+
+	return pause.End.Sub(pause.Start), nil
+}
+
+// SessionOverlap - часть периода [start, end], приходящаяся на работу
+// в текущей сессии проекта (до паузы или до now)
+func (s *TrackingService) SessionOverlap(project *domain.Project, start, end, now time.Time) (domain.Break, bool) {
+	if project == nil || project.StartTime == nil {
+		return domain.Break{}, false
+	}
+
+	sessionEnd := now
+	if project.PausedAt != nil {
+		sessionEnd = *project.PausedAt
+	}
+
+	if start.Before(*project.StartTime) {
+		start = *project.StartTime
+	}
+	if end.After(sessionEnd) {
+		end = sessionEnd
+	}
+	if !start.Before(end) {
+		return domain.Break{}, false
+	}
+
+	return domain.Break{Start: start, End: end}, true
+}
+
+// ExcludeSpan - исключение периода из текущей сессии, например времени
+// отсутствия за компьютером. Период добавляется к перерывам сессии и
+// не попадает в запись, которую создаст StopTracking. Возвращает
+// длительность, на которую уменьшилось рабочее время.
+func (s *TrackingService) ExcludeSpan(data map[string]*domain.Project, name string, start, end time.Time) (time.Duration, error) {
+	s.Logger.Debugf("Исключение периода %s - %s из сессии проекта: %s", start.Format(time.RFC3339), end.Format(time.RFC3339), name)
+	project, exists := data[name]
+	if !exists || project.StartTime == nil {
+		return 0, fmt.Errorf("отслеживание для проекта '%s' не запущено", name)
+	}
+
+	span, ok := s.SessionOverlap(project, start, end, time.Now())
+	if !ok {
+		return 0, fmt.Errorf("период не пересекается с сессией проекта '%s'", name)
+	}
+
+	before := domain.BreaksDuration(project.Breaks)
+	project.Breaks = domain.MergeBreaks(append(project.Breaks, span))
+	excluded := domain.BreaksDuration(project.Breaks) - before
+
+	if err := s.ProjectService.SaveProject(data, name); err != nil {
+		return 0, err
+	}
+
+	return excluded, nil
 }
 
 // StopTracking - остановка отслеживания времени
 func (s *TrackingService) StopTracking(data map[string]*domain.Project, name string, description string) (time.Duration, error) {
 	s.Logger.Debugf("Попытка остановить отслеживание для проекта: %s", name)
+	return s.stopSession(data, name, description, 0)
+}
+
+// CapTracking - остановка сессии, оставленной запущенной слишком долго.
+// В запись попадает не больше limit рабочего времени от начала сессии.
+func (s *TrackingService) CapTracking(data map[string]*domain.Project, name string, description string, limit time.Duration) (time.Duration, error) {
+	s.Logger.Infof("Остановка сессии проекта %s с ограничением %v", name, limit)
+	return s.stopSession(data, name, description, limit)
+}
+
+// DiscardTracking - отмена запущенной сессии без сохранения записи
+func (s *TrackingService) DiscardTracking(data map[string]*domain.Project, name string) error {
+	s.Logger.Infof("Отмена сессии проекта: %s", name)
+	project, exists := data[name]
+	if !exists || project.StartTime == nil {
+		return fmt.Errorf("отслеживание для проекта '%s' не запущено", name)
+	}
+
+	project.StartTime = nil
+	project.PausedAt = nil
+	project.Breaks = nil
+
+	s.SyncReminders(data)
+
+	return s.ProjectService.SaveProject(data, name)
+}
+
+// LongSessions - проекты, сессия которых длится дольше limit
+func (s *TrackingService) LongSessions(data map[string]*domain.Project, now time.Time, limit time.Duration) []string {
+	var names []string
+
+	for _, name := range s.ActiveProjects(data) {
+		if data[name].Elapsed(now) > limit {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// stopSession - завершение сессии с созданием записи, limit > 0 ограничивает её длительность
+func (s *TrackingService) stopSession(data map[string]*domain.Project, name string, description string, limit time.Duration) (time.Duration, error) {
 	project, exists := data[name]
 	if !exists || project.StartTime == nil {
 		return 0, fmt.Errorf("отслеживание для проекта '%s' не запущено", name)
 	}
 
-	elapsed := time.Since(*project.StartTime)
-	seconds := int(elapsed.Seconds())
+	// Остановка во время паузы завершает сессию в момент паузы
+	end := time.Now()
+	if project.PausedAt != nil {
+		end = *project.PausedAt
+	}
 
 	entry := domain.TimeEntry{
-		Date:        time.Now().Format("2006-01-02 15:04:05"),
-		TimeSpent:   seconds,
+		ID:          uuid.New().String(),
+		Start:       *project.StartTime,
+		End:         end,
 		Description: description,
+		Breaks:      project.Breaks,
+	}
+	if limit > 0 && entry.Duration() > limit {
+		entry = withDuration(entry, limit)
 	}
 
-	// Если у проекта есть активный этап, добавляем запись к нему
+	// Если у проекта есть активный этап, запись относится к нему
 	if project.Sprints != nil && project.ActiveSprint != "" {
-		if sprint, exists := project.Sprints[project.ActiveSprint]; exists {
-			if sprint.Entries == nil {
-				sprint.Entries = make(map[string]domain.TimeEntry)
-			}
-			entryID := uuid.New().String()
-			sprint.Entries[entryID] = entry
+		if _, exists := project.Sprints[project.ActiveSprint]; exists {
+			entry.SprintID = project.ActiveSprint
 		}
 	}
 
-	// В любом случае добавляем запись к проекту для обратной совместимости
-	if project.Entries == nil {
-		project.Entries = []domain.TimeEntry{}
+	// Сессия без рабочего времени, например приостановленная сразу после
+	// запуска, завершается без записи нулевой длительности
+	elapsed := entry.Duration()
+	if elapsed > 0 {
+		project.Entries = append(project.Entries, entry)
+	} else {
+		s.Logger.Infof("Сессия проекта %s завершена без записи: нет рабочего времени", name)
+		elapsed = 0
 	}
-	project.Entries = append(project.Entries, entry)
 
 	project.StartTime = nil
+	project.PausedAt = nil
+	project.Breaks = nil
+
+	s.SyncReminders(data)
 
-	if err := s.ProjectService.SaveData(data); err != nil {
+	if err := s.ProjectService.SaveProject(data, name); err != nil {
 		return 0, err
 	}
 
 	return elapsed, nil
 }
 
-// Notify - отправка уведомления
-func (s *TrackingService) Notify(project string) error {
-	s.Logger.Debugf("Отправка уведомления для проекта: %s", project)
-	return exec.Command("notify-send", "Оповещение", fmt.Sprintf("Вы работаете над проектом '%s' уже %d минут! Время сделать перерыв.", project, s.NotificationTime/60)).Run()
+// SyncReminders - обновление напоминаний планировщика по запущенным сессиям
+func (s *TrackingService) SyncReminders(data map[string]*domain.Project) {
+	if s.Scheduler == nil {
+		return
+	}
+
+	now := time.Now()
+	var sessions []Session
+	for _, name := range s.ActiveProjects(data) {
+		project := data[name]
+		sessions = append(sessions, Session{
+			Project: name,
+			Elapsed: project.Elapsed(now),
+			Paused:  project.PausedAt != nil,
+		})
+	}
+
+	s.Scheduler.Sync(sessions)
+}
+
+// SendReminder - отправка напоминания планировщика. Напоминания о запущенной
+// сессии можно отложить или остановить отслеживание прямо из уведомления.
+func (s *TrackingService) SendReminder(reminder Reminder) {
+	s.Logger.Infof("Напоминание %s: %s", reminder.Kind, reminder.Project)
+
+	var notification notify.Notification
+	switch reminder.Kind {
+	case ReminderBreak:
+		notification = notify.BreakReminder(reminder.Project, reminder.Worked)
+	case ReminderForgotStart:
+		notification = notify.Notification{Title: "Оповещение", Message: "Рабочее время идет, а отслеживание не запущено."}
+	case ReminderEndOfDay:
+		notification = notify.Notification{
+			Title:   "Оповещение",
+			Message: fmt.Sprintf("Рабочий день закончился, а отслеживание проекта '%s' все еще запущено.", reminder.Project),
+		}
+	}
+
+	if reminder.Project != "" {
+		notification.Actions = []notify.Action{
+			{Key: notify.ActionStop, Label: "Остановить таймер"},
+			{Key: notify.ActionSnooze, Label: "Отложить"},
+		}
+		notification.OnAction = func(key string) {
+			s.reminderAction(reminder, key)
+		}
+	}
+
+	if err := s.Notifier.Notify(notification); err != nil {
+		s.Logger.Warnf("Ошибка отправки напоминания: %v", err)
+	}
 }
 
-// Summary - вывод сводки по проектам
-func (s *TrackingService) Summary(data map[string]*domain.Project) map[string]int {
-	result := make(map[string]int)
+// reminderAction - обработка действия, выбранного в уведомлении
+func (s *TrackingService) reminderAction(reminder Reminder, key string) {
+	s.Logger.Infof("Выбрано действие %s в напоминании о проекте %s", key, reminder.Project)
+
+	switch key {
+	case notify.ActionStop:
+		if s.OnStopAction != nil {
+			s.OnStopAction(reminder.Project)
+		}
+	case notify.ActionSnooze:
+		if s.Scheduler != nil {
+			s.Scheduler.Snooze(reminder, s.SnoozeDuration)
+		}
+	}
+}
+
+// PausedProjects - получение отсортированного списка проектов с приостановленным отслеживанием
+func (s *TrackingService) PausedProjects(data map[string]*domain.Project) []string {
+	var names []string
+
+	for name, project := range data {
+		if project.StartTime != nil && project.PausedAt != nil {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// ActiveProjects - получение отсортированного списка проектов с запущенным отслеживанием
+func (s *TrackingService) ActiveProjects(data map[string]*domain.Project) []string {
+	var names []string
 
 	for name, project := range data {
-		var total int
+		if project.StartTime != nil {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// ProjectSummary - итоги времени по проекту в секундах
+type ProjectSummary struct {
+	Total   int
+	Sprints map[string]int
+}
+
+// Summary - подсчет итогов по проектам и их спринтам (по ID спринта)
+func (s *TrackingService) Summary(data map[string]*domain.Project) map[string]ProjectSummary {
+	result := make(map[string]ProjectSummary)
+
+	for name, project := range data {
+		summary := ProjectSummary{Sprints: make(map[string]int)}
 		for _, entry := range project.Entries {
-			total += entry.TimeSpent
+			seconds := int(entry.Duration().Seconds())
+			summary.Total += seconds
+			if entry.SprintID != "" {
+				summary.Sprints[entry.SprintID] += seconds
+			}
 		}
-		result[name] = total
+		result[name] = summary
 	}
 
 	return result
 }
+
+// DayTotal - время проекта за один календарный день в секундах
+type DayTotal struct {
+	Day     string
+	Seconds int
+}
+
+// DailyTotals - время проекта по календарным дням в часовом поясе loc,
+// отсортированное по дате
+func (s *TrackingService) DailyTotals(data map[string]*domain.Project, name string, loc *time.Location) ([]DayTotal, error) {
+	project, exists := data[name]
+	if !exists {
+		return nil, fmt.Errorf("проект '%s' не существует", name)
+	}
+
+	totals := make(map[string]time.Duration)
+	for _, entry := range project.Entries {
+		for _, part := range entry.SplitByDay(loc) {
+			totals[part.Day] += part.Duration
+		}
+	}
+
+	result := make([]DayTotal, 0, len(totals))
+	for day, duration := range totals {
+		result = append(result, DayTotal{Day: day, Seconds: int(duration.Seconds())})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Day < result[j].Day
+	})
+
+	return result, nil
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/internal/domain"
+	"github.com/MWT-proger/time-tracking/internal/storage"
+	"github.com/MWT-proger/time-tracking/pkg/config"
+	"github.com/MWT-proger/time-tracking/pkg/logger"
+)
+
+// testServices - сервисы поверх хранилища в памяти и загруженные из него проекты
+type testServices struct {
+	projects *ProjectService
+	tracking *TrackingService
+	entries  *EntryService
+	reports  *ReportService
+	data     map[string]*domain.Project
+}
+
+// newTestServices - сервисы с пустыми проектами names
+func newTestServices(t *testing.T, cfg *config.Config, names ...string) *testServices {
+	t.Helper()
+
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	log := logger.NewLogger("error", io.Discard)
+
+	projectService := NewProjectService(log, storage.NewMemoryStorage(CurrentSchemaVersion))
+	data, err := projectService.LoadData()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range names {
+		if err := projectService.CreateProject(data, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return &testServices{
+		projects: projectService,
+		tracking: NewTrackingService(projectService, log, cfg),
+		entries:  NewEntryService(projectService, log),
+		reports:  NewReportService(projectService, log),
+		data:     data,
+	}
+}
+
+// reload - проекты в том виде, в котором они сохранены в хранилище
+func (s *testServices) reload(t *testing.T) map[string]*domain.Project {
+	t.Helper()
+
+	data, err := s.projects.LoadData()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+// shiftSession - перенос начала и паузы сессии в прошлое на d
+func (s *testServices) shiftSession(name string, d time.Duration) {
+	project := s.data[name]
+
+	start := project.StartTime.Add(-d)
+	project.StartTime = &start
+	if project.PausedAt != nil {
+		pausedAt := project.PausedAt.Add(-d)
+		project.PausedAt = &pausedAt
+	}
+	for i := range project.Breaks {
+		project.Breaks[i].Start = project.Breaks[i].Start.Add(-d)
+		project.Breaks[i].End = project.Breaks[i].End.Add(-d)
+	}
+}
+
+func TestStartTracking(t *testing.T) {
+	s := newTestServices(t, nil, "alpha", "archived")
+	s.data["archived"].Archived = true
+
+	tests := []struct {
+		name    string
+		project string
+		wantErr bool
+	}{
+		{"проект", "alpha", false},
+		{"повторный запуск", "alpha", true},
+		{"несуществующий проект", "beta", true},
+		{"архивный проект", "archived", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.tracking.StartTracking(s.data, tt.project)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if saved := s.reload(t)["alpha"]; saved.StartTime == nil {
+		t.Error("запуск не сохранен")
+	}
+}
+
+func TestStopTracking(t *testing.T) {
+	s := newTestServices(t, nil, "alpha")
+	if err := s.projects.CreateSprint(s.data, "alpha", "S1", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.tracking.StopTracking(s.data, "alpha", ""); err == nil {
+		t.Error("остановка незапущенного отслеживания без ошибки")
+	}
+
+	if err := s.tracking.StartTracking(s.data, "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	s.shiftSession("alpha", time.Hour)
+
+	elapsed, err := s.tracking.StopTracking(s.data, "alpha", "анализ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed < time.Hour || elapsed > time.Hour+time.Second {
+		t.Errorf("записано %v, ожидался час", elapsed)
+	}
+
+	saved := s.reload(t)["alpha"]
+	if saved.StartTime != nil || len(saved.Entries) != 1 {
+		t.Fatalf("сохраненный проект: %+v", saved)
+	}
+	entry := saved.Entries[0]
+	if entry.Description != "анализ" || entry.SprintID != saved.ActiveSprint || entry.Duration().Round(time.Second) != time.Hour {
+		t.Errorf("запись: %+v", entry)
+	}
+}
+
+func TestStopEmptySession(t *testing.T) {
+	s := newTestServices(t, nil, "alpha")
+
+	// Пауза в момент запуска: в сессии нет рабочего времени
+	if err := s.tracking.StartTracking(s.data, "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	pausedAt := *s.data["alpha"].StartTime
+	s.data["alpha"].PausedAt = &pausedAt
+
+	elapsed, err := s.tracking.StopTracking(s.data, "alpha", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed != 0 {
+		t.Errorf("записано %v, ожидалось 0", elapsed)
+	}
+
+	saved := s.reload(t)["alpha"]
+	if saved.StartTime != nil || saved.PausedAt != nil || len(saved.Entries) != 0 {
+		t.Errorf("сохраненный проект: %+v", saved)
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	s := newTestServices(t, nil, "alpha")
+
+	if err := s.tracking.PauseTracking(s.data, "alpha"); err == nil {
+		t.Error("пауза незапущенного отслеживания без ошибки")
+	}
+
+	if err := s.tracking.StartTracking(s.data, "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.tracking.ResumeTracking(s.data, "alpha"); err == nil {
+		t.Error("продолжение без паузы без ошибки")
+	}
+
+	if err := s.tracking.PauseTracking(s.data, "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.tracking.PauseTracking(s.data, "alpha"); err == nil {
+		t.Error("повторная пауза без ошибки")
+	}
+	if paused := s.tracking.PausedProjects(s.data); len(paused) != 1 || paused[0] != "alpha" {
+		t.Errorf("приостановленные проекты: %v", paused)
+	}
+
+	// Час работы, затем получасовой перерыв
+	s.shiftSession("alpha", 30*time.Minute)
+	start := s.data["alpha"].StartTime.Add(-time.Hour)
+	s.data["alpha"].StartTime = &start
+
+	pause, err := s.tracking.ResumeTracking(s.data, "alpha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pause < 30*time.Minute || pause > 30*time.Minute+time.Second {
+		t.Errorf("перерыв %v, ожидалось 30 минут", pause)
+	}
+
+	// Остановка во время паузы завершает запись в момент паузы
+	if err := s.tracking.PauseTracking(s.data, "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	pausedAt := *s.data["alpha"].PausedAt
+	s.shiftSession("alpha", time.Hour)
+
+	if _, err := s.tracking.StopTracking(s.data, "alpha", ""); err != nil {
+		t.Fatal(err)
+	}
+	entry := s.data["alpha"].Entries[0]
+	if !entry.End.Equal(pausedAt.Add(-time.Hour)) || len(entry.Breaks) != 1 {
+		t.Errorf("запись: %+v", entry)
+	}
+	if entry.Duration() < time.Hour || entry.Duration() > time.Hour+time.Second {
+		t.Errorf("длительность %v, ожидался час без перерыва", entry.Duration())
+	}
+}
+
+func TestTimerMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		wantErr bool
+	}{
+		{config.TimerModeMulti, false},
+		{config.TimerModeSingle, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.TimerMode = tt.mode
+			s := newTestServices(t, cfg, "alpha", "beta")
+
+			if err := s.tracking.StartTracking(s.data, "alpha"); err != nil {
+				t.Fatal(err)
+			}
+
+			conflicting := s.tracking.ConflictingSessions(s.data, "beta")
+			err := s.tracking.StartTracking(s.data, "beta")
+			if tt.wantErr {
+				if !errors.Is(err, ErrTimerRunning) || len(conflicting) != 1 || conflicting[0] != "alpha" {
+					t.Errorf("ошибка %v, конфликтующие сессии %v", err, conflicting)
+				}
+				return
+			}
+			if err != nil || len(conflicting) != 0 {
+				t.Errorf("ошибка %v, конфликтующие сессии %v", err, conflicting)
+			}
+		})
+	}
+}
+
+func TestCapDiscard(t *testing.T) {
+	s := newTestServices(t, nil, "alpha")
+
+	if err := s.tracking.StartTracking(s.data, "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	s.shiftSession("alpha", 24*time.Hour)
+
+	if long := s.tracking.LongSessions(s.data, time.Now(), 10*time.Hour); len(long) != 1 {
+		t.Errorf("долгие сессии: %v", long)
+	}
+
+	elapsed, err := s.tracking.CapTracking(s.data, "alpha", "", 10*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed != 10*time.Hour || s.data["alpha"].Entries[0].Duration() != 10*time.Hour {
+		t.Errorf("записано %v, ожидалось 10h", elapsed)
+	}
+
+	if err := s.tracking.StartTracking(s.data, "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.tracking.DiscardTracking(s.data, "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.tracking.DiscardTracking(s.data, "alpha"); err == nil {
+		t.Error("повторная отмена без ошибки")
+	}
+
+	saved := s.reload(t)["alpha"]
+	if saved.StartTime != nil || len(saved.Entries) != 1 {
+		t.Errorf("сохраненный проект после отмены: %+v", saved)
+	}
+}
+
+func TestExcludeSpan(t *testing.T) {
+	s := newTestServices(t, nil, "alpha")
+
+	if err := s.tracking.StartTracking(s.data, "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	s.shiftSession("alpha", 2*time.Hour)
+	start := *s.data["alpha"].StartTime
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     time.Duration
+		wantErr  bool
+	}{
+		{"период внутри сессии", start.Add(30 * time.Minute), start.Add(time.Hour), 30 * time.Minute, false},
+		{"пересечение с исключенным", start.Add(45 * time.Minute), start.Add(75 * time.Minute), 15 * time.Minute, false},
+		{"начало до сессии", start.Add(-time.Hour), start.Add(10 * time.Minute), 10 * time.Minute, false},
+		{"вне сессии", start.Add(-2 * time.Hour), start.Add(-time.Hour), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			excluded, err := s.tracking.ExcludeSpan(s.data, "alpha", tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+			if excluded != tt.want {
+				t.Errorf("исключено %v, ожидалось %v", excluded, tt.want)
+			}
+		})
+	}
+
+	if breaks := domain.BreaksDuration(s.data["alpha"].Breaks); breaks != 55*time.Minute {
+		t.Errorf("перерывы сессии %v, ожидалось 55m", breaks)
+	}
+}
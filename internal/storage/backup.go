@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupTimeFormat - формат метки времени в имени резервной копии
+const backupTimeFormat = "20060102-150405.000"
+
+// Backup - резервная копия файла данных
+type Backup struct {
+	Path string
+	Time time.Time
+	Size int64
+}
+
+// BackupStorage - хранилище, поддерживающее резервные копии
+type BackupStorage interface {
+	// Backups - список резервных копий, начиная с самой новой
+	Backups() ([]Backup, error)
+	// RestoreBackup - восстановление резервной копии по номеру из списка (начиная с 1)
+	RestoreBackup(n int) error
+	// BackupBeforeMigration - копия данных перед обновлением схемы с версии version.
+	// Хранится отдельно от резервных копий и не удаляется при ротации.
+	// Возвращает путь копии, пустой - копия не создавалась.
+	BackupBeforeMigration(version int) (string, error)
+}
+
+// backupSet - набор резервных копий, хранящихся рядом с файлом данных
+type backupSet struct {
+	path string
+	keep int
+
+	// Минимальный промежуток между плановыми копиями, 0 - копия при каждом сохранении
+	interval time.Duration
+}
+
+// prefix - общий префикс имен резервных копий
+func (b backupSet) prefix() string {
+	return b.path + ".backup-"
+}
+
+// create - создание резервной копии текущего файла данных и удаление лишних копий.
+// Копия создается жесткой ссылкой, так как файл данных никогда не изменяется
+// на месте, а только заменяется целиком.
+func (b backupSet) create() error {
+	if b.keep <= 0 {
+		return nil
+	}
+
+	if _, err := os.Stat(b.path); os.IsNotExist(err) {
+		return nil
+	}
+
+	name := b.prefix() + time.Now().Format(backupTimeFormat)
+	if err := os.Link(b.path, name); err != nil {
+		// Копия с той же меткой уже есть и может быть ссылкой на сам файл
+		// данных, копирование поверх нее обнулило бы данные
+		if os.IsExist(err) {
+			return nil
+		}
+		if err := copyFile(b.path, name); err != nil {
+			return fmt.Errorf("ошибка создания резервной копии: %w", err)
+		}
+	}
+
+	return b.prune()
+}
+
+// due - нужна ли плановая копия перед сохранением: самая новая копия старше
+// интервала. Иначе частые сохранения вытеснили бы все копии за несколько минут работы.
+func (b backupSet) due() (bool, error) {
+	if b.keep <= 0 {
+		return false, nil
+	}
+	if b.interval <= 0 {
+		return true, nil
+	}
+
+	backups, err := b.list()
+	if err != nil {
+		return false, err
+	}
+
+	return len(backups) == 0 || time.Since(backups[0].Time) >= b.interval, nil
+}
+
+// createIfDue - плановая копия перед сохранением не чаще одного раза за интервал
+func (b backupSet) createIfDue() error {
+	due, err := b.due()
+	if err != nil || !due {
+		return err
+	}
+
+	return b.create()
+}
+
+// migrationPath - путь копии перед обновлением схемы с версии version.
+// Имя не начинается с префикса резервных копий, поэтому копия не попадает в ротацию.
+func (b backupSet) migrationPath(version int) string {
+	return fmt.Sprintf("%s.pre-migration-v%d", b.path, version)
+}
+
+// migrationDue - нужна ли копия перед обновлением схемы: уже созданная копия
+// не перезаписывается, в ней данные до первой попытки обновления
+func (b backupSet) migrationDue(version int) (bool, error) {
+	if b.keep <= 0 {
+		return false, nil
+	}
+
+	if _, err := os.Stat(b.path); os.IsNotExist(err) {
+		return false, nil
+	}
+
+	_, err := os.Stat(b.migrationPath(version))
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+
+	return false, err
+}
+
+// createMigration - копия файла данных перед обновлением схемы
+func (b backupSet) createMigration(version int) (string, error) {
+	due, err := b.migrationDue(version)
+	if err != nil || !due {
+		return "", err
+	}
+
+	name := b.migrationPath(version)
+	if err := os.Link(b.path, name); err != nil {
+		if err := copyFile(b.path, name); err != nil {
+			return "", fmt.Errorf("ошибка создания копии перед обновлением схемы: %w", err)
+		}
+	}
+
+	return name, nil
+}
+
+// list - список резервных копий, начиная с самой новой
+func (b backupSet) list() ([]Backup, error) {
+	matches, err := filepath.Glob(b.prefix() + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]Backup, 0, len(matches))
+	for _, match := range matches {
+		stamp := strings.TrimPrefix(match, b.prefix())
+		t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, Backup{Path: match, Time: t, Size: info.Size()})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+
+	return backups, nil
+}
+
+// get - получение резервной копии по номеру из списка (начиная с 1)
+func (b backupSet) get(n int) (Backup, error) {
+	backups, err := b.list()
+	if err != nil {
+		return Backup{}, err
+	}
+
+	if n < 1 || n > len(backups) {
+		return Backup{}, fmt.Errorf("резервная копия №%d не найдена (доступно: %d)", n, len(backups))
+	}
+
+	return backups[n-1], nil
+}
+
+// prune - удаление самых старых резервных копий сверх лимита
+func (b backupSet) prune() error {
+	backups, err := b.list()
+	if err != nil {
+		return err
+	}
+
+	for i := b.keep; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyFile - копирование файла с принудительной записью на диск
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/pkg/logger"
+)
+
+// newTestJSONStorage - хранилище во временной директории с уже сохраненным проектом
+func newTestJSONStorage(t *testing.T, backups int, interval time.Duration) *JSONStorage {
+	t.Helper()
+
+	store := NewJSONStorage(Options{
+		Path:           filepath.Join(t.TempDir(), "data.json"),
+		Backups:        backups,
+		BackupInterval: interval,
+		SchemaVersion:  1,
+	}, logger.NewLogger("error", io.Discard))
+
+	if _, err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+	saveProject(t, store, "a")
+
+	return store
+}
+
+// saveProject - сохранение пустого проекта; метки копий различаются на миллисекунды
+func saveProject(t *testing.T, store *JSONStorage, name string) {
+	t.Helper()
+
+	time.Sleep(2 * time.Millisecond)
+	if err := store.SaveProject(name, json.RawMessage(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// countBackups - количество копий в ротации
+func countBackups(t *testing.T, store *JSONStorage) int {
+	t.Helper()
+
+	backups, err := store.Backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return len(backups)
+}
+
+func TestBackupInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		keep     int
+		interval time.Duration
+		want     int
+	}{
+		{"копия при каждом сохранении", 10, 0, 3},
+		{"не чаще одного раза за интервал", 10, time.Hour, 1},
+		{"ротация по количеству", 2, 0, 2},
+		{"копии отключены", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestJSONStorage(t, tt.keep, tt.interval)
+			for _, name := range []string{"b", "c", "d"} {
+				saveProject(t, store, name)
+			}
+
+			if got := countBackups(t, store); got != tt.want {
+				t.Errorf("копий %d, ожидалось %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackupIntervalElapsed(t *testing.T) {
+	store := newTestJSONStorage(t, 10, time.Hour)
+	saveProject(t, store, "b")
+
+	// Самая новая копия старше интервала
+	backups, err := store.Backups()
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("копии %v, ошибка %v", backups, err)
+	}
+	old := store.backups.prefix() + time.Now().Add(-2*time.Hour).Format(backupTimeFormat)
+	if err := os.Rename(backups[0].Path, old); err != nil {
+		t.Fatal(err)
+	}
+
+	saveProject(t, store, "c")
+	if got := countBackups(t, store); got != 2 {
+		t.Errorf("копий %d, ожидалось 2", got)
+	}
+}
+
+func TestRestoreBackupIgnoresInterval(t *testing.T) {
+	store := newTestJSONStorage(t, 10, time.Hour)
+	saveProject(t, store, "b")
+
+	time.Sleep(2 * time.Millisecond)
+	if err := store.RestoreBackup(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Данные перед восстановлением сохраняются всегда
+	if got := countBackups(t, store); got != 2 {
+		t.Errorf("копий %d, ожидалось 2", got)
+	}
+
+	snapshot, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := snapshot.Projects["b"]; exists {
+		t.Error("восстановлены данные после копии")
+	}
+}
+
+func TestBackupBeforeMigration(t *testing.T) {
+	store := newTestJSONStorage(t, 2, 0)
+	original, err := os.ReadFile(store.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := store.BackupBeforeMigration(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path == "" {
+		t.Fatal("копия не создана")
+	}
+
+	// Ротация и повторное обновление не затрагивают копию
+	for _, name := range []string{"b", "c", "d", "e"} {
+		saveProject(t, store, name)
+	}
+	if again, err := store.BackupBeforeMigration(1); err != nil || again != "" {
+		t.Errorf("повторная копия %q, ошибка %v", again, err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != string(original) {
+		t.Errorf("копия изменилась: %s", content)
+	}
+
+	backups, _ := store.Backups()
+	for _, backup := range backups {
+		if backup.Path == path {
+			t.Error("копия перед обновлением попала в список резервных копий")
+		}
+	}
+	if len(backups) != 2 {
+		t.Errorf("копий %d, ожидалось 2", len(backups))
+	}
+}
+
+func TestBackupSameTimestamp(t *testing.T) {
+	store := newTestJSONStorage(t, 200, 0)
+	original, err := os.ReadFile(store.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Копии с метками, одну из которых получит и следующая копия
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		name := store.backups.prefix() + now.Add(time.Duration(i)*time.Millisecond).Format(backupTimeFormat)
+		if err := os.Link(store.Path, name); err != nil && !os.IsExist(err) {
+			t.Fatal(err)
+		}
+	}
+	if err := store.backups.create(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(store.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != string(original) {
+		t.Errorf("файл данных изменился: %q", content)
+	}
+}
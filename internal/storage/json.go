@@ -0,0 +1,299 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/pkg/logger"
+)
+
+// JSONStorage - хранилище данных в одном JSON-файле
+type JSONStorage struct {
+	Path          string
+	Logger        logger.Logger
+	backups       backupSet
+	schemaVersion int
+
+	// Последние загруженные или сохраненные данные, нужны для записи
+	// отдельного проекта, так как файл всегда перезаписывается целиком
+	snapshot *Snapshot
+
+	// Состояние файла после последней загрузки или сохранения,
+	// по нему определяется изменение файла другими программами
+	state   fileState
+	tracked bool
+}
+
+// fileState - признаки, по которым определяется изменение файла
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// statFile - получение состояния файла
+func statFile(path string) (fileState, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fileState{}, nil
+	}
+	if err != nil {
+		return fileState{}, err
+	}
+
+	return fileState{exists: true, size: info.Size(), modTime: info.ModTime()}, nil
+}
+
+// NewJSONStorage - создание хранилища в JSON-файле
+func NewJSONStorage(opts Options, log logger.Logger) *JSONStorage {
+	return &JSONStorage{
+		Path:          opts.Path,
+		Logger:        log,
+		backups:       backupSet{path: opts.Path, keep: opts.Backups, interval: opts.BackupInterval},
+		schemaVersion: opts.SchemaVersion,
+	}
+}
+
+// Load - загрузка данных из файла
+func (s *JSONStorage) Load() (*Snapshot, error) {
+	s.Logger.Debug("Загрузка данных из файла:", s.Path)
+
+	// Создаем директорию для файла данных, если она не существует
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		s.Logger.Errorf("Ошибка создания директории для данных: %v", err)
+		return nil, err
+	}
+
+	raw, err := os.ReadFile(s.Path)
+	if err != nil && !os.IsNotExist(err) {
+		s.Logger.Errorf("Ошибка открытия файла данных: %v", err)
+		return nil, err
+	}
+	if os.IsNotExist(err) {
+		s.Logger.Info("Файл данных не существует, будет создан новый")
+		s.snapshot = NewSnapshot(s.schemaVersion)
+		s.rememberState()
+		return copySnapshot(s.snapshot), nil
+	}
+
+	snapshot, err := decodeJSONFile(raw)
+	if err != nil {
+		s.Logger.Errorf("Ошибка декодирования данных: %v", err)
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+
+	s.snapshot = snapshot
+	s.rememberState()
+	return copySnapshot(snapshot), nil
+}
+
+// Save - сохранение данных в файл
+func (s *JSONStorage) Save(snapshot *Snapshot) error {
+	s.Logger.Debug("Сохранение данных в файл:", s.Path)
+
+	// Создаем директорию для файла данных, если она не существует
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		s.Logger.Errorf("Ошибка создания директории для данных: %v", err)
+		return err
+	}
+
+	changed, err := s.ChangedOnDisk()
+	if err != nil {
+		return err
+	}
+	if changed {
+		s.Logger.Warn("Файл данных изменен другой программой, сохранение отменено")
+		return ErrChangedOnDisk
+	}
+
+	raw, err := encodeJSONFile(snapshot)
+	if err != nil {
+		s.Logger.Errorf("Ошибка кодирования данных: %v", err)
+		return err
+	}
+
+	// Ошибка резервного копирования не должна мешать сохранению
+	if err := s.backups.createIfDue(); err != nil {
+		s.Logger.Errorf("Ошибка резервного копирования: %v", err)
+	}
+
+	if err := writeFileAtomic(s.Path, raw); err != nil {
+		s.Logger.Errorf("Ошибка записи файла данных: %v", err)
+		return err
+	}
+
+	s.snapshot = copySnapshot(snapshot)
+	s.rememberState()
+	return nil
+}
+
+// SaveProject - сохранение проекта, файл перезаписывается целиком
+func (s *JSONStorage) SaveProject(name string, project json.RawMessage) error {
+	if err := s.ensureLoaded(); err != nil {
+		return err
+	}
+
+	snapshot := copySnapshot(s.snapshot)
+	snapshot.Projects[name] = project
+	return s.Save(snapshot)
+}
+
+// DeleteProject - удаление проекта, файл перезаписывается целиком
+func (s *JSONStorage) DeleteProject(name string) error {
+	if err := s.ensureLoaded(); err != nil {
+		return err
+	}
+
+	snapshot := copySnapshot(s.snapshot)
+	delete(snapshot.Projects, name)
+	return s.Save(snapshot)
+}
+
+// Backups - список резервных копий файла данных
+func (s *JSONStorage) Backups() ([]Backup, error) {
+	return s.backups.list()
+}
+
+// BackupBeforeMigration - копия файла данных перед обновлением схемы
+func (s *JSONStorage) BackupBeforeMigration(version int) (string, error) {
+	return s.backups.createMigration(version)
+}
+
+// RestoreBackup - замена файла данных резервной копией.
+// Текущий файл перед этим сам сохраняется в резервную копию.
+func (s *JSONStorage) RestoreBackup(n int) error {
+	backup, err := s.backups.get(n)
+	if err != nil {
+		return err
+	}
+
+	s.Logger.Infof("Восстановление данных из резервной копии: %s", backup.Path)
+
+	raw, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := decodeJSONFile(raw)
+	if err != nil {
+		return fmt.Errorf("резервная копия повреждена: %w", err)
+	}
+
+	if err := s.backups.create(); err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(s.Path, raw); err != nil {
+		return err
+	}
+
+	s.snapshot = snapshot
+	s.rememberState()
+	return nil
+}
+
+// ChangedOnDisk - изменился ли файл с момента последней загрузки или сохранения
+func (s *JSONStorage) ChangedOnDisk() (bool, error) {
+	if !s.tracked {
+		return false, nil
+	}
+
+	current, err := statFile(s.Path)
+	if err != nil {
+		return false, err
+	}
+
+	return current != s.state, nil
+}
+
+// rememberState - запоминание состояния файла после чтения или записи
+func (s *JSONStorage) rememberState() {
+	state, err := statFile(s.Path)
+	if err != nil {
+		s.Logger.Warnf("Ошибка получения состояния файла данных: %v", err)
+		s.tracked = false
+		return
+	}
+
+	s.state = state
+	s.tracked = true
+}
+
+// Close - у JSON-хранилища нет открытых ресурсов
+func (s *JSONStorage) Close() error {
+	return nil
+}
+
+// ensureLoaded - загрузка данных, если они еще не загружены
+func (s *JSONStorage) ensureLoaded() error {
+	if s.snapshot != nil {
+		return nil
+	}
+
+	_, err := s.Load()
+	return err
+}
+
+// jsonFile - формат файла данных
+type jsonFile struct {
+	Version  int                        `json:"version"`
+	Projects map[string]json.RawMessage `json:"projects"`
+}
+
+// encodeJSONFile - кодирование снимка в формат файла данных
+func encodeJSONFile(snapshot *Snapshot) ([]byte, error) {
+	raw, err := json.Marshal(jsonFile{
+		Version:  snapshot.Version,
+		Projects: snapshot.Projects,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(raw, '\n'), nil
+}
+
+// decodeJSONFile - декодирование файла данных. Файлы без версии схемы
+// содержат только объект проектов и считаются данными версии LegacySchemaVersion.
+func decodeJSONFile(raw []byte) (*Snapshot, error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &top); err != nil {
+		return nil, err
+	}
+
+	if isVersionedFile(top) {
+		var file jsonFile
+		if err := json.Unmarshal(raw, &file); err != nil {
+			return nil, err
+		}
+
+		snapshot := NewSnapshot(file.Version)
+		for name, project := range file.Projects {
+			snapshot.Projects[name] = project
+		}
+		return snapshot, nil
+	}
+
+	snapshot := NewSnapshot(LegacySchemaVersion)
+	for name, project := range top {
+		snapshot.Projects[name] = project
+	}
+	return snapshot, nil
+}
+
+// isVersionedFile - содержит ли файл версию схемы. Проверяется и тип
+// значений, чтобы не принять за версию проекты со случайными именами.
+func isVersionedFile(top map[string]json.RawMessage) bool {
+	rawVersion, hasVersion := top["version"]
+	rawProjects, hasProjects := top["projects"]
+	if !hasVersion || !hasProjects || len(top) != 2 {
+		return false
+	}
+
+	var version int
+	var projects map[string]json.RawMessage
+	return json.Unmarshal(rawVersion, &version) == nil && json.Unmarshal(rawProjects, &projects) == nil
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrLocked - данные уже открыты другим экземпляром приложения
+var ErrLocked = errors.New("данные используются другим экземпляром приложения")
+
+// FileLock - рекомендательная блокировка файла данных между процессами.
+// Блокируется отдельный файл, так как сам файл данных заменяется при каждом сохранении.
+type FileLock struct {
+	Path string
+	file *os.File
+}
+
+// Lock - захват блокировки файла данных без ожидания
+func Lock(dataPath string) (*FileLock, error) {
+	path := dataPath + ".lock"
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла блокировки: %w", err)
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			if pid := readLockOwner(path); pid != "" {
+				return nil, fmt.Errorf("%w (PID %s)", ErrLocked, pid)
+			}
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("ошибка блокировки файла данных: %w", err)
+	}
+
+	// Записываем PID владельца, чтобы второй экземпляр мог его показать
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	return &FileLock{Path: path, file: file}, nil
+}
+
+// Unlock - освобождение блокировки
+func (l *FileLock) Unlock() error {
+	if err := syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN); err != nil {
+		l.file.Close()
+		return err
+	}
+
+	return l.file.Close()
+}
+
+// readLockOwner - чтение PID владельца блокировки
+func readLockOwner(path string) string {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(raw))
+}
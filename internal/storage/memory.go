@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// MemoryStorage - хранилище в памяти для тестов и временной работы
+type MemoryStorage struct {
+	mu       sync.Mutex
+	snapshot *Snapshot
+}
+
+// NewMemoryStorage - создание пустого хранилища в памяти
+func NewMemoryStorage(schemaVersion int) *MemoryStorage {
+	return &MemoryStorage{
+		snapshot: NewSnapshot(schemaVersion),
+	}
+}
+
+// Load - получение копии всех данных
+func (s *MemoryStorage) Load() (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return copySnapshot(s.snapshot), nil
+}
+
+// Save - замена всех данных
+func (s *MemoryStorage) Save(snapshot *Snapshot) error {
+	s.mu.Lock()
+	s.snapshot = copySnapshot(snapshot)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// SaveProject - создание или обновление одного проекта
+func (s *MemoryStorage) SaveProject(name string, project json.RawMessage) error {
+	s.mu.Lock()
+	s.snapshot.Projects[name] = project
+	s.mu.Unlock()
+
+	return nil
+}
+
+// DeleteProject - удаление одного проекта
+func (s *MemoryStorage) DeleteProject(name string) error {
+	s.mu.Lock()
+	delete(s.snapshot.Projects, name)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Close - у хранилища в памяти нет ресурсов для освобождения
+func (s *MemoryStorage) Close() error {
+	return nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrReadOnly - хранилище открыто только для чтения
+var ErrReadOnly = errors.New("данные открыты только для чтения")
+
+// ErrChangedOnDisk - данные изменены другой программой после загрузки
+var ErrChangedOnDisk = errors.New("данные изменены другой программой после загрузки")
+
+// ChangeDetector - хранилище, умеющее определять изменение данных другими процессами
+type ChangeDetector interface {
+	// ChangedOnDisk - изменились ли данные с момента последней загрузки или сохранения
+	ChangedOnDisk() (bool, error)
+}
+
+// ReadOnlyStorage - обертка, запрещающая запись в хранилище
+type ReadOnlyStorage struct {
+	Storage
+	Reason string
+}
+
+// NewReadOnlyStorage - создание обертки только для чтения с указанием причины
+func NewReadOnlyStorage(store Storage, reason string) *ReadOnlyStorage {
+	return &ReadOnlyStorage{
+		Storage: store,
+		Reason:  reason,
+	}
+}
+
+// Save - запись запрещена
+func (s *ReadOnlyStorage) Save(snapshot *Snapshot) error {
+	return s.err()
+}
+
+// SaveProject - запись запрещена
+func (s *ReadOnlyStorage) SaveProject(name string, project json.RawMessage) error {
+	return s.err()
+}
+
+// DeleteProject - запись запрещена
+func (s *ReadOnlyStorage) DeleteProject(name string) error {
+	return s.err()
+}
+
+// ChangedOnDisk - проверка изменений во вложенном хранилище
+func (s *ReadOnlyStorage) ChangedOnDisk() (bool, error) {
+	detector, ok := s.Storage.(ChangeDetector)
+	if !ok {
+		return false, nil
+	}
+
+	return detector.ChangedOnDisk()
+}
+
+// Backups - список резервных копий вложенного хранилища
+func (s *ReadOnlyStorage) Backups() ([]Backup, error) {
+	backups, ok := s.Storage.(BackupStorage)
+	if !ok {
+		return nil, fmt.Errorf("хранилище не поддерживает резервные копии")
+	}
+
+	return backups.Backups()
+}
+
+// RestoreBackup - восстановление запрещено
+func (s *ReadOnlyStorage) RestoreBackup(n int) error {
+	return s.err()
+}
+
+// BackupBeforeMigration - данные не обновляются на диске, поэтому копия не нужна
+func (s *ReadOnlyStorage) BackupBeforeMigration(version int) (string, error) {
+	return "", nil
+}
+
+// err - ошибка записи с причиной режима только для чтения
+func (s *ReadOnlyStorage) err() error {
+	if s.Reason == "" {
+		return ErrReadOnly
+	}
+
+	return fmt.Errorf("%w: %s", ErrReadOnly, s.Reason)
+}
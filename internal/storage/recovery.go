@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrNoValidBackup - нет ни одной резервной копии, пригодной для восстановления
+var ErrNoValidBackup = errors.New("нет исправных резервных копий")
+
+// ProjectValidator - проверка, что приложение сможет прочитать проект name
+// из файла со схемой версии version; хранилище не знает структуры проектов
+type ProjectValidator func(version int, name string, raw json.RawMessage) error
+
+// RecoverableStorage - хранилище, умеющее восстанавливаться после повреждения данных
+type RecoverableStorage interface {
+	// Recover - восстановление поврежденных данных. Поврежденный файл
+	// переносится в карантин, данные берутся из последней исправной
+	// резервной копии, а если её нет и allowPartial - частично из самого файла.
+	// Файл, резервная копия или проект считаются исправными, только если
+	// их проекты проходят проверку valid.
+	Recover(allowPartial bool, valid ProjectValidator) (*RecoveryReport, error)
+}
+
+// RecoveryReport - отчет о восстановлении данных
+type RecoveryReport struct {
+	// Путь, по которому сохранен поврежденный файл
+	QuarantinePath string
+
+	// Резервная копия, из которой восстановлены данные (nil при частичном восстановлении)
+	Backup *Backup
+
+	// Проекты, которые читаются из поврежденного файла, но отличаются от
+	// резервной копии: их изменения после создания копии потеряны
+	ChangedSinceBackup []string
+
+	// Проекты, прочитанные из поврежденного файла без ошибок
+	Salvaged []string
+
+	// Проекты, которые найдены в поврежденном файле, но не могут быть прочитаны
+	// (при восстановлении из копии их изменения после её создания тоже потеряны)
+	Damaged []string
+
+	// Файл обрывается или нарушена его структура: все проекты начиная
+	// с позиции Offset потеряны, их имена неизвестны
+	Truncated bool
+	Offset    int64
+}
+
+// salvageResult - результат разбора поврежденного файла
+type salvageResult struct {
+	snapshot  *Snapshot
+	salvaged  []string
+	damaged   []string
+	truncated bool
+	offset    int64
+}
+
+// versionedHeader - начало файла данных с версией схемы
+var versionedHeader = regexp.MustCompile(`^\s*\{\s*"version"\s*:\s*(\d+)\s*,\s*"projects"\s*:\s*`)
+
+// Recover - восстановление поврежденного файла данных
+func (s *JSONStorage) Recover(allowPartial bool, valid ProjectValidator) (*RecoveryReport, error) {
+	raw, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot, err := decodeJSONFile(raw); err == nil && validSnapshot(snapshot, valid) == nil {
+		return nil, fmt.Errorf("файл данных '%s' не поврежден", s.Path)
+	}
+
+	backup, backupSnapshot := s.latestValidBackup(valid)
+	if backup == nil && !allowPartial {
+		return nil, ErrNoValidBackup
+	}
+
+	report := &RecoveryReport{}
+	salvage := salvageSnapshot(raw, valid)
+
+	report.QuarantinePath = fmt.Sprintf("%s.corrupt-%s", s.Path, time.Now().Format(backupTimeFormat))
+	if err := os.Rename(s.Path, report.QuarantinePath); err != nil {
+		return nil, fmt.Errorf("ошибка переноса поврежденного файла: %w", err)
+	}
+	s.Logger.Warnf("Поврежденный файл данных перенесен в %s", report.QuarantinePath)
+
+	report.Damaged = salvage.damaged
+	report.Truncated = salvage.truncated
+	report.Offset = salvage.offset
+
+	snapshot := salvage.snapshot
+	if backup != nil {
+		report.Backup = backup
+		report.ChangedSinceBackup = changedProjects(salvage.snapshot, backupSnapshot)
+		snapshot = backupSnapshot
+		s.Logger.Infof("Данные восстанавливаются из резервной копии: %s", backup.Path)
+	} else {
+		report.Salvaged = salvage.salvaged
+		s.Logger.Infof("Частичное восстановление: прочитано проектов %d, повреждено %d", len(salvage.salvaged), len(salvage.damaged))
+	}
+
+	// Файл перенесен в карантин, поэтому его состояние больше не отслеживается
+	s.tracked = false
+	if err := s.Save(snapshot); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// latestValidBackup - поиск самой новой резервной копии, которая читается без ошибок
+func (s *JSONStorage) latestValidBackup(valid ProjectValidator) (*Backup, *Snapshot) {
+	backups, err := s.backups.list()
+	if err != nil {
+		s.Logger.Errorf("Ошибка получения списка резервных копий: %v", err)
+		return nil, nil
+	}
+
+	for i := range backups {
+		raw, err := os.ReadFile(backups[i].Path)
+		if err != nil {
+			continue
+		}
+
+		snapshot, err := decodeJSONFile(raw)
+		if err == nil {
+			err = validSnapshot(snapshot, valid)
+		}
+		if err != nil {
+			s.Logger.Warnf("Резервная копия повреждена: %s: %v", backups[i].Path, err)
+			continue
+		}
+
+		return &backups[i], snapshot
+	}
+
+	return nil, nil
+}
+
+// validSnapshot - проверка всех проектов снимка
+func validSnapshot(snapshot *Snapshot, valid ProjectValidator) error {
+	for name, project := range snapshot.Projects {
+		if err := valid(snapshot.Version, name, project); err != nil {
+			return fmt.Errorf("проект '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// salvageSnapshot - разбор поврежденного файла по одному проекту.
+// Проекты, не являющиеся JSON-объектами или не прошедшие проверку valid,
+// пропускаются, а при нарушении структуры самого файла разбор
+// останавливается на месте ошибки.
+func salvageSnapshot(raw []byte, valid ProjectValidator) salvageResult {
+	result := salvageResult{snapshot: NewSnapshot(LegacySchemaVersion)}
+
+	// В файлах с версией схемы проекты вложены в поле "projects"
+	var base int64
+	if match := versionedHeader.FindSubmatchIndex(raw); match != nil {
+		version, err := strconv.Atoi(string(raw[match[2]:match[3]]))
+		if err == nil {
+			result.snapshot.Version = version
+			base = int64(match[1])
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw[base:]))
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		result.truncated = true
+		result.offset = base
+		return result
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+
+		name, ok := tok.(string)
+		if !ok {
+			break
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			result.damaged = append(result.damaged, name)
+			break
+		}
+
+		var project map[string]json.RawMessage
+		if err := json.Unmarshal(value, &project); err != nil {
+			result.damaged = append(result.damaged, name)
+			continue
+		}
+
+		if err := valid(result.snapshot.Version, name, value); err != nil {
+			result.damaged = append(result.damaged, name)
+			continue
+		}
+
+		result.snapshot.Projects[name] = value
+		result.salvaged = append(result.salvaged, name)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		result.truncated = true
+		result.offset = base + dec.InputOffset()
+	}
+
+	return result
+}
+
+// changedProjects - проекты, содержимое которых отличается от резервной копии
+func changedProjects(current, backup *Snapshot) []string {
+	var changed []string
+
+	for name, project := range current.Projects {
+		backupProject, exists := backup.Projects[name]
+		if !exists || !equalJSON(project, backupProject) {
+			changed = append(changed, name)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
+// equalJSON - сравнение JSON без учета форматирования
+func equalJSON(a, b json.RawMessage) bool {
+	var bufA, bufB bytes.Buffer
+	if json.Compact(&bufA, a) != nil || json.Compact(&bufB, b) != nil {
+		return false
+	}
+
+	return bytes.Equal(bufA.Bytes(), bufB.Bytes())
+}
@@ -0,0 +1,352 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/pkg/logger"
+
+	// Драйвер SQLite на чистом Go, не требует cgo
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema - схема базы данных, каждый проект хранится отдельной строкой,
+// версия схемы данных проектов хранится в таблице meta
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS projects (
+	name TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+)`
+
+// schemaVersionKey - ключ версии схемы данных в таблице meta
+const schemaVersionKey = "schema_version"
+
+// SQLiteStorage - хранилище данных во встроенной базе SQLite
+type SQLiteStorage struct {
+	Path    string
+	Logger  logger.Logger
+	db      *sql.DB
+	backups backupSet
+
+	// Версия схемы для новой базы
+	schemaVersion int
+
+	// Версия данных после последней загрузки, изменяется SQLite
+	// при фиксации транзакций другими соединениями
+	dataVersion int64
+}
+
+// NewSQLiteStorage - открытие базы SQLite и создание схемы
+func NewSQLiteStorage(opts Options, log logger.Logger) (*SQLiteStorage, error) {
+	// Создаем директорию для базы данных, если она не существует
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0755); err != nil {
+		log.Errorf("Ошибка создания директории для данных: %v", err)
+		return nil, err
+	}
+
+	db, err := openSQLite(opts.Path)
+	if err != nil {
+		log.Errorf("Ошибка открытия базы данных: %v", err)
+		return nil, err
+	}
+
+	store := &SQLiteStorage{
+		Path:    opts.Path,
+		Logger:  log,
+		db:      db,
+		backups: backupSet{path: opts.Path, keep: opts.Backups, interval: opts.BackupInterval},
+
+		schemaVersion: opts.SchemaVersion,
+	}
+
+	if store.dataVersion, err = store.queryDataVersion(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// openSQLite - открытие базы и создание схемы
+func openSQLite(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite не поддерживает параллельную запись из нескольких соединений
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка создания схемы базы данных: %w", err)
+	}
+
+	return db, nil
+}
+
+// Load - загрузка всех проектов из базы
+func (s *SQLiteStorage) Load() (*Snapshot, error) {
+	s.Logger.Debug("Загрузка данных из базы:", s.Path)
+
+	// Версию запрашиваем до чтения строк: единственное соединение
+	// занято до закрытия rows
+	version, err := s.queryDataVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := NewSnapshot(s.schemaVersion)
+	versionErr := s.db.QueryRow("SELECT value FROM meta WHERE key = ?", schemaVersionKey).Scan(&snapshot.Version)
+	if versionErr != nil && !errors.Is(versionErr, sql.ErrNoRows) {
+		s.Logger.Errorf("Ошибка чтения версии схемы: %v", versionErr)
+		return nil, versionErr
+	}
+
+	rows, err := s.db.Query("SELECT name, data FROM projects")
+	if err != nil {
+		s.Logger.Errorf("Ошибка чтения проектов: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, raw string
+		if err := rows.Scan(&name, &raw); err != nil {
+			return nil, err
+		}
+
+		if !json.Valid([]byte(raw)) {
+			s.Logger.Errorf("Поврежден проект '%s'", name)
+			return nil, fmt.Errorf("%w: проект '%s'", ErrCorrupt, name)
+		}
+		snapshot.Projects[name] = json.RawMessage(raw)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Базы, созданные до появления версий, содержат только проекты
+	if len(snapshot.Projects) > 0 && errors.Is(versionErr, sql.ErrNoRows) {
+		snapshot.Version = LegacySchemaVersion
+	}
+
+	s.dataVersion = version
+	return snapshot, nil
+}
+
+// Save - замена всех данных в базе одной транзакцией
+func (s *SQLiteStorage) Save(snapshot *Snapshot) error {
+	s.Logger.Debug("Сохранение данных в базу:", s.Path)
+	if err := s.checkNotChanged(); err != nil {
+		return err
+	}
+	s.createBackup()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM projects"); err != nil {
+		return err
+	}
+
+	for name, project := range snapshot.Projects {
+		if err := upsertProject(tx, name, project); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value",
+		schemaVersionKey, snapshot.Version,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SaveProject - создание или обновление одного проекта
+func (s *SQLiteStorage) SaveProject(name string, project json.RawMessage) error {
+	s.Logger.Debugf("Сохранение проекта '%s' в базу", name)
+	if err := s.checkNotChanged(); err != nil {
+		return err
+	}
+	s.createBackup()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// В новой базе версия схемы записывается вместе с первым проектом
+	_, err = tx.Exec("INSERT OR IGNORE INTO meta (key, value) VALUES (?, ?)", schemaVersionKey, s.schemaVersion)
+	if err != nil {
+		return err
+	}
+
+	if err := upsertProject(tx, name, project); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteProject - удаление одного проекта
+func (s *SQLiteStorage) DeleteProject(name string) error {
+	s.Logger.Debugf("Удаление проекта '%s' из базы", name)
+	if err := s.checkNotChanged(); err != nil {
+		return err
+	}
+	s.createBackup()
+
+	_, err := s.db.Exec("DELETE FROM projects WHERE name = ?", name)
+	return err
+}
+
+// Backups - список резервных копий базы
+func (s *SQLiteStorage) Backups() ([]Backup, error) {
+	return s.backups.list()
+}
+
+// RestoreBackup - замена базы резервной копией.
+// Текущая база перед этим сама сохраняется в резервную копию.
+func (s *SQLiteStorage) RestoreBackup(n int) error {
+	backup, err := s.backups.get(n)
+	if err != nil {
+		return err
+	}
+
+	s.Logger.Infof("Восстановление базы из резервной копии: %s", backup.Path)
+
+	if err := s.backupDatabase(); err != nil {
+		return err
+	}
+
+	if err := s.db.Close(); err != nil {
+		return err
+	}
+
+	restoreErr := copyFileAtomic(backup.Path, s.Path)
+
+	// База открывается заново даже при ошибке, чтобы хранилище оставалось рабочим
+	db, err := openSQLite(s.Path)
+	if err != nil {
+		return err
+	}
+	s.db = db
+
+	if restoreErr != nil {
+		return restoreErr
+	}
+
+	// После замены файла база считается загруженной заново
+	s.dataVersion, err = s.queryDataVersion()
+	return err
+}
+
+// ChangedOnDisk - изменили ли базу другие процессы с момента последней загрузки
+func (s *SQLiteStorage) ChangedOnDisk() (bool, error) {
+	version, err := s.queryDataVersion()
+	if err != nil {
+		return false, err
+	}
+
+	return version != s.dataVersion, nil
+}
+
+// checkNotChanged - отказ от записи, если базу изменил другой процесс
+func (s *SQLiteStorage) checkNotChanged() error {
+	changed, err := s.ChangedOnDisk()
+	if err != nil {
+		return err
+	}
+	if changed {
+		s.Logger.Warn("База данных изменена другой программой, сохранение отменено")
+		return ErrChangedOnDisk
+	}
+
+	return nil
+}
+
+// queryDataVersion - получение счетчика изменений базы другими соединениями
+func (s *SQLiteStorage) queryDataVersion() (int64, error) {
+	var version int64
+	err := s.db.QueryRow("PRAGMA data_version").Scan(&version)
+	return version, err
+}
+
+// createBackup - плановое резервное копирование перед изменением базы.
+// Ошибка резервного копирования не должна мешать сохранению.
+func (s *SQLiteStorage) createBackup() {
+	due, err := s.backups.due()
+	if err == nil && due {
+		err = s.backupDatabase()
+	}
+	if err != nil {
+		s.Logger.Errorf("Ошибка резервного копирования: %v", err)
+	}
+}
+
+// backupDatabase - создание согласованной копии базы командой VACUUM INTO
+func (s *SQLiteStorage) backupDatabase() error {
+	if s.backups.keep <= 0 {
+		return nil
+	}
+
+	name := s.backups.prefix() + time.Now().Format(backupTimeFormat)
+	if _, err := s.db.Exec("VACUUM INTO ?", name); err != nil {
+		return fmt.Errorf("ошибка создания резервной копии: %w", err)
+	}
+
+	return s.backups.prune()
+}
+
+// BackupBeforeMigration - копия базы перед обновлением схемы
+func (s *SQLiteStorage) BackupBeforeMigration(version int) (string, error) {
+	due, err := s.backups.migrationDue(version)
+	if err != nil || !due {
+		return "", err
+	}
+
+	name := s.backups.migrationPath(version)
+	if _, err := s.db.Exec("VACUUM INTO ?", name); err != nil {
+		return "", fmt.Errorf("ошибка создания копии перед обновлением схемы: %w", err)
+	}
+
+	return name, nil
+}
+
+// Close - закрытие базы данных
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+// execer - общий интерфейс для *sql.DB и *sql.Tx
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// upsertProject - запись проекта в базу с заменой существующей строки
+func upsertProject(db execer, name string, project json.RawMessage) error {
+	_, err := db.Exec(
+		"INSERT INTO projects (name, data) VALUES (?, ?) ON CONFLICT(name) DO UPDATE SET data = excluded.data",
+		name, string(project),
+	)
+	return err
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/pkg/logger"
+)
+
+// Типы хранилищ
+const (
+	TypeJSON   = "json"
+	TypeSQLite = "sqlite"
+	TypeMemory = "memory"
+)
+
+// ErrCorrupt - данные в хранилище повреждены и не могут быть прочитаны
+var ErrCorrupt = errors.New("данные повреждены")
+
+// LegacySchemaVersion - версия схемы данных, сохраненных до появления версий
+const LegacySchemaVersion = 1
+
+// Snapshot - сохраненные данные: версия схемы и проекты в виде JSON.
+// Хранилище не разбирает проекты, их декодированием и обновлением схемы
+// занимается сервисный слой.
+type Snapshot struct {
+	Version  int
+	Projects map[string]json.RawMessage
+}
+
+// NewSnapshot - создание пустого снимка данных указанной версии
+func NewSnapshot(version int) *Snapshot {
+	return &Snapshot{
+		Version:  version,
+		Projects: make(map[string]json.RawMessage),
+	}
+}
+
+// copySnapshot - копия снимка, чтобы изменения вызывающего кода не затрагивали хранилище
+func copySnapshot(snapshot *Snapshot) *Snapshot {
+	result := NewSnapshot(snapshot.Version)
+	for name, project := range snapshot.Projects {
+		result.Projects[name] = project
+	}
+	return result
+}
+
+// Storage - интерфейс хранилища данных проектов
+type Storage interface {
+	// Load - загрузка всех данных. Для пустого хранилища возвращается
+	// снимок с версией схемы из Options.SchemaVersion.
+	Load() (*Snapshot, error)
+	// Save - сохранение всех данных с заменой существующих
+	Save(snapshot *Snapshot) error
+	// SaveProject - создание или обновление одного проекта
+	SaveProject(name string, project json.RawMessage) error
+	// DeleteProject - удаление одного проекта
+	DeleteProject(name string) error
+	// Close - освобождение ресурсов хранилища
+	Close() error
+}
+
+// Options - параметры хранилища
+type Options struct {
+	// Путь к файлу данных
+	Path string
+
+	// Количество хранимых резервных копий (0 - не создавать)
+	Backups int
+
+	// Минимальный промежуток между резервными копиями при сохранении
+	// (0 - копия при каждом сохранении)
+	BackupInterval time.Duration
+
+	// Версия схемы, с которой создаются новые данные
+	SchemaVersion int
+}
+
+// New - создание хранилища указанного типа
+func New(kind string, opts Options, log logger.Logger) (Storage, error) {
+	switch kind {
+	case TypeJSON, "":
+		return NewJSONStorage(opts, log), nil
+	case TypeSQLite:
+		return NewSQLiteStorage(opts, log)
+	case TypeMemory:
+		return NewMemoryStorage(opts.SchemaVersion), nil
+	default:
+		return nil, fmt.Errorf("неизвестный тип хранилища: %s", kind)
+	}
+}
@@ -0,0 +1,123 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Timer - отложенный вызов, который можно отменить
+type Timer interface {
+	// Stop - отмена вызова, false если он уже выполнен или отменен
+	Stop() bool
+}
+
+// Clock - источник времени и таймеров, подменяется в тестах
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Real - системные часы
+type Real struct{}
+
+// Now - текущее время
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc - вызов f через d в отдельной горутине
+func (Real) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// Fake - часы, время в которых идет только при вызове Advance
+type Fake struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// fakeTimer - таймер поддельных часов
+type fakeTimer struct {
+	clock *Fake
+	at    time.Time
+	f     func()
+}
+
+// NewFake - поддельные часы, показывающие now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now - текущее время поддельных часов
+func (c *Fake) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// AfterFunc - регистрация вызова f, который выполнит Advance
+func (c *Fake) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+
+	return t
+}
+
+// Advance - перевод часов на d с выполнением наступивших таймеров по порядку.
+// Таймеры вызываются синхронно и могут регистрировать новые.
+func (c *Fake) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		sort.SliceStable(c.timers, func(i, j int) bool {
+			return c.timers[i].at.Before(c.timers[j].at)
+		})
+		if len(c.timers) == 0 || c.timers[0].at.After(target) {
+			c.now = target
+			c.mu.Unlock()
+			return
+		}
+
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		if t.at.After(c.now) {
+			c.now = t.at
+		}
+		c.mu.Unlock()
+
+		t.f()
+	}
+}
+
+// Pending - количество ожидающих таймеров
+func (c *Fake) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+// Stop - отмена таймера
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, other := range c.timers {
+		if other == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
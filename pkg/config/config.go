@@ -5,6 +5,23 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
+)
+
+// Режимы одновременной работы таймеров
+const (
+	// TimerModeSingle - запуск отслеживания останавливает запущенное в другом проекте
+	TimerModeSingle = "single"
+	// TimerModeMulti - несколько проектов отслеживаются одновременно
+	TimerModeMulti = "multi"
+)
+
+// Интерфейсы интерактивного режима
+const (
+	// UIMenu - последовательные меню с выбором пунктов
+	UIMenu = "menu"
+	// UITUI - полноэкранный интерфейс терминала
+	UITUI = "tui"
 )
 
 // Config - структура конфигурации приложения
@@ -12,20 +29,89 @@ type Config struct {
 	// Путь к файлу данных
 	DataFile string
 
+	// Тип хранилища данных (json, sqlite)
+	Storage string
+
+	// Количество резервных копий данных
+	Backups int
+
+	// Минимальный промежуток между резервными копиями при сохранении
+	BackupInterval time.Duration
+
+	// Открыть данные только для чтения
+	ReadOnly bool
+
+	// Unix-сокет демона
+	Socket string
+
+	// Адрес HTTP API (пусто - отключен)
+	HTTPAddr string
+
+	// Токен HTTP API, пусто - токен из файла HTTPTokenFile
+	HTTPToken string
+
+	// Файл токена HTTP API, создается при первом запуске API
+	HTTPTokenFile string
+
 	// Директория для логов
 	LogDir string
 
 	// Уровень логирования
 	LogLevel string
 
-	// Время для уведомления в секундах
+	// Интервал напоминаний о перерыве в секундах
 	NotificationTime int
 
+	// Способ отправки уведомлений (auto, notify-send, dbus, console, webhook, none)
+	Notifier string
+
+	// Адрес для уведомлений webhook
+	WebhookURL string
+
+	// Время, на которое откладывается напоминание
+	Snooze time.Duration
+
+	// Рабочие часы для напоминаний о незапущенном отслеживании
+	// и об отслеживании после окончания дня ("09:00-18:00", пусто - отключить)
+	WorkHours string
+
+	// Интервал повтора этих напоминаний
+	RemindInterval time.Duration
+
+	// Длительность сессии, после которой она считается забытой
+	MaxSession time.Duration
+
+	// Режим помидоров: перерывы по расписанию с автоматической паузой
+	Pomodoro bool
+
+	// Длительность работы, короткого и длинного перерыва в режиме помидоров
+	PomodoroWork       time.Duration
+	PomodoroShortBreak time.Duration
+	PomodoroLongBreak  time.Duration
+
+	// Длинный перерыв после каждого N-го помидора
+	PomodoroLongEvery int
+
+	// Режим таймеров (single, multi)
+	TimerMode string
+
+	// Интерфейс интерактивного режима (menu, tui)
+	UI string
+
+	// Время бездействия, после которого предлагается исключить его из сессии (0 - отключить)
+	IdleThreshold time.Duration
+
+	// Команда, печатающая время бездействия в миллисекундах
+	IdleCommand string
+
 	// Версия приложения
 	Version string
 
 	// Показать справку и выйти
 	ShowHelp bool
+
+	// Подкоманда и её аргументы (пусто - интерактивное меню)
+	Args []string
 }
 
 // DefaultConfig - конфигурация по умолчанию
@@ -36,11 +122,28 @@ func DefaultConfig() *Config {
 	}
 
 	return &Config{
-		DataFile:         filepath.Join(homeDir, "учет_времени.json"),
-		LogDir:           filepath.Join(homeDir, ".time-tracker", "logs"),
-		LogLevel:         "info",
-		NotificationTime: 1500, // 25 минут в секундах
-		ShowHelp:         false,
+		DataFile:           filepath.Join(homeDir, "учет_времени.json"),
+		Storage:            "json",
+		Backups:            10,
+		BackupInterval:     time.Hour,
+		Socket:             filepath.Join(homeDir, ".time-tracker", "ttracker.sock"),
+		HTTPTokenFile:      filepath.Join(homeDir, ".time-tracker", "api-token"),
+		LogDir:             filepath.Join(homeDir, ".time-tracker", "logs"),
+		LogLevel:           "info",
+		NotificationTime:   1500, // 25 минут в секундах
+		Notifier:           "auto",
+		Snooze:             10 * time.Minute,
+		RemindInterval:     30 * time.Minute,
+		MaxSession:         10 * time.Hour,
+		PomodoroWork:       25 * time.Minute,
+		PomodoroShortBreak: 5 * time.Minute,
+		PomodoroLongBreak:  15 * time.Minute,
+		PomodoroLongEvery:  4,
+		TimerMode:          TimerModeMulti,
+		UI:                 UIMenu,
+		IdleThreshold:      10 * time.Minute,
+		IdleCommand:        "xprintidle",
+		ShowHelp:           false,
 	}
 }
 
@@ -51,22 +154,53 @@ func ParseFlags(version string) *Config {
 
 	// Определение флагов
 	flag.StringVar(&config.DataFile, "data", config.DataFile, "Путь к файлу данных")
+	flag.StringVar(&config.Storage, "storage", config.Storage, "Тип хранилища данных (json, sqlite)")
+	flag.IntVar(&config.Backups, "backups", config.Backups, "Количество резервных копий данных (0 - отключить)")
+	flag.DurationVar(&config.BackupInterval, "backup-interval", config.BackupInterval, "Минимальный промежуток между резервными копиями при сохранении (0 - копия при каждом сохранении)")
+	flag.BoolVar(&config.ReadOnly, "readonly", false, "Открыть данные только для чтения")
+	flag.StringVar(&config.Socket, "socket", config.Socket, "Unix-сокет демона: при запущенном демоне меню, трей и команды отслеживания работают через него")
+	flag.StringVar(&config.HTTPAddr, "http", "", "Адрес HTTP API, например 127.0.0.1:8765 (пусто - отключен)")
+	flag.StringVar(&config.HTTPToken, "http-token", "", "Токен HTTP API (по умолчанию - из файла ~/.time-tracker/api-token)")
 	flag.StringVar(&config.LogDir, "log-dir", config.LogDir, "Директория для логов")
 	flag.StringVar(&config.LogLevel, "log-level", config.LogLevel, "Уровень логирования (debug, info, warn, error, fatal)")
-	flag.IntVar(&config.NotificationTime, "notify-time", config.NotificationTime, "Время для уведомления в секундах")
+	flag.IntVar(&config.NotificationTime, "notify-time", config.NotificationTime, "Интервал напоминаний о перерыве в секундах (0 - отключить)")
+	flag.StringVar(&config.Notifier, "notifier", config.Notifier, "Способ отправки уведомлений: auto, notify-send, dbus, console, webhook, none")
+	flag.StringVar(&config.WebhookURL, "webhook-url", "", "Адрес для уведомлений webhook (POST с JSON)")
+	flag.DurationVar(&config.Snooze, "snooze", config.Snooze, "Время, на которое откладывается напоминание кнопкой в уведомлении")
+	flag.StringVar(&config.WorkHours, "work-hours", "", "Рабочие часы ЧЧ:ММ-ЧЧ:ММ для напоминаний о незапущенном и незавершенном отслеживании")
+	flag.DurationVar(&config.RemindInterval, "remind-interval", config.RemindInterval, "Интервал повтора напоминаний в рабочие часы")
+	flag.DurationVar(&config.MaxSession, "max-session", config.MaxSession, "Длительность сессии, после которой при запуске предлагается её ограничить или отменить")
+	flag.BoolVar(&config.Pomodoro, "pomodoro", false, "Режим помидоров: перерывы по расписанию с автоматической паузой отслеживания")
+	flag.DurationVar(&config.PomodoroWork, "pomodoro-work", config.PomodoroWork, "Длительность помидора")
+	flag.DurationVar(&config.PomodoroShortBreak, "pomodoro-short", config.PomodoroShortBreak, "Длительность короткого перерыва")
+	flag.DurationVar(&config.PomodoroLongBreak, "pomodoro-long", config.PomodoroLongBreak, "Длительность длинного перерыва")
+	flag.IntVar(&config.PomodoroLongEvery, "pomodoro-long-every", config.PomodoroLongEvery, "Длинный перерыв после каждого N-го помидора")
+	flag.StringVar(&config.TimerMode, "timers", config.TimerMode, "Режим таймеров: single - один запущенный проект, multi - несколько одновременно")
+	flag.StringVar(&config.UI, "ui", config.UI, "Интерфейс интерактивного режима: menu - меню, tui - полноэкранный")
+	flag.DurationVar(&config.IdleThreshold, "idle", config.IdleThreshold, "Время бездействия, после которого при возвращении предлагается исключить его из сессии (0 - отключить)")
+	flag.StringVar(&config.IdleCommand, "idle-command", config.IdleCommand, "Команда, печатающая время бездействия в миллисекундах")
 	flag.BoolVar(&config.ShowHelp, "help", false, "Показать справку и выйти")
 	flag.BoolVar(&config.ShowHelp, "h", false, "Показать справку и выйти (сокращение)")
 
 	// Переопределяем стандартный обработчик справки
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Трекер времени v%s\n\n", version)
-		fmt.Fprintf(os.Stderr, "Использование: %s [флаги]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Использование: %s [флаги] [команда [аргументы]]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Без команды запускается интерактивное меню.\n\n")
+		fmt.Fprintf(os.Stderr, "Команды: daemon, web, start, stop, pause, resume, status, projects, sprints, add, entries, entry, report, export, import, backup, recover (подробнее: %s help)\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Флаги:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nПримеры:\n")
 		fmt.Fprintf(os.Stderr, "  %s -data /path/to/data.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -storage sqlite -data ~/time-tracker.db\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -log-level debug\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -ui tui\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -notify-time 1800\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s start my-project --sprint v1\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s stop my-project -m \"Исправлены ошибки\"\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s daemon\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -http 127.0.0.1:8765 daemon\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s web\n", os.Args[0])
 	}
 
 	// Парсинг флагов
@@ -78,6 +212,8 @@ func ParseFlags(version string) *Config {
 		os.Exit(0)
 	}
 
+	config.Args = flag.Args()
+
 	return config
 }
 
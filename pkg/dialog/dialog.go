@@ -0,0 +1,95 @@
+// Package dialog - небольшие графические диалоги для действий из системного трея,
+// где нельзя спросить пользователя в терминале
+package dialog
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrCanceled - пользователь закрыл диалог без ответа
+	ErrCanceled = errors.New("диалог отменен")
+
+	// ErrUnavailable - не найдена программа для показа диалогов
+	ErrUnavailable = errors.New("не найдены zenity и kdialog")
+)
+
+// Prompter - запрос строки у пользователя
+type Prompter interface {
+	// Entry - поле ввода с заголовком и подсказкой, ErrCanceled при отмене
+	Entry(title, text string) (string, error)
+}
+
+// CommandPrompter - диалоги через внешнюю программу (zenity или kdialog)
+type CommandPrompter struct {
+	Command string
+}
+
+// New - диалоги через первую найденную программу: zenity, затем kdialog
+func New() (*CommandPrompter, error) {
+	for _, command := range []string{"zenity", "kdialog"} {
+		if _, err := exec.LookPath(command); err == nil {
+			return &CommandPrompter{Command: command}, nil
+		}
+	}
+
+	return nil, ErrUnavailable
+}
+
+// Entry - показ поля ввода; программа завершается с кодом 1 при отмене
+func (p *CommandPrompter) Entry(title, text string) (string, error) {
+	var args []string
+	switch p.Command {
+	case "kdialog":
+		args = []string{"--title", title, "--inputbox", text}
+	default:
+		args = []string{"--entry", "--title", title, "--text", text}
+	}
+
+	out, err := exec.Command(p.Command, args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return "", ErrCanceled
+	}
+	if err != nil {
+		return "", fmt.Errorf("ошибка запуска %s: %w", p.Command, err)
+	}
+
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// FakePrompter - диалог с заранее заданным ответом для тестов
+type FakePrompter struct {
+	mu      sync.Mutex
+	answer  string
+	err     error
+	prompts []string
+}
+
+// Set - установка ответа и ошибки
+func (p *FakePrompter) Set(answer string, err error) {
+	p.mu.Lock()
+	p.answer, p.err = answer, err
+	p.mu.Unlock()
+}
+
+// Entry - заданный ответ, подсказка запоминается
+func (p *FakePrompter) Entry(title, text string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.prompts = append(p.prompts, text)
+	return p.answer, p.err
+}
+
+// Prompts - подсказки показанных диалогов
+func (p *FakePrompter) Prompts() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]string(nil), p.prompts...)
+}
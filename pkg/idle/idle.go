@@ -0,0 +1,178 @@
+package idle
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source - источник времени бездействия пользователя
+type Source interface {
+	// IdleTime - время с последнего действия пользователя (клавиатура, мышь)
+	IdleTime() (time.Duration, error)
+}
+
+// CommandSource - время бездействия из вывода внешней команды.
+// Команда печатает одно целое число в единицах Unit.
+type CommandSource struct {
+	Command string
+	Args    []string
+	Unit    time.Duration
+}
+
+// NewCommandSource - источник на основе команды; xprintidle печатает миллисекунды
+// (работает в X11 и в Wayland через XWayland)
+func NewCommandSource(command string) *CommandSource {
+	return &CommandSource{
+		Command: command,
+		Unit:    time.Millisecond,
+	}
+}
+
+// IdleTime - запуск команды и разбор её вывода
+func (s *CommandSource) IdleTime() (time.Duration, error) {
+	out, err := exec.Command(s.Command, s.Args...).Output()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка запуска %s: %w", s.Command, err)
+	}
+
+	value, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("неверный вывод %s: %q", s.Command, out)
+	}
+
+	return time.Duration(value) * s.Unit, nil
+}
+
+// FakeSource - источник с заданным вручную временем бездействия для тестов
+type FakeSource struct {
+	mu   sync.Mutex
+	idle time.Duration
+	err  error
+}
+
+// Set - установка времени бездействия и ошибки
+func (s *FakeSource) Set(idle time.Duration, err error) {
+	s.mu.Lock()
+	s.idle, s.err = idle, err
+	s.mu.Unlock()
+}
+
+// IdleTime - заданное время бездействия
+func (s *FakeSource) IdleTime() (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.idle, s.err
+}
+
+// Span - период бездействия
+type Span struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration - длительность бездействия
+func (s Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Monitor - периодический опрос источника. Когда бездействие превышает
+// Threshold, запоминается его начало, а при возвращении пользователя
+// период передается в OnReturn.
+type Monitor struct {
+	Source    Source
+	Threshold time.Duration
+	Interval  time.Duration
+	OnReturn  func(Span)
+
+	// Текущее время, подменяется в тестах
+	Now func() time.Time
+
+	mu        sync.Mutex
+	idleSince *time.Time
+	stop      chan struct{}
+}
+
+// NewMonitor - создание монитора бездействия с опросом раз в 5 секунд
+func NewMonitor(source Source, threshold time.Duration, onReturn func(Span)) *Monitor {
+	return &Monitor{
+		Source:    source,
+		Threshold: threshold,
+		Interval:  5 * time.Second,
+		OnReturn:  onReturn,
+		Now:       time.Now,
+	}
+}
+
+// Check - однократный опрос источника
+func (m *Monitor) Check() error {
+	idle, err := m.Source.IdleTime()
+	if err != nil {
+		return err
+	}
+
+	now := m.Now()
+
+	m.mu.Lock()
+	var span *Span
+	switch {
+	case idle >= m.Threshold:
+		if m.idleSince == nil {
+			since := now.Add(-idle)
+			m.idleSince = &since
+		}
+	case m.idleSince != nil:
+		// Бездействие закончилось в момент последнего действия пользователя
+		span = &Span{Start: *m.idleSince, End: now.Add(-idle)}
+		m.idleSince = nil
+	}
+	m.mu.Unlock()
+
+	if span != nil && m.OnReturn != nil {
+		m.OnReturn(*span)
+	}
+
+	return nil
+}
+
+// Start - запуск опроса в отдельной горутине
+func (m *Monitor) Start() {
+	m.mu.Lock()
+	if m.stop != nil {
+		m.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	m.stop = stop
+	m.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(m.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				// Ошибки источника не прерывают опрос: команда может
+				// временно не работать, например при блокировке экрана
+				m.Check()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop - остановка опроса
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+	m.mu.Unlock()
+}
@@ -0,0 +1,171 @@
+package idle
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestMonitor - монитор с порогом 5 минут, подменяемым временем и списком
+// переданных в OnReturn периодов
+func newTestMonitor(source Source, now *time.Time) (*Monitor, *[]Span) {
+	var spans []Span
+	monitor := NewMonitor(source, 5*time.Minute, func(span Span) {
+		spans = append(spans, span)
+	})
+	monitor.Now = func() time.Time { return *now }
+
+	return monitor, &spans
+}
+
+func TestMonitorCheck(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	type step struct {
+		at   time.Duration
+		idle time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+		want  []Span
+	}{
+		{
+			name:  "бездействие ниже порога",
+			steps: []step{{0, time.Minute}, {4 * time.Minute, 4 * time.Minute}, {5 * time.Minute, 0}},
+		},
+		{
+			name:  "превышение порога без возвращения",
+			steps: []step{{0, 6 * time.Minute}, {10 * time.Minute, 16 * time.Minute}},
+		},
+		{
+			name: "возвращение после бездействия",
+			steps: []step{
+				{0, 6 * time.Minute},
+				{10 * time.Minute, 16 * time.Minute},
+				{15 * time.Minute, 2 * time.Second},
+			},
+			want: []Span{{Start: base.Add(-6 * time.Minute), End: base.Add(15*time.Minute - 2*time.Second)}},
+		},
+		{
+			name: "два периода бездействия",
+			steps: []step{
+				{0, 10 * time.Minute},
+				{time.Minute, 0},
+				{2 * time.Minute, time.Minute},
+				{time.Hour, 20 * time.Minute},
+				{2 * time.Hour, 30 * time.Second},
+			},
+			want: []Span{
+				{Start: base.Add(-10 * time.Minute), End: base.Add(time.Minute)},
+				{Start: base.Add(40 * time.Minute), End: base.Add(2*time.Hour - 30*time.Second)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &FakeSource{}
+			now := base
+			monitor, spans := newTestMonitor(source, &now)
+
+			for _, s := range tt.steps {
+				now = base.Add(s.at)
+				source.Set(s.idle, nil)
+				if err := monitor.Check(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if len(*spans) != len(tt.want) {
+				t.Fatalf("периоды %v, ожидалось %v", *spans, tt.want)
+			}
+			for i, span := range *spans {
+				if !span.Start.Equal(tt.want[i].Start) || !span.End.Equal(tt.want[i].End) {
+					t.Errorf("период %d: %v, ожидался %v", i, span, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMonitorSourceError(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	source := &FakeSource{}
+	now := base
+	monitor, spans := newTestMonitor(source, &now)
+
+	source.Set(10*time.Minute, nil)
+	if err := monitor.Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ошибка источника не сбрасывает начатое бездействие
+	now = base.Add(time.Minute)
+	source.Set(0, errors.New("экран заблокирован"))
+	if err := monitor.Check(); err == nil {
+		t.Error("ошибка источника не возвращена")
+	}
+
+	now = base.Add(20 * time.Minute)
+	source.Set(0, nil)
+	if err := monitor.Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := Span{Start: base.Add(-10 * time.Minute), End: base.Add(20 * time.Minute)}
+	if len(*spans) != 1 || (*spans)[0] != want {
+		t.Errorf("периоды %v, ожидался %v", *spans, want)
+	}
+	if d := want.Duration(); d != 30*time.Minute {
+		t.Errorf("длительность %v, ожидалось 30m", d)
+	}
+}
+
+func TestMonitorStartStop(t *testing.T) {
+	source := &FakeSource{}
+	returned := make(chan Span, 1)
+
+	monitor := NewMonitor(source, time.Minute, func(span Span) {
+		select {
+		case returned <- span:
+		default:
+		}
+	})
+	monitor.Interval = time.Millisecond
+
+	source.Set(time.Hour, nil)
+	monitor.Start()
+	monitor.Start()
+	defer monitor.Stop()
+
+	// Опрос должен заметить бездействие до возвращения пользователя
+	deadline := time.After(time.Second)
+	for {
+		monitor.mu.Lock()
+		idle := monitor.idleSince != nil
+		monitor.mu.Unlock()
+		if idle {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("бездействие не обнаружено")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	source.Set(0, nil)
+	select {
+	case span := <-returned:
+		if span.Duration() < time.Hour {
+			t.Errorf("период %v короче бездействия", span)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("возвращение не обнаружено")
+	}
+
+	monitor.Stop()
+	monitor.Stop()
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+// CommandNotifier - уведомления через утилиту notify-send, без действий
+type CommandNotifier struct {
+	Command string
+}
+
+// NewCommandNotifier - отправитель через notify-send
+func NewCommandNotifier() *CommandNotifier {
+	return &CommandNotifier{Command: "notify-send"}
+}
+
+// Notify - запуск notify-send
+func (n *CommandNotifier) Notify(notification Notification) error {
+	if err := exec.Command(n.Command, notification.Title, notification.Message).Run(); err != nil {
+		return fmt.Errorf("ошибка запуска %s: %w", n.Command, err)
+	}
+
+	return nil
+}
+
+// ConsoleNotifier - вывод уведомлений в консоль со звуковым сигналом терминала
+type ConsoleNotifier struct {
+	Writer io.Writer
+	Bell   bool
+}
+
+// Notify - вывод уведомления
+func (n *ConsoleNotifier) Notify(notification Notification) error {
+	bell := ""
+	if n.Bell {
+		bell = "\a"
+	}
+
+	_, err := fmt.Fprintf(n.Writer, "%s[%s] %s\n", bell, notification.Title, notification.Message)
+	return err
+}
+
+// Noop - отключенные уведомления
+type Noop struct{}
+
+// Notify - уведомление не отправляется
+func (Noop) Notify(Notification) error {
+	return nil
+}
+
+// Recorder - сохранение уведомлений в памяти для тестов
+type Recorder struct {
+	mu            sync.Mutex
+	notifications []Notification
+}
+
+// Notify - сохранение уведомления
+func (r *Recorder) Notify(notification Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.notifications = append(r.notifications, notification)
+	return nil
+}
+
+// Notifications - отправленные уведомления
+func (r *Recorder) Notifications() []Notification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Notification(nil), r.notifications...)
+}
+
+// Invoke - выбор действия в последнем уведомлении, как если бы его нажал пользователь
+func (r *Recorder) Invoke(key string) {
+	r.mu.Lock()
+	if len(r.notifications) == 0 {
+		r.mu.Unlock()
+		return
+	}
+	last := r.notifications[len(r.notifications)-1]
+	r.mu.Unlock()
+
+	if last.OnAction != nil {
+		last.OnAction(key)
+	}
+}
@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	dbusDestination = "org.freedesktop.Notifications"
+	dbusPath        = dbus.ObjectPath("/org/freedesktop/Notifications")
+)
+
+// DBusNotifier - уведомления через freedesktop D-Bus API с поддержкой действий
+type DBusNotifier struct {
+	AppName string
+
+	mu       sync.Mutex
+	conn     *dbus.Conn
+	handlers map[uint32]func(string)
+}
+
+// NewDBusNotifier - подключение к сессионной шине и подписка на нажатия действий
+func NewDBusNotifier(appName string) (*DBusNotifier, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подключения к D-Bus: %w", err)
+	}
+
+	// Проверяем, что на шине есть сервер уведомлений
+	if call := conn.Object(dbusDestination, dbusPath).Call(dbusDestination+".GetServerInformation", 0); call.Err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("сервер уведомлений D-Bus недоступен: %w", call.Err)
+	}
+
+	err = conn.AddMatchSignal(
+		dbus.WithMatchInterface(dbusDestination),
+		dbus.WithMatchObjectPath(dbusPath),
+	)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ошибка подписки на сигналы уведомлений: %w", err)
+	}
+
+	n := &DBusNotifier{
+		AppName:  appName,
+		conn:     conn,
+		handlers: make(map[uint32]func(string)),
+	}
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	go n.listen(signals)
+
+	return n, nil
+}
+
+// Notify - отправка уведомления методом org.freedesktop.Notifications.Notify
+func (n *DBusNotifier) Notify(notification Notification) error {
+	// Действия передаются плоским списком пар ключ, подпись
+	var actions []string
+	for _, action := range notification.Actions {
+		actions = append(actions, action.Key, action.Label)
+	}
+
+	// Блокировка удерживается до регистрации обработчика,
+	// чтобы сигнал о нажатии не пришел раньше
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var id uint32
+	err := n.conn.Object(dbusDestination, dbusPath).Call(
+		dbusDestination+".Notify", 0,
+		n.AppName, uint32(0), "", notification.Title, notification.Message,
+		actions, map[string]dbus.Variant{}, int32(-1),
+	).Store(&id)
+	if err != nil {
+		return fmt.Errorf("ошибка отправки уведомления через D-Bus: %w", err)
+	}
+
+	if notification.OnAction != nil && len(actions) > 0 {
+		n.handlers[id] = notification.OnAction
+	}
+
+	return nil
+}
+
+// listen - обработка нажатий действий и закрытия уведомлений
+func (n *DBusNotifier) listen(signals <-chan *dbus.Signal) {
+	for signal := range signals {
+		if len(signal.Body) < 2 {
+			continue
+		}
+		id, ok := signal.Body[0].(uint32)
+		if !ok {
+			continue
+		}
+
+		n.mu.Lock()
+		handler := n.handlers[id]
+		delete(n.handlers, id)
+		n.mu.Unlock()
+
+		if signal.Name != dbusDestination+".ActionInvoked" || handler == nil {
+			continue
+		}
+		if key, ok := signal.Body[1].(string); ok {
+			handler(key)
+		}
+	}
+}
+
+// Close - закрытие соединения с шиной
+func (n *DBusNotifier) Close() error {
+	return n.conn.Close()
+}
@@ -2,15 +2,116 @@ package notify
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"time"
 )
 
-// Send - отправка уведомления
-func Send(title, message string) error {
-	return exec.Command("notify-send", title, message).Run()
+// Виды отправителей уведомлений
+const (
+	KindAuto       = "auto"
+	KindNotifySend = "notify-send"
+	KindDBus       = "dbus"
+	KindConsole    = "console"
+	KindWebhook    = "webhook"
+	KindNone       = "none"
+)
+
+// Ключи действий уведомлений
+const (
+	ActionStop   = "stop"
+	ActionSnooze = "snooze"
+)
+
+// Action - кнопка действия в уведомлении
+type Action struct {
+	Key   string
+	Label string
+}
+
+// Notification - уведомление
+type Notification struct {
+	Title   string
+	Message string
+
+	// Действия поддерживаются не всеми отправителями,
+	// OnAction вызывается с ключом выбранного действия
+	Actions  []Action
+	OnAction func(key string)
+}
+
+// Notifier - отправитель уведомлений
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// Options - параметры создания отправителя
+type Options struct {
+	// Вид отправителя (auto, notify-send, dbus, console, webhook, none)
+	Kind string
+
+	// Адрес для отправителя webhook
+	WebhookURL string
+
+	// Вывод для отправителя console, по умолчанию stderr
+	Console io.Writer
+}
+
+// New - создание отправителя уведомлений. В режиме auto используется D-Bus,
+// без него notify-send, а если нет и его - вывод в консоль.
+func New(opts Options) (Notifier, error) {
+	console := opts.Console
+	if console == nil {
+		console = os.Stderr
+	}
+
+	switch opts.Kind {
+	case KindAuto, "":
+		if n, err := NewDBusNotifier("Трекер времени"); err == nil {
+			return n, nil
+		}
+		if _, err := exec.LookPath("notify-send"); err == nil {
+			return NewCommandNotifier(), nil
+		}
+		return &ConsoleNotifier{Writer: console, Bell: true}, nil
+	case KindNotifySend:
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return nil, fmt.Errorf("notify-send не найден: %w", err)
+		}
+		return NewCommandNotifier(), nil
+	case KindDBus:
+		return NewDBusNotifier("Трекер времени")
+	case KindConsole:
+		return &ConsoleNotifier{Writer: console, Bell: true}, nil
+	case KindWebhook:
+		if opts.WebhookURL == "" {
+			return nil, fmt.Errorf("для уведомлений webhook нужен адрес (-webhook-url)")
+		}
+		return NewWebhookNotifier(opts.WebhookURL), nil
+	case KindNone:
+		return Noop{}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный вид уведомлений: %s (допустимо: auto, notify-send, dbus, console, webhook, none)", opts.Kind)
+	}
+}
+
+// BreakReminder - напоминание о перерыве после worked работы над проектом
+func BreakReminder(project string, worked time.Duration) Notification {
+	return Notification{
+		Title:   "Оповещение",
+		Message: fmt.Sprintf("Вы работаете над проектом '%s' уже %s! Время сделать перерыв.", project, formatWorked(worked)),
+	}
 }
 
-// SendBreakReminder - отправка напоминания о перерыве
-func SendBreakReminder(project string) error {
-	return Send("Оповещение", fmt.Sprintf("Вы работаете над проектом '%s' уже 25 минут! Время сделать перерыв.", project))
+// formatWorked - длительность работы в часах и минутах
+func formatWorked(d time.Duration) string {
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+
+	if hours > 0 {
+		return fmt.Sprintf("%d ч %d мин", hours, minutes)
+	}
+
+	return fmt.Sprintf("%d мин", minutes)
 }
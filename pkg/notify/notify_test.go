@@ -0,0 +1,194 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withoutDBus - окружение без сессионной шины D-Bus и с notify-send,
+// если он передан, в единственном каталоге PATH
+func withoutDBus(t *testing.T, notifySend bool) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path="+filepath.Join(dir, "bus"))
+	t.Setenv("PATH", dir)
+
+	out := filepath.Join(dir, "notify-send.out")
+	if notifySend {
+		script := "#!/bin/sh\nprintf '%s|%s' \"$1\" \"$2\" > " + out + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "notify-send"), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return out
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		notifySend bool
+		want       string
+		wantErr    bool
+	}{
+		{name: "auto без D-Bus и notify-send", opts: Options{Kind: KindAuto}, want: "console"},
+		{name: "auto без D-Bus", opts: Options{}, notifySend: true, want: "notify-send"},
+		{name: "dbus без шины", opts: Options{Kind: KindDBus}, wantErr: true},
+		{name: "notify-send", opts: Options{Kind: KindNotifySend}, notifySend: true, want: "notify-send"},
+		{name: "notify-send не установлен", opts: Options{Kind: KindNotifySend}, wantErr: true},
+		{name: "console", opts: Options{Kind: KindConsole}, want: "console"},
+		{name: "webhook", opts: Options{Kind: KindWebhook, WebhookURL: "http://localhost/hook"}, want: "webhook"},
+		{name: "webhook без адреса", opts: Options{Kind: KindWebhook}, wantErr: true},
+		{name: "none", opts: Options{Kind: KindNone}, want: "none"},
+		{name: "неизвестный вид", opts: Options{Kind: "sms"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withoutDBus(t, tt.notifySend)
+
+			n, err := New(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			var kind string
+			switch n.(type) {
+			case *ConsoleNotifier:
+				kind = "console"
+			case *CommandNotifier:
+				kind = "notify-send"
+			case *WebhookNotifier:
+				kind = "webhook"
+			case Noop:
+				kind = "none"
+			}
+			if kind != tt.want {
+				t.Errorf("отправитель %T, ожидался %s", n, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandNotifier(t *testing.T) {
+	out := withoutDBus(t, true)
+
+	if err := NewCommandNotifier().Notify(Notification{Title: "Оповещение", Message: "Пора отдохнуть"}); err != nil {
+		t.Fatal(err)
+	}
+
+	args, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(args) != "Оповещение|Пора отдохнуть" {
+		t.Errorf("аргументы notify-send: %q", args)
+	}
+
+	if err := (&CommandNotifier{Command: "missing"}).Notify(Notification{}); err == nil {
+		t.Error("запуск несуществующей команды без ошибки")
+	}
+}
+
+func TestConsoleNotifier(t *testing.T) {
+	var buf bytes.Buffer
+	n := &ConsoleNotifier{Writer: &buf, Bell: true}
+
+	if err := n.Notify(Notification{Title: "Оповещение", Message: "Пора отдохнуть"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "\a[Оповещение] Пора отдохнуть\n" {
+		t.Errorf("вывод %q", got)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received map[string]string
+	status := http.StatusNoContent
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("запрос %s с типом %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("тело запроса: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL)
+	if err := n.Notify(Notification{Title: "Оповещение", Message: "Пора отдохнуть"}); err != nil {
+		t.Fatal(err)
+	}
+	if received["title"] != "Оповещение" || received["message"] != "Пора отдохнуть" {
+		t.Errorf("получено %v", received)
+	}
+
+	status = http.StatusInternalServerError
+	err := n.Notify(Notification{Title: "Оповещение"})
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("ошибка сервера: %v", err)
+	}
+
+	server.Close()
+	if err := n.Notify(Notification{Title: "Оповещение"}); err == nil {
+		t.Error("отправка на недоступный адрес без ошибки")
+	}
+}
+
+func TestRecorderActions(t *testing.T) {
+	var r Recorder
+
+	// Без уведомлений выбор действия ничего не делает
+	r.Invoke(ActionStop)
+
+	var chosen []string
+	r.Notify(Notification{Title: "Без действий"})
+	r.Invoke(ActionStop)
+	r.Notify(Notification{
+		Title:    "Оповещение",
+		Actions:  []Action{{Key: ActionStop, Label: "Остановить таймер"}, {Key: ActionSnooze, Label: "Отложить"}},
+		OnAction: func(key string) { chosen = append(chosen, key) },
+	})
+	r.Invoke(ActionSnooze)
+	r.Invoke(ActionStop)
+
+	if len(chosen) != 2 || chosen[0] != ActionSnooze || chosen[1] != ActionStop {
+		t.Errorf("выбранные действия %v", chosen)
+	}
+	if notifications := r.Notifications(); len(notifications) != 2 || notifications[1].Title != "Оповещение" {
+		t.Errorf("уведомления %+v", notifications)
+	}
+}
+
+func TestBreakReminder(t *testing.T) {
+	tests := []struct {
+		worked time.Duration
+		want   string
+	}{
+		{50 * time.Minute, "50 мин"},
+		{2*time.Hour + 5*time.Minute, "2 ч 5 мин"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			n := BreakReminder("alpha", tt.worked)
+			if !strings.Contains(n.Message, "'alpha' уже "+tt.want+"!") {
+				t.Errorf("сообщение %q", n.Message)
+			}
+		})
+	}
+}
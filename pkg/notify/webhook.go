@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier - отправка уведомлений POST-запросом с JSON
+// {"title": "...", "message": "..."}, без действий
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookNotifier - отправитель на адрес url
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify - отправка уведомления
+func (n *WebhookNotifier) Notify(notification Notification) error {
+	body, err := json.Marshal(map[string]string{
+		"title":   notification.Title,
+		"message": notification.Message,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.Client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("ошибка отправки уведомления на %s: %w", n.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("ошибка отправки уведомления на %s: %s", n.URL, resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package pomodoro
+
+import (
+	"sync"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/pkg/clock"
+)
+
+// Phase - этап помидора
+type Phase string
+
+const (
+	// PhaseWork - работа
+	PhaseWork Phase = "work"
+	// PhaseShortBreak - короткий перерыв
+	PhaseShortBreak Phase = "short_break"
+	// PhaseLongBreak - длинный перерыв
+	PhaseLongBreak Phase = "long_break"
+)
+
+// IsBreak - этап является перерывом
+func (p Phase) IsBreak() bool {
+	return p == PhaseShortBreak || p == PhaseLongBreak
+}
+
+// Settings - длительность этапов
+type Settings struct {
+	Work       time.Duration
+	ShortBreak time.Duration
+	LongBreak  time.Duration
+
+	// Длинный перерыв после каждого N-го помидора за день
+	LongBreakEvery int
+}
+
+// DefaultSettings - классические 25/5/15 минут, длинный перерыв после 4 помидоров
+func DefaultSettings() Settings {
+	return Settings{
+		Work:           25 * time.Minute,
+		ShortBreak:     5 * time.Minute,
+		LongBreak:      15 * time.Minute,
+		LongBreakEvery: 4,
+	}
+}
+
+// State - текущий этап помидора проекта
+type State struct {
+	Project string
+	Phase   Phase
+	Start   time.Time
+	End     time.Time
+
+	// Завершенных помидоров за текущий день
+	Cycles int
+}
+
+// Remaining - время до конца этапа
+func (s State) Remaining(now time.Time) time.Duration {
+	if now.After(s.End) {
+		return 0
+	}
+
+	return s.End.Sub(now)
+}
+
+// Engine - смена этапов помидора для запущенных проектов.
+// OnPhase вызывается из горутины таймера при начале каждого следующего этапа.
+// Счетчик помидоров за день хранится только в памяти и сбрасывается
+// при перезапуске программы.
+type Engine struct {
+	Settings Settings
+	Clock    clock.Clock
+	OnPhase  func(State)
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	day      string
+	cycles   int
+}
+
+// session - этап помидора проекта и таймер его окончания
+type session struct {
+	state State
+	timer clock.Timer
+}
+
+// NewEngine - создание движка помидоров
+func NewEngine(settings Settings, clk clock.Clock, onPhase func(State)) *Engine {
+	return &Engine{
+		Settings: settings,
+		Clock:    clk,
+		OnPhase:  onPhase,
+		sessions: make(map[string]*session),
+	}
+}
+
+// Start - начало помидора для проекта, текущий этап проекта сбрасывается
+func (e *Engine) Start(project string) State {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.stopLocked(project)
+
+	return e.beginLocked(project, PhaseWork)
+}
+
+// Stop - отмена помидора проекта
+func (e *Engine) Stop(project string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.stopLocked(project)
+}
+
+// State - текущий этап помидора проекта
+func (e *Engine) State(project string) (State, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, ok := e.sessions[project]
+	if !ok {
+		return State{}, false
+	}
+
+	return s.state, true
+}
+
+// CyclesToday - количество завершенных помидоров за сегодня с момента запуска движка
+func (e *Engine) CyclesToday() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rolloverLocked()
+
+	return e.cycles
+}
+
+// stopLocked - отмена таймера проекта, вызывается под e.mu
+func (e *Engine) stopLocked(project string) {
+	if s, ok := e.sessions[project]; ok {
+		s.timer.Stop()
+		delete(e.sessions, project)
+	}
+}
+
+// rolloverLocked - сброс счетчика помидоров в начале нового дня, вызывается под e.mu
+func (e *Engine) rolloverLocked() {
+	day := e.Clock.Now().Format("2006-01-02")
+	if day != e.day {
+		e.day = day
+		e.cycles = 0
+	}
+}
+
+// beginLocked - начало этапа с таймером его окончания, вызывается под e.mu
+func (e *Engine) beginLocked(project string, phase Phase) State {
+	e.rolloverLocked()
+
+	now := e.Clock.Now()
+	s := &session{
+		state: State{
+			Project: project,
+			Phase:   phase,
+			Start:   now,
+			End:     now.Add(e.duration(phase)),
+			Cycles:  e.cycles,
+		},
+	}
+	s.timer = e.Clock.AfterFunc(e.duration(phase), func() {
+		e.advance(project, s)
+	})
+	e.sessions[project] = s
+
+	return s.state
+}
+
+// advance - переход к следующему этапу по окончании текущего
+func (e *Engine) advance(project string, s *session) {
+	e.mu.Lock()
+	// Этап мог быть отменен или перезапущен, пока срабатывал таймер
+	if e.sessions[project] != s {
+		e.mu.Unlock()
+		return
+	}
+
+	next := PhaseWork
+	if s.state.Phase == PhaseWork {
+		e.rolloverLocked()
+		e.cycles++
+
+		next = PhaseShortBreak
+		if e.Settings.LongBreakEvery > 0 && e.cycles%e.Settings.LongBreakEvery == 0 {
+			next = PhaseLongBreak
+		}
+	}
+
+	state := e.beginLocked(project, next)
+	e.mu.Unlock()
+
+	if e.OnPhase != nil {
+		e.OnPhase(state)
+	}
+}
+
+// duration - длительность этапа
+func (e *Engine) duration(phase Phase) time.Duration {
+	switch phase {
+	case PhaseShortBreak:
+		return e.Settings.ShortBreak
+	case PhaseLongBreak:
+		return e.Settings.LongBreak
+	default:
+		return e.Settings.Work
+	}
+}
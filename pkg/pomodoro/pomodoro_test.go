@@ -0,0 +1,125 @@
+package pomodoro
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MWT-proger/time-tracking/pkg/clock"
+)
+
+// testSettings - короткие этапы, длинный перерыв после каждого второго помидора
+var testSettings = Settings{
+	Work:           25 * time.Minute,
+	ShortBreak:     5 * time.Minute,
+	LongBreak:      15 * time.Minute,
+	LongBreakEvery: 2,
+}
+
+// newTestEngine - движок на поддельных часах и список полученных этапов
+func newTestEngine(now time.Time) (*Engine, *clock.Fake, *[]State) {
+	clk := clock.NewFake(now)
+	var phases []State
+	engine := NewEngine(testSettings, clk, func(state State) {
+		phases = append(phases, state)
+	})
+
+	return engine, clk, &phases
+}
+
+func TestEnginePhases(t *testing.T) {
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	engine, clk, phases := newTestEngine(start)
+
+	state := engine.Start("alpha")
+	if state.Phase != PhaseWork || !state.End.Equal(start.Add(25*time.Minute)) || state.Cycles != 0 {
+		t.Fatalf("первый этап: %+v", state)
+	}
+
+	// Помидор, короткий перерыв, помидор, длинный перерыв, помидор
+	clk.Advance(25*time.Minute + 5*time.Minute + 25*time.Minute + 15*time.Minute)
+
+	want := []struct {
+		phase  Phase
+		cycles int
+		length time.Duration
+	}{
+		{PhaseShortBreak, 1, 5 * time.Minute},
+		{PhaseWork, 1, 25 * time.Minute},
+		{PhaseLongBreak, 2, 15 * time.Minute},
+		{PhaseWork, 2, 25 * time.Minute},
+	}
+	if len(*phases) != len(want) {
+		t.Fatalf("этапы: %+v", *phases)
+	}
+	for i, w := range want {
+		got := (*phases)[i]
+		if got.Project != "alpha" || got.Phase != w.phase || got.Cycles != w.cycles || got.End.Sub(got.Start) != w.length {
+			t.Errorf("этап %d: %+v, ожидался %s (помидоров %d, %v)", i+1, got, w.phase, w.cycles, w.length)
+		}
+	}
+
+	if current, ok := engine.State("alpha"); !ok || current != (*phases)[3] {
+		t.Errorf("текущий этап %+v, ожидался последний полученный", current)
+	}
+	if cycles := engine.CyclesToday(); cycles != 2 {
+		t.Errorf("помидоров за сегодня %d, ожидалось 2", cycles)
+	}
+}
+
+func TestEngineStop(t *testing.T) {
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	engine, clk, phases := newTestEngine(start)
+
+	engine.Start("alpha")
+	engine.Start("beta")
+	clk.Advance(10 * time.Minute)
+
+	engine.Stop("alpha")
+	if _, ok := engine.State("alpha"); ok {
+		t.Error("этап остановленного проекта не сброшен")
+	}
+
+	// Перезапуск начинает помидор заново
+	restarted := engine.Start("beta")
+	if !restarted.End.Equal(start.Add(35 * time.Minute)) {
+		t.Errorf("перезапущенный этап: %+v", restarted)
+	}
+
+	clk.Advance(20 * time.Minute)
+	if len(*phases) != 0 {
+		t.Errorf("этапы остановленного и перезапущенного помидоров: %+v", *phases)
+	}
+
+	clk.Advance(5 * time.Minute)
+	if len(*phases) != 1 || (*phases)[0].Project != "beta" || (*phases)[0].Phase != PhaseShortBreak {
+		t.Errorf("этапы: %+v", *phases)
+	}
+	if clk.Pending() != 1 {
+		t.Errorf("ожидающих таймеров %d, ожидался один", clk.Pending())
+	}
+}
+
+func TestEngineNewDay(t *testing.T) {
+	start := time.Date(2024, 3, 4, 23, 0, 0, 0, time.Local)
+	engine, clk, phases := newTestEngine(start)
+
+	engine.Start("alpha")
+	clk.Advance(25 * time.Minute)
+	if cycles := engine.CyclesToday(); cycles != 1 {
+		t.Fatalf("помидоров за день %d, ожидался 1", cycles)
+	}
+
+	// После полуночи счет помидоров начинается заново
+	clk.Advance(5*time.Minute + 40*time.Minute)
+	engine.Stop("alpha")
+	if cycles := engine.CyclesToday(); cycles != 0 {
+		t.Errorf("помидоров в новый день %d, ожидалось 0", cycles)
+	}
+
+	engine.Start("alpha")
+	clk.Advance(25 * time.Minute)
+	last := (*phases)[len(*phases)-1]
+	if last.Phase != PhaseShortBreak || last.Cycles != 1 {
+		t.Errorf("первый перерыв нового дня: %+v", last)
+	}
+}